@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"log"
+	"os"
+	"time"
+)
+
+const usage = `Usage: boltctl <command> <database> <destination>
+
+Maintenance commands for the bbolt entry store. The HTTP server must be stopped first,
+as it holds an exclusive lock on the database file.
+
+Commands:
+  backup   write a consistent copy of the database to destination
+  compact  rewrite the database into destination without its unused pages
+`
+
+func main() {
+	if len(os.Args) != 4 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command, src, dst := os.Args[1], os.Args[2], os.Args[3]
+
+	switch command {
+	case "backup":
+		db, err := boltDB.OpenReadOnly(src, time.Second)
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		if err := boltDB.BackupFile(db, dst); err != nil {
+			log.Fatalf("Failed to back up database: %v", err)
+		}
+		log.Printf("Backed up %s to %s", src, dst)
+	case "compact":
+		if err := boltDB.Compact(src, dst); err != nil {
+			log.Fatalf("Failed to compact database: %v", err)
+		}
+		log.Printf("Compacted %s into %s (%s)", src, dst, sizes(src, dst))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func sizes(src, dst string) string {
+	before, err := os.Stat(src)
+	if err != nil {
+		return "unknown size"
+	}
+	after, err := os.Stat(dst)
+	if err != nil {
+		return "unknown size"
+	}
+	return fmt.Sprintf("%d -> %d bytes", before.Size(), after.Size())
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// config holds the settings of the HTTP server. Every setting can be given as a command-line
+// flag, falling back to an environment variable and then to a default.
+type config struct {
+	Addr       string
+	Store      string
+	SQLitePath string
+	BoltPath   string
+	EventsPath string
+	WALPath    string
+	WALSync    string
+
+	TrashRetention time.Duration
+	UndoDepth      int
+	LogEvents      bool
+
+	JWKSPath    string
+	JWTIssuer   string
+	JWTAudience string
+}
+
+func loadConfig() config {
+	cfg := config{}
+	flag.StringVar(&cfg.Addr, "addr", env("TODO_ADDR", ":8080"), "address to listen on")
+	flag.StringVar(&cfg.Store, "store", env("TODO_STORE", "memory"), "entry store to use: memory, sqlite, bolt or events")
+	flag.StringVar(&cfg.SQLitePath, "sqlite-path", env("TODO_SQLITE_PATH", "todo.db"), "path of the SQLite database file")
+	flag.StringVar(&cfg.BoltPath, "bolt-path", env("TODO_BOLT_PATH", "todo.bolt"), "path of the bbolt database file, which also keeps everything but entries for the events store and the memory store with a write-ahead log")
+	flag.StringVar(&cfg.EventsPath, "events-path", env("TODO_EVENTS_PATH", "todo.events"), "path of the event log file of the events store")
+	flag.StringVar(&cfg.WALPath, "wal-path", env("TODO_WAL_PATH", ""), "path of the write-ahead log of entries kept in memory, with everything else kept at -bolt-path; everything is lost on exit when empty")
+	flag.StringVar(&cfg.WALSync, "wal-sync", env("TODO_WAL_SYNC", "always"), "when the write-ahead log is synced to disk: always, periodic or never")
+	flag.DurationVar(&cfg.TrashRetention, "trash-retention", envDuration("TODO_TRASH_RETENTION", 30*24*time.Hour), "how long deleted entries are kept in the trash before being purged; 0 keeps them until purged by hand")
+	flag.IntVar(&cfg.UndoDepth, "undo-depth", envInt("TODO_UNDO_DEPTH", 20), "how many recent operations each user can undo; 0 disables undo")
+	flag.BoolVar(&cfg.LogEvents, "log-events", envBool("TODO_LOG_EVENTS", false), "log every change made to entries")
+	flag.StringVar(&cfg.JWKSPath, "jwks-path", env("TODO_JWKS_PATH", ""), "path of the JWKS file verifying bearer JWTs; JWTs are rejected when empty")
+	flag.StringVar(&cfg.JWTIssuer, "jwt-issuer", env("TODO_JWT_ISSUER", ""), "issuer required of bearer JWTs")
+	flag.StringVar(&cfg.JWTAudience, "jwt-audience", env("TODO_JWT_AUDIENCE", ""), "audience required of bearer JWTs")
+	flag.Parse()
+
+	return cfg
+}
+
+func env(key, fallback string) string {
+	if val, ok := os.LookupEnv(key); ok {
+		return val
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(val)
+	if err != nil {
+		log.Fatalf("Invalid duration in %s: %v", key, err)
+	}
+	return parsed
+}
+
+func envInt(key string, fallback int) int {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(val)
+	if err != nil {
+		log.Fatalf("Invalid number in %s: %v", key, err)
+	}
+	return parsed
+}
+
+func envBool(key string, fallback bool) bool {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Fatalf("Invalid boolean in %s: %v", key, err)
+	}
+	return parsed
+}
@@ -1,32 +1,373 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/core/services/accessSrv"
 	"github.com/Nikym/go-todo/internal/core/services/entrySrv"
+	"github.com/Nikym/go-todo/internal/core/services/listSrv"
+	"github.com/Nikym/go-todo/internal/core/services/userSrv"
+	"github.com/Nikym/go-todo/internal/core/services/viewSrv"
+	"github.com/Nikym/go-todo/internal/eventBus"
+	"github.com/Nikym/go-todo/internal/handlers/accessHandler"
 	"github.com/Nikym/go-todo/internal/handlers/entryHandler"
+	"github.com/Nikym/go-todo/internal/handlers/jwtAuth"
+	"github.com/Nikym/go-todo/internal/handlers/listHandler"
+	"github.com/Nikym/go-todo/internal/handlers/userHandler"
+	"github.com/Nikym/go-todo/internal/handlers/viewHandler"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
 	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/grantRepo"
+	"github.com/Nikym/go-todo/internal/repositories/listRepo"
+	"github.com/Nikym/go-todo/internal/repositories/revisionRepo"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/Nikym/go-todo/internal/repositories/trashRepo"
+	"github.com/Nikym/go-todo/internal/repositories/userRepo"
+	"github.com/Nikym/go-todo/internal/repositories/viewRepo"
 	"github.com/gorilla/mux"
+	"go.etcd.io/bbolt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
-func SetupRoutes(router *mux.Router, httpHandler *entryHandler.HTTPEntryHandler) {
+// SetupRoutes registers every route of the API. Registering a user is the only route reachable
+// without authentication. Requests to the other routes are authenticated by the given
+// authenticators, in order, and then by the credentials of a user.
+func SetupRoutes(router *mux.Router, httpHandler *entryHandler.HTTPEntryHandler, listHandler *listHandler.HTTPListHandler, userHandler *userHandler.HTTPUserHandler, accessHandler *accessHandler.HTTPAccessHandler, viewHandler *viewHandler.HTTPViewHandler, authenticators ...mux.MiddlewareFunc) {
 	log.Println("Setting up routes...")
+	router.HandleFunc("/api/users", userHandler.Register).Methods("POST")
+
+	router = router.NewRoute().Subrouter()
+	router.Use(append(authenticators, userHandler.Authenticate)...)
+	router.HandleFunc("/api/users/me", userHandler.Me).Methods("GET")
+	router.HandleFunc("/api/tokens/{id}", userHandler.RevokeToken).Methods("DELETE")
+	router.HandleFunc("/api/tokens", userHandler.Tokens).Methods("GET")
+	router.HandleFunc("/api/tokens", userHandler.CreateToken).Methods("POST")
+	router.HandleFunc("/api/entry/search", httpHandler.Search).Methods("GET")
+	router.HandleFunc("/api/entry/parse", httpHandler.Parse).Methods("POST")
 	router.HandleFunc("/api/entry/{id}", httpHandler.Get).Methods("GET")
 	router.HandleFunc("/api/entry/{id}", httpHandler.Delete).Methods("DELETE")
 	router.HandleFunc("/api/entry/{id}", httpHandler.Update).Methods("PATCH")
+	router.HandleFunc("/api/entry/{id}/tags", httpHandler.AddTags).Methods("POST")
+	router.HandleFunc("/api/entry/{id}/tags/{tag}", httpHandler.RemoveTag).Methods("DELETE")
+	router.HandleFunc("/api/entry/{id}/children", httpHandler.Children).Methods("GET")
+	router.HandleFunc("/api/entry/{id}/tree", httpHandler.Tree).Methods("GET")
+	router.HandleFunc("/api/entry/{id}/history", httpHandler.History).Methods("GET")
+	router.HandleFunc("/api/entry/{id}/history/{number}/revert", httpHandler.Revert).Methods("POST")
+	router.HandleFunc("/api/entry/{id}/collaborators", accessHandler.Collaborators(domain.ResourceEntry)).Methods("GET")
+	router.HandleFunc("/api/entry/{id}/collaborators", accessHandler.Share(domain.ResourceEntry)).Methods("POST")
+	router.HandleFunc("/api/entry/{id}/collaborators/{user_id}", accessHandler.Revoke(domain.ResourceEntry)).Methods("DELETE")
+	router.HandleFunc("/api/entry", httpHandler.List).Methods("GET")
 	router.HandleFunc("/api/entry", httpHandler.Create).Methods("POST")
+	router.HandleFunc("/api/tags", httpHandler.Tags).Methods("GET")
+	router.HandleFunc("/api/trash/{id}", httpHandler.Purge).Methods("DELETE")
+	router.HandleFunc("/api/trash/{id}/restore", httpHandler.Restore).Methods("POST")
+	router.HandleFunc("/api/trash", httpHandler.Trash).Methods("GET")
+	router.HandleFunc("/api/trash", httpHandler.EmptyTrash).Methods("DELETE")
+	router.HandleFunc("/api/undo", httpHandler.Undo).Methods("POST")
+	router.HandleFunc("/api/redo", httpHandler.Redo).Methods("POST")
+	router.HandleFunc("/api/list/{id}", listHandler.Get).Methods("GET")
+	router.HandleFunc("/api/list/{id}", listHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/list/{id}", listHandler.Update).Methods("PATCH")
+	router.HandleFunc("/api/list/{id}/entries", listHandler.Entries).Methods("GET")
+	router.HandleFunc("/api/list/{id}/entries", listHandler.Move).Methods("POST")
+	router.HandleFunc("/api/list/{id}/collaborators", accessHandler.Collaborators(domain.ResourceList)).Methods("GET")
+	router.HandleFunc("/api/list/{id}/collaborators", accessHandler.Share(domain.ResourceList)).Methods("POST")
+	router.HandleFunc("/api/list/{id}/collaborators/{user_id}", accessHandler.Revoke(domain.ResourceList)).Methods("DELETE")
+	router.HandleFunc("/api/list", listHandler.List).Methods("GET")
+	router.HandleFunc("/api/list", listHandler.Create).Methods("POST")
+	router.HandleFunc("/api/views/{name}", viewHandler.Get).Methods("GET")
+	router.HandleFunc("/api/views/{name}", viewHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/views/{name}", viewHandler.Update).Methods("PATCH")
+	router.HandleFunc("/api/views/{name}/entries", viewHandler.Entries).Methods("GET")
+	router.HandleFunc("/api/views", viewHandler.List).Methods("GET")
+	router.HandleFunc("/api/views", viewHandler.Create).Methods("POST")
+}
+
+// repositories holds the repositories of the configured store.
+type repositories struct {
+	entries   ports.EntryRepository
+	lists     ports.ListRepository
+	users     ports.UserRepository
+	grants    ports.GrantRepository
+	views     ports.ViewRepository
+	trash     ports.TrashRepository
+	revisions ports.RevisionRepository
+}
+
+// nopCloser is the closer of stores holding no resource that has to be closed.
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// NewRepositories returns the repositories of the store selected by the configuration, along
+// with the resource that has to be closed once the repositories are no longer used.
+func NewRepositories(cfg config) (*repositories, io.Closer, error) {
+	switch cfg.Store {
+	case "memory":
+		if cfg.WALPath != "" {
+			return NewDurableMemoryRepositories(cfg)
+		}
+		return &repositories{
+			entries:   entryRepo.NewMemKVS(),
+			lists:     listRepo.NewMemKVS(),
+			users:     userRepo.NewMemKVS(),
+			grants:    grantRepo.NewMemKVS(),
+			views:     viewRepo.NewMemKVS(),
+			trash:     trashRepo.NewMemKVS(),
+			revisions: revisionRepo.NewMemKVS(),
+		}, nopCloser{}, nil
+	case "sqlite":
+		db, err := sqliteDB.Open(cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &repositories{
+			entries:   entryRepo.NewSQLite(db),
+			lists:     listRepo.NewSQLite(db),
+			users:     userRepo.NewSQLite(db),
+			grants:    grantRepo.NewSQLite(db),
+			views:     viewRepo.NewSQLite(db),
+			trash:     trashRepo.NewSQLite(db),
+			revisions: revisionRepo.NewSQLite(db),
+		}, db, nil
+	case "bolt":
+		db, err := boltDB.Open(cfg.BoltPath, time.Second)
+		if err != nil {
+			return nil, nil, err
+		}
+		repos := newBoltRepositories(db)
+		repos.entries = entryRepo.NewBolt(db)
+		return repos, db, nil
+	case "events":
+		entries, err := entryRepo.OpenEventLog(cfg.EventsPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		db, err := boltDB.Open(cfg.BoltPath, time.Second)
+		if err != nil {
+			entries.Close()
+			return nil, nil, err
+		}
+		repos := newBoltRepositories(db)
+		repos.entries = entries
+		return repos, closers{entries, db}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown store %q", cfg.Store)
+	}
+}
+
+// newBoltRepositories returns the repositories of everything but entries kept in the given bbolt
+// database.
+func newBoltRepositories(db *bbolt.DB) *repositories {
+	return &repositories{
+		lists:     listRepo.NewBolt(db),
+		users:     userRepo.NewBolt(db),
+		grants:    grantRepo.NewBolt(db),
+		views:     viewRepo.NewBolt(db),
+		trash:     trashRepo.NewBolt(db),
+		revisions: revisionRepo.NewBolt(db),
+	}
+}
+
+// closers closes each of its resources in turn, and returns the first error met.
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var first error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// NewDurableMemoryRepositories returns the repositories of the memory store, keeping entries in
+// memory and recording the writes made to them in a write-ahead log, and everything else in the
+// bbolt database. Both have to be closed once the repositories are no longer used.
+func NewDurableMemoryRepositories(cfg config) (*repositories, io.Closer, error) {
+	policy, err := entryRepo.ParseSyncPolicy(cfg.WALSync)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := entryRepo.OpenMemKVS(cfg.WALPath, entryRepo.WithSyncPolicy(policy))
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := boltDB.Open(cfg.BoltPath, time.Second)
+	if err != nil {
+		entries.Close()
+		return nil, nil, err
+	}
+	repos := newBoltRepositories(db)
+	repos.entries = entries
+	return repos, closers{entries, db}, nil
+}
+
+// LogEvent logs the change to an entry announced by event. Logging drops events rather than hold
+// up requests when it falls behind.
+func LogEvent(_ context.Context, event *domain.Event) {
+	if len(event.Changes) == 0 {
+		log.Printf("Entry %s %s by %s", event.EntryID, event.Type, event.ActorID)
+		return
+	}
+	fields := make([]string, 0, len(event.Changes))
+	for _, change := range event.Changes {
+		fields = append(fields, change.Field)
+	}
+	log.Printf("Entry %s %s by %s: %s", event.EntryID, event.Type, event.ActorID, strings.Join(fields, ", "))
 }
 
+// trashPurgeInterval is how often the entries kept in the trash for longer than the retention
+// are purged.
+const trashPurgeInterval = time.Hour
+
+// PurgeTrash permanently removes the entries kept in the trash for longer than retention, and
+// then again every interval until ctx is done.
+func PurgeTrash(ctx context.Context, entryService ports.EntryService, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := entryService.PurgeTrash(ctx, retention)
+		if err != nil {
+			log.Printf("Failed to purge trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d entries from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// jwksReloadInterval is how often the JWKS file is checked for rotated keys.
+const jwksReloadInterval = 30 * time.Second
+
+// NewVerifier returns a verifier of the JWTs signed by the keys of the configured JWKS file,
+// which is watched for changes until ctx is done.
+func NewVerifier(ctx context.Context, cfg config) (*jwtAuth.Verifier, error) {
+	keys, err := jwtAuth.NewKeySet(cfg.JWKSPath)
+	if err != nil {
+		return nil, err
+	}
+	go keys.Watch(ctx, jwksReloadInterval, func(err error) {
+		log.Printf("Failed to reload JWKS, keeping previous keys: %v", err)
+	})
+
+	var opts []jwtAuth.Option
+	if cfg.JWTIssuer != "" {
+		opts = append(opts, jwtAuth.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		opts = append(opts, jwtAuth.WithAudience(cfg.JWTAudience))
+	}
+	return jwtAuth.NewVerifier(keys, opts...), nil
+}
+
+// shutdownTimeout is how long the server waits for the requests in flight to complete when
+// shutting down.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	log.Println("Started HTTP server")
+	cfg := loadConfig()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	repos, closer, err := NewRepositories(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up %s store: %v", cfg.Store, err)
+	}
+	log.Printf("Using %s store", cfg.Store)
 
-	entryRepository := entryRepo.NewMemKVS()
-	entryService := entrySrv.New(entryRepository)
+	bus := eventBus.New()
+	if cfg.LogEvents {
+		bus.SubscribeAsync(LogEvent, domain.Subscription{Overflow: domain.OverflowDrop})
+		log.Println("Logging entry events")
+	}
+
+	accessService := accessSrv.New(repos.grants, repos.entries, repos.lists, repos.users)
+	entryService := entrySrv.New(repos.entries, entrySrv.WithLists(repos.lists), entrySrv.WithAccess(accessService), entrySrv.WithTrash(repos.trash), entrySrv.WithHistory(repos.revisions), entrySrv.WithUndo(cfg.UndoDepth), entrySrv.WithEvents(bus))
+	listService := listSrv.New(repos.lists, entryService, listSrv.WithAccess(accessService))
+	userService := userSrv.New(repos.users)
+	viewService := viewSrv.New(repos.views, entryService)
 	httpHandler := entryHandler.NewHTTPEntryHandler(entryService)
+	httpListHandler := listHandler.NewHTTPListHandler(listService)
+	httpUserHandler := userHandler.NewHTTPUserHandler(userService)
+	httpAccessHandler := accessHandler.NewHTTPAccessHandler(accessService)
+	httpViewHandler := viewHandler.NewHTTPViewHandler(viewService)
+
+	// shutdown closes the event bus, letting its subscribers handle the events they buffered,
+	// and then the store, once nothing uses them any longer.
+	var workers sync.WaitGroup
+	shutdown := func() {
+		stop()
+		workers.Wait()
+		bus.Close()
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close %s store: %v", cfg.Store, err)
+		}
+	}
+
+	if cfg.TrashRetention > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			PurgeTrash(ctx, entryService, cfg.TrashRetention, trashPurgeInterval)
+		}()
+		log.Printf("Purging entries kept in the trash for longer than %s", cfg.TrashRetention)
+	}
+
+	var authenticators []mux.MiddlewareFunc
+	if cfg.JWKSPath != "" {
+		verifier, err := NewVerifier(ctx, cfg)
+		if err != nil {
+			shutdown()
+			log.Fatalf("Failed to set up JWT authentication: %v", err)
+		}
+		authenticators = append(authenticators, verifier.Authenticate)
+		log.Printf("Accepting JWTs verified by %s", cfg.JWKSPath)
+	}
 
 	router := mux.NewRouter()
-	SetupRoutes(router, httpHandler)
+	SetupRoutes(router, httpHandler, httpListHandler, httpUserHandler, httpAccessHandler, httpViewHandler, authenticators...)
 
+	server := &http.Server{Addr: cfg.Addr, Handler: router}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
 	log.Println("Finished setup")
-	log.Fatal(http.ListenAndServe(":8080", router))
+
+	select {
+	case err := <-served:
+		shutdown()
+		log.Fatalf("Failed to serve HTTP: %v", err)
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down")
+	timeout, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(timeout); err != nil {
+		log.Printf("Failed to complete the requests in flight: %v", err)
+	}
+	shutdown()
+	log.Println("Stopped HTTP server")
 }
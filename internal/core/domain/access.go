@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Role is the level of access a user has to a list or an entry. Every role includes the
+// permissions of the roles below it.
+type Role string
+
+const (
+	// RoleViewer may read a resource.
+	RoleViewer Role = "viewer"
+	// RoleEditor may also change and delete entries, and add entries to lists.
+	RoleEditor Role = "editor"
+	// RoleOwner may also change and delete lists, and share resources with other users.
+	RoleOwner Role = "owner"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// Valid reports whether r is one of the defined roles.
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Includes reports whether r grants every permission of the other role. No role includes an
+// invalid one, and the empty role, describing users without access, includes none.
+func (r Role) Includes(other Role) bool {
+	return other.Valid() && roleRanks[r] >= roleRanks[other]
+}
+
+// Max returns the role with more permissions of r and other.
+func (r Role) Max(other Role) Role {
+	if roleRanks[other] > roleRanks[r] {
+		return other
+	}
+	return r
+}
+
+// ResourceKind names the type of resource a grant gives access to.
+type ResourceKind string
+
+const (
+	ResourceList  ResourceKind = "list"
+	ResourceEntry ResourceKind = "entry"
+)
+
+// Grant gives a user a role on a list or an entry owned by someone else. Grants on a list apply
+// to every entry in it, and grants on an entry apply to all of its subtasks. Username is not
+// stored; services fill it in when listing the collaborators of a resource.
+type Grant struct {
+	Kind       ResourceKind `json:"resource_type"`
+	ResourceID string       `json:"resource_id"`
+	UserID     string       `json:"user_id"`
+	Username   string       `json:"username,omitempty"`
+	Role       Role         `json:"role"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// GrantInput holds the values chosen when sharing a resource with another user.
+type GrantInput struct {
+	Username string `json:"username"`
+	Role     Role   `json:"role"`
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Clock returns the current time. Services read the time from a clock, so that it can be fixed.
+type Clock func() time.Time
+
+// Timestamp returns the current time as stored on resources: in UTC.
+func (c Clock) Timestamp() time.Time {
+	return c().UTC()
+}
@@ -1,13 +1,85 @@
 package domain
 
-import uuid2 "github.com/google/uuid"
+import (
+	uuid2 "github.com/google/uuid"
+	"time"
+)
 
 // Entry object describes a to-do instance.
+//
+// A recurring entry carries the Recurrence of its series and the number of its Occurrence within
+// it. Completing it creates the next occurrence, which takes the recurrence over.
+//
+// Entries belong to the List with ListID, or to no list when it is empty. Subtasks always
+// belong to the list of their parent. Entries are visible to the user with OwnerID and to the
+// users they, or their list, are shared with.
+//
+// Version starts at 1 and is incremented by every update, so that changes based on an outdated
+// copy of an entry can be detected.
 type Entry struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Done        bool   `json:"done"`
+	ID          string      `json:"id"`
+	Version     int64       `json:"version"`
+	OwnerID     string      `json:"owner_id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Done        bool        `json:"done"`
+	ParentID    string      `json:"parent_id,omitempty"`
+	ListID      string      `json:"list_id,omitempty"`
+	Priority    Priority    `json:"priority"`
+	Tags        []string    `json:"tags,omitempty"`
+	DueAt       *time.Time  `json:"due_at,omitempty"`
+	Recurrence  *Recurrence `json:"recurrence,omitempty"`
+	Occurrence  int         `json:"occurrence,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+}
+
+// EntryInput holds the values a user can choose when creating an entry. In Quick mode, the
+// title is typed as in a quick-add box, and other fields of the entry are parsed from it.
+type EntryInput struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	ParentID    string      `json:"parent_id,omitempty"`
+	ListID      string      `json:"list_id,omitempty"`
+	Priority    Priority    `json:"priority"`
+	Tags        []string    `json:"tags,omitempty"`
+	DueAt       *time.Time  `json:"due_at,omitempty"`
+	Recurrence  *Recurrence `json:"recurrence,omitempty"`
+	Quick       bool        `json:"quick,omitempty"`
+}
+
+// DeleteOptions controls how an entry is deleted.
+type DeleteOptions struct {
+	// Cascade deletes the subtasks of the entry along with it. Without it, entries that
+	// have subtasks cannot be deleted.
+	Cascade bool
+	// Version, when not zero, only deletes the entry while it is at that version.
+	Version int64
+	// Permanent removes the entry right away, instead of moving it to the trash.
+	Permanent bool
+}
+
+// EntryNode is an entry together with its subtasks, forming a tree.
+type EntryNode struct {
+	*Entry
+	Children []*EntryNode `json:"children"`
+}
+
+// TagCount is the number of entries carrying a tag.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// HasTag reports whether the entry carries the given tag.
+func (e *Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // NewEntry returns a pointer to a new Entry object.
@@ -15,6 +87,7 @@ func NewEntry(title, description string) *Entry {
 	id := uuid2.NewString()
 	return &Entry{
 		ID:          id,
+		Version:     1,
 		Title:       title,
 		Description: description,
 		Done:        false,
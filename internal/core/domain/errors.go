@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sentinel errors describing the category of a failure. Errors returned by repositories and
+// services wrap one of these, so callers can tell them apart with errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrValidation = errors.New("validation failed")
+	ErrConflict   = errors.New("conflict")
+	ErrInternal   = errors.New("internal error")
+	// ErrUnauthenticated signals a request that is not made on behalf of a known user.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden signals a request by a user whose role does not permit it.
+	ErrForbidden = errors.New("forbidden")
+	// ErrPreconditionFailed signals a change made against a version of a resource that is no
+	// longer current.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is a failure belonging to one of the sentinel categories, optionally caused by another error.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Is reports whether the error belongs to the target category.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the error that caused the failure, if any.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NotFound returns an error signalling that the requested resource does not exist.
+func NotFound(message string) error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+// Conflict returns an error signalling that the request clashes with the current state of a resource.
+func Conflict(message string) error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+// Unauthenticated returns an error signalling that the caller could not be identified.
+func Unauthenticated(message string) error {
+	return &Error{Kind: ErrUnauthenticated, Message: message}
+}
+
+// Forbidden returns an error signalling that the caller lacks the role the request requires.
+func Forbidden(message string) error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+// PreconditionFailed returns an error signalling that the resource changed since the version the
+// request was based on.
+func PreconditionFailed(message string) error {
+	return &Error{Kind: ErrPreconditionFailed, Message: message}
+}
+
+// Internal returns an error signalling an unexpected failure caused by err.
+func Internal(message string, err error) error {
+	return &Error{Kind: ErrInternal, Message: message, Err: err}
+}
+
+// Categorise passes errors belonging to a category, including validation errors, through
+// untouched and reports anything else as an internal failure described by message.
+func Categorise(message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var categorised *Error
+	var invalid *ValidationError
+	if errors.As(err, &categorised) || errors.As(err, &invalid) {
+		return err
+	}
+	return Internal(message, err)
+}
+
+// FieldError describes why the value of a single field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when input is rejected, listing every offending field.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// NewValidationError returns a ValidationError for a single field.
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+func (e *ValidationError) Error() string {
+	details := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		details[i] = field.Field + ": " + field.Message
+	}
+	return ErrValidation.Error() + ": " + strings.Join(details, ", ")
+}
+
+// Is reports whether the target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCategorise(t *testing.T) {
+	t.Run("should pass errors of every category through", func(t *testing.T) {
+		for _, err := range []error{
+			NotFound("missing"),
+			Conflict("clash"),
+			Unauthenticated("who"),
+			Forbidden("no"),
+			PreconditionFailed("stale"),
+			Internal("broken", errors.New("disk full")),
+			NewValidationError("title", "too short"),
+			fmt.Errorf("wrapped: %w", Forbidden("no")),
+		} {
+			assert.Same(t, err, Categorise("failed", err))
+		}
+	})
+
+	t.Run("should report other errors as internal failures", func(t *testing.T) {
+		cause := errors.New("disk full")
+		err := Categorise("failed", cause)
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.ErrorIs(t, err, cause)
+		assert.EqualValues(t, "failed: disk full", err.Error())
+	})
+
+	t.Run("should return nil without an error", func(t *testing.T) {
+		assert.NoError(t, Categorise("failed", nil))
+	})
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// EntryEventType names the kind of change an entry event records.
+type EntryEventType string
+
+const (
+	EntryCreated   EntryEventType = "entry_created"
+	EntryRenamed   EntryEventType = "entry_renamed"
+	EntryCompleted EntryEventType = "entry_completed"
+	EntryReopened  EntryEventType = "entry_reopened"
+	EntryChanged   EntryEventType = "entry_changed"
+	EntryDeleted   EntryEventType = "entry_deleted"
+)
+
+// EntryEvent records a change made to the entry with EntryID, which was at Version and last
+// updated At afterwards. Created and changed events carry the whole Entry as it was afterwards,
+// renamed events its new Title and completed events the time it was CompletedAt. A single change
+// can be recorded by several events, which then share their version.
+type EntryEvent struct {
+	Type        EntryEventType `json:"type"`
+	EntryID     string         `json:"entry_id"`
+	Version     int64          `json:"version"`
+	At          time.Time      `json:"at"`
+	Entry       *Entry         `json:"entry,omitempty"`
+	Title       string         `json:"title,omitempty"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+}
+
+// EventType names the kind of change an event published by the entry service announces.
+type EventType string
+
+const (
+	EventCreated   EventType = "created"
+	EventUpdated   EventType = "updated"
+	EventCompleted EventType = "completed"
+	EventReopened  EventType = "reopened"
+	EventDeleted   EventType = "deleted"
+)
+
+// Event announces a change made to the entry with EntryID by the user with ActorID, once it is
+// stored. Unlike the entry events kept by event-sourced repositories, events are published to
+// the subscribers of an event bus and not kept. Updated events list the fields that changed, and
+// are followed by a completed or reopened event when the update changed the completion of the
+// entry. Entry holds the entry as it was after the change, and is nil for deletions.
+type Event struct {
+	Type    EventType     `json:"type"`
+	EntryID string        `json:"entry_id"`
+	ActorID string        `json:"actor_id"`
+	At      time.Time     `json:"at"`
+	Changes []FieldChange `json:"changes,omitempty"`
+	Entry   *Entry        `json:"entry,omitempty"`
+}
+
+// OverflowPolicy tells what publishing an event does when the buffer of an asynchronous
+// subscriber is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock makes publishing wait until the subscriber catches up, or the context of the
+	// publisher is done, in which case the event is dropped.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDrop drops the event for the subscriber right away.
+	OverflowDrop OverflowPolicy = "drop"
+)
+
+// Subscription configures an asynchronous subscriber of an event bus, handling events from a
+// buffer holding up to Buffer of them in the background.
+type Subscription struct {
+	Buffer   int
+	Overflow OverflowPolicy
+}
@@ -0,0 +1,514 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter is a compiled filter expression, selecting the entries it matches. Expressions combine
+// conditions on the fields of entries with AND, OR and NOT, in that order of precedence, and
+// parentheses:
+//
+//	done:false AND tag:work AND due<+3d OR priority:high
+//
+// Conditions are written as field, operator and value. Adjacent conditions are implicitly joined
+// by AND, and a leading '-' negates a condition or group. Values containing spaces or
+// parentheses are double quoted. The fields and the operators they support are:
+//
+//	done                                  : = !=     true or false
+//	tag                                   : = !=     a tag
+//	priority                              all        none, low, medium or high
+//	due, created, updated, completed      all        a date, a time or none
+//	title, description                    : = !=     text contained, ignoring case
+//	list, parent                          : = !=     an ID, or none
+//
+// where all stands for : = != < <= > and >=. Dates are written as 2006-01-02, times in RFC 3339,
+// and both relative to the time the filter is parsed at as today, tomorrow, yesterday, now or
+// a signed number of hours, days, weeks, months or years, e.g. +3d or -1w. Dates stand for the
+// whole day in UTC, so that due:today matches any time of the day. Entries without the time
+// only match != conditions on it. Tags are normalised as they are on entries, so that tag:"my tag"
+// matches my-tag. Words without a field match entries whose title or description contain them.
+type Filter struct {
+	text  string
+	match func(entry *Entry) bool
+}
+
+// maxFilterDepth bounds the nesting of groups and negations in a filter expression.
+const maxFilterDepth = 32
+
+// ParseFilter compiles the filter expression text, resolving relative dates against now.
+func ParseFilter(text string, now time.Time) (*Filter, error) {
+	tokens, err := lexFilter(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens, now: now.UTC()}
+	if p.peek().kind == tokenEnd {
+		return nil, NewValidationError("query", "cannot be empty")
+	}
+	match, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, filterError(t.pos, "unexpected %s", t)
+	}
+
+	return &Filter{text: text, match: match}, nil
+}
+
+// Matches reports whether the filter selects the entry.
+func (f *Filter) Matches(entry *Entry) bool {
+	return f.match(entry)
+}
+
+// String returns the expression the filter was parsed from.
+func (f *Filter) String() string {
+	return f.text
+}
+
+func filterError(pos int, format string, args ...interface{}) error {
+	return NewValidationError("query", fmt.Sprintf(format, args...)+" at position "+strconv.Itoa(pos+1))
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenCondition
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+// filterToken is a token of a filter expression. Conditions carry their field and operator,
+// which are empty for bare words, and their unquoted value.
+type filterToken struct {
+	kind             tokenKind
+	pos              int
+	field, op, value string
+}
+
+func (t filterToken) String() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of query"
+	case tokenAnd:
+		return "AND"
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "NOT"
+	case tokenOpen:
+		return "'('"
+	case tokenClose:
+		return "')'"
+	}
+	return strconv.Quote(t.field + t.op + t.value)
+}
+
+// filterOperators lists the operators of conditions, longest first so that they are matched
+// greedily.
+var filterOperators = []string{"!=", "<=", ">=", ":", "=", "<", ">"}
+
+func lexFilter(text string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokenOpen, pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokenClose, pos: i})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, filterToken{kind: tokenNot, pos: i})
+			i++
+		default:
+			t, next, err := lexCondition(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = next
+		}
+	}
+	return append(tokens, filterToken{kind: tokenEnd, pos: len(runes)}), nil
+}
+
+// lexCondition reads the condition or keyword starting at start, returning it along with the
+// position following it.
+func lexCondition(runes []rune, start int) (filterToken, int, error) {
+	t := filterToken{kind: tokenCondition, pos: start}
+
+	i := start
+	for i < len(runes) && unicode.IsLetter(runes[i]) {
+		i++
+	}
+	if i > start {
+		rest := string(runes[i:])
+		for _, op := range filterOperators {
+			if strings.HasPrefix(rest, op) {
+				t.field, t.op = strings.ToLower(string(runes[start:i])), op
+				i += len([]rune(op))
+				break
+			}
+		}
+	}
+	if t.op == "" {
+		i = start
+	}
+
+	var value strings.Builder
+	quoted := false
+	for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+		if runes[i] != '"' {
+			value.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		quoted = true
+		open := i
+		for i++; ; i++ {
+			if i >= len(runes) {
+				return t, i, filterError(open, "unterminated quote")
+			}
+			if runes[i] == '\\' && i+1 < len(runes) {
+				i++
+			} else if runes[i] == '"' {
+				i++
+				break
+			}
+			value.WriteRune(runes[i])
+		}
+	}
+	t.value = value.String()
+
+	if t.op == "" && !quoted {
+		switch t.value {
+		case "AND":
+			t.kind = tokenAnd
+		case "OR":
+			t.kind = tokenOr
+		case "NOT":
+			t.kind = tokenNot
+		}
+	}
+	if t.kind == tokenCondition && t.op != "" && t.value == "" {
+		return t, i, filterError(start, "missing value of %s", t.field)
+	}
+	return t, i, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	now    time.Time
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) parseOr(depth int) (func(*Entry) bool, error) {
+	var any []func(*Entry) bool
+	for {
+		match, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		any = append(any, match)
+		if p.peek().kind != tokenOr {
+			break
+		}
+		p.next()
+	}
+
+	if len(any) == 1 {
+		return any[0], nil
+	}
+	return func(entry *Entry) bool {
+		for _, match := range any {
+			if match(entry) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (p *filterParser) parseAnd(depth int) (func(*Entry) bool, error) {
+	var all []func(*Entry) bool
+	for {
+		match, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, match)
+
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+			continue
+		case tokenCondition, tokenNot, tokenOpen:
+			continue
+		}
+		break
+	}
+
+	if len(all) == 1 {
+		return all[0], nil
+	}
+	return func(entry *Entry) bool {
+		for _, match := range all {
+			if !match(entry) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func (p *filterParser) parseNot(depth int) (func(*Entry) bool, error) {
+	if depth >= maxFilterDepth {
+		return nil, filterError(p.peek().pos, "query nested too deeply")
+	}
+
+	t := p.next()
+	switch t.kind {
+	case tokenNot:
+		match, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return func(entry *Entry) bool { return !match(entry) }, nil
+	case tokenOpen:
+		match, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenClose {
+			return nil, filterError(closing.pos, "expected ')' instead of %s", closing)
+		}
+		return match, nil
+	case tokenCondition:
+		return p.condition(t)
+	}
+	return nil, filterError(t.pos, "unexpected %s", t)
+}
+
+// condition compiles a single condition.
+func (p *filterParser) condition(t filterToken) (func(*Entry) bool, error) {
+	switch t.field {
+	case "":
+		return containsText(t.value, func(e *Entry) string { return e.Title + "\n" + e.Description }), nil
+	case "title":
+		return p.textCondition(t, func(e *Entry) string { return e.Title })
+	case "description":
+		return p.textCondition(t, func(e *Entry) string { return e.Description })
+	case "done":
+		done, err := strconv.ParseBool(t.value)
+		if err != nil {
+			return nil, filterError(t.pos, "done must be true or false")
+		}
+		return p.equality(t, func(e *Entry) bool { return e.Done == done })
+	case "tag":
+		tag, err := NormalizeTag(t.value)
+		if err != nil {
+			return nil, filterError(t.pos, "tag %q is not a valid tag", t.value)
+		}
+		return p.equality(t, func(e *Entry) bool { return e.HasTag(tag) })
+	case "list":
+		id := noneAsEmpty(t.value)
+		return p.equality(t, func(e *Entry) bool { return e.ListID == id })
+	case "parent":
+		id := noneAsEmpty(t.value)
+		return p.equality(t, func(e *Entry) bool { return e.ParentID == id })
+	case "priority":
+		priority, err := ParsePriority(strings.ToLower(t.value))
+		if err != nil {
+			return nil, filterError(t.pos, "priority must be one of none, low, medium or high")
+		}
+		return comparison(t.op, func(e *Entry) (int, bool) {
+			return compareInts(int(e.Priority), int(priority)), true
+		}), nil
+	case "due":
+		return p.timeCondition(t, func(e *Entry) *time.Time { return e.DueAt })
+	case "created":
+		return p.timeCondition(t, func(e *Entry) *time.Time { return &e.CreatedAt })
+	case "updated":
+		return p.timeCondition(t, func(e *Entry) *time.Time { return &e.UpdatedAt })
+	case "completed":
+		return p.timeCondition(t, func(e *Entry) *time.Time { return e.CompletedAt })
+	}
+	return nil, filterError(t.pos, "unknown field %q", t.field)
+}
+
+// equality turns match into a condition supporting the equality operators only.
+func (p *filterParser) equality(t filterToken, match func(*Entry) bool) (func(*Entry) bool, error) {
+	switch t.op {
+	case ":", "=":
+		return match, nil
+	case "!=":
+		return func(entry *Entry) bool { return !match(entry) }, nil
+	}
+	return nil, filterError(t.pos, "%s cannot be compared with %s", t.field, t.op)
+}
+
+func (p *filterParser) textCondition(t filterToken, field func(*Entry) string) (func(*Entry) bool, error) {
+	return p.equality(t, containsText(t.value, field))
+}
+
+func containsText(text string, field func(*Entry) string) func(*Entry) bool {
+	text = strings.ToLower(text)
+	return func(entry *Entry) bool {
+		return strings.Contains(strings.ToLower(field(entry)), text)
+	}
+}
+
+// timeCondition compiles a condition on a time of the entry. Times are compared to the interval
+// [from, to) named by the value; instants are intervals of a nanosecond.
+func (p *filterParser) timeCondition(t filterToken, field func(*Entry) *time.Time) (func(*Entry) bool, error) {
+	if strings.EqualFold(t.value, "none") {
+		return p.equality(t, func(e *Entry) bool { return field(e) == nil })
+	}
+
+	from, to, err := p.interval(t)
+	if err != nil {
+		return nil, err
+	}
+	return comparison(t.op, func(e *Entry) (int, bool) {
+		at := field(e)
+		switch {
+		case at == nil:
+			return 0, false
+		case at.Before(from):
+			return -1, true
+		case at.Before(to):
+			return 0, true
+		}
+		return 1, true
+	}), nil
+}
+
+// interval resolves the time value of the condition to the interval [from, to) it stands for.
+func (p *filterParser) interval(t filterToken) (time.Time, time.Time, error) {
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, time.UTC)
+	day := func(start time.Time) (time.Time, time.Time, error) {
+		return start, start.AddDate(0, 0, 1), nil
+	}
+	instant := func(at time.Time) (time.Time, time.Time, error) {
+		return at, at.Add(time.Nanosecond), nil
+	}
+
+	value := strings.ToLower(t.value)
+	switch value {
+	case "now":
+		return instant(p.now)
+	case "today":
+		return day(today)
+	case "tomorrow":
+		return day(today.AddDate(0, 0, 1))
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1))
+	}
+
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return day(date)
+	}
+	if at, err := time.Parse(time.RFC3339, t.value); err == nil {
+		return instant(at)
+	}
+	if at, ok := p.relative(value); ok {
+		return instant(at)
+	}
+	return time.Time{}, time.Time{}, filterError(t.pos, "%s must be a date, a time, a relative time such as +3d or none", t.field)
+}
+
+// relative resolves offsets from now such as +3d or -1w.
+func (p *filterParser) relative(value string) (time.Time, bool) {
+	if len(value) < 3 || (value[0] != '+' && value[0] != '-') {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	if value[0] == '-' {
+		n = -n
+	}
+
+	switch value[len(value)-1] {
+	case 'h':
+		return p.now.Add(time.Duration(n) * time.Hour), true
+	case 'd':
+		return p.now.AddDate(0, 0, n), true
+	case 'w':
+		return p.now.AddDate(0, 0, 7*n), true
+	case 'm':
+		return p.now.AddDate(0, n, 0), true
+	case 'y':
+		return p.now.AddDate(n, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+// comparison turns compare, which orders a field of the entry against the value of the condition
+// and reports whether the entry has the field at all, into a condition for the operator.
+func comparison(op string, compare func(*Entry) (int, bool)) func(*Entry) bool {
+	return func(entry *Entry) bool {
+		c, ok := compare(entry)
+		if !ok {
+			return op == "!="
+		}
+		switch op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		case ">=":
+			return c >= 0
+		case "!=":
+			return c != 0
+		}
+		return c == 0
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func noneAsEmpty(value string) string {
+	if strings.EqualFold(value, "none") {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	at := func(day, hour int) *time.Time {
+		t := time.Date(2021, 3, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	entries := []*Entry{
+		{ID: "report", Title: "Write report", Tags: []string{"work"}, DueAt: at(12, 9), Priority: PriorityLow},
+		{ID: "deploy", Title: "Deploy release", Description: "Ship v2", Tags: []string{"#release", "work"}, DueAt: at(20, 9), Priority: PriorityHigh},
+		{ID: "milk", Title: "Buy milk", Tags: []string{"home", "my-tag"}, Done: true, CompletedAt: at(9, 18), ListID: "groceries"},
+		{ID: "plants", Title: "Water plants", Tags: []string{"home"}, DueAt: at(10, 8), ParentID: "garden"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "should bind AND tighter than OR",
+			query:    "done:false AND tag:work AND due<+3d OR priority:high",
+			expected: []string{"report", "deploy"},
+		},
+		{
+			name:     "should group with parentheses",
+			query:    "done:false AND (tag:work OR tag:home) AND due<+3d",
+			expected: []string{"report", "plants"},
+		},
+		{
+			name:     "should join adjacent conditions with AND",
+			query:    "tag:work priority>=medium",
+			expected: []string{"deploy"},
+		},
+		{
+			name:     "should normalise tags as they are on entries",
+			query:    `tag:"  My Tag " OR tag:#Release`,
+			expected: []string{"deploy", "milk"},
+		},
+		{
+			name:     "should negate conditions and groups",
+			query:    "-tag:work NOT (done:true)",
+			expected: []string{"plants"},
+		},
+		{
+			name:     "should match the whole day of dates",
+			query:    "due:today OR completed:2021-03-09",
+			expected: []string{"milk", "plants"},
+		},
+		{
+			name:     "should compare with the end of the day of dates",
+			query:    "due<=2021-03-12",
+			expected: []string{"report", "plants"},
+		},
+		{
+			name:     "should match missing times with none and inequality only",
+			query:    "due:none OR (due!=none due>2021-03-19)",
+			expected: []string{"deploy", "milk"},
+		},
+		{
+			name:     "should match quoted text in titles and descriptions, ignoring case",
+			query:    `"SHIP V2" OR title:"buy milk"`,
+			expected: []string{"deploy", "milk"},
+		},
+		{
+			name:     "should match lists and parents",
+			query:    "list:groceries OR (list:none parent:garden)",
+			expected: []string{"milk", "plants"},
+		},
+		{
+			name:     "should compare times with relative and absolute times",
+			query:    "due>-1w due<2021-03-12T10:00:00Z",
+			expected: []string{"report", "plants"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := ParseFilter(test.query, now)
+			require.NoError(t, err)
+			assert.EqualValues(t, test.query, filter.String())
+
+			matched := []string{}
+			for _, entry := range entries {
+				if filter.Matches(entry) {
+					matched = append(matched, entry.ID)
+				}
+			}
+			assert.EqualValues(t, test.expected, matched)
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{name: "should reject empty queries", query: "  ", message: "cannot be empty"},
+		{name: "should reject unknown fields", query: "done:false colour:red", message: `unknown field "colour" at position 12`},
+		{name: "should reject unsupported operators", query: "tag>work", message: "tag cannot be compared with > at position 1"},
+		{name: "should reject invalid tags", query: "tag:@@home", message: `tag "@@home" is not a valid tag at position 1`},
+		{name: "should reject invalid values", query: "due<soon", message: "due must be a date, a time, a relative time such as +3d or none at position 1"},
+		{name: "should reject missing values", query: "done:", message: "missing value of done at position 1"},
+		{name: "should reject unbalanced parentheses", query: "(tag:work", message: "expected ')' instead of end of query at position 10"},
+		{name: "should reject dangling operators", query: "tag:work OR", message: "unexpected end of query at position 12"},
+		{name: "should reject unterminated quotes", query: `title:"milk`, message: "unterminated quote at position 7"},
+		{name: "should reject deeply nested queries", query: "NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT done:true", message: "query nested too deeply"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseFilter(test.query, time.Now())
+			assert.ErrorIs(t, err, ErrValidation)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	uuid2 "github.com/google/uuid"
+	"time"
+)
+
+// List is a named collection of entries, such as a project. Every entry belongs to at most one
+// list, owned by the same user as the entry.
+type List struct {
+	ID          string    `json:"id"`
+	OwnerID     string    `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListInput holds the values a user can choose when creating a list.
+type ListInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ListDeleteOptions controls what happens to the entries of a deleted list. Lists that still
+// have entries can only be deleted when exactly one of the options is set.
+type ListDeleteOptions struct {
+	// Cascade deletes the entries of the list, including their subtasks, along with it.
+	Cascade bool
+	// ReassignTo moves the entries of the list to the list with this ID.
+	ReassignTo string
+}
+
+// NewList returns a pointer to a new List object.
+func NewList(name, description string) *List {
+	return &List{
+		ID:          uuid2.NewString(),
+		Name:        name,
+		Description: description,
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// OperationKind names the kind of change an operation made to entries.
+type OperationKind string
+
+const (
+	OperationCreate OperationKind = "create"
+	OperationUpdate OperationKind = "update"
+	OperationDelete OperationKind = "delete"
+)
+
+// Operation describes a change a user made to entries, which can be undone and redone. EntryID
+// names the entry the change was made to; any other entries changed along with it, such as its
+// subtasks or parent, are changed back with it. Entries holds every entry changed by undoing or
+// redoing the operation, as it was afterwards, except for the entries it removed.
+type Operation struct {
+	Kind    OperationKind `json:"kind"`
+	EntryID string        `json:"entry_id"`
+	At      time.Time     `json:"at"`
+	Entries []*Entry      `json:"entries"`
+}
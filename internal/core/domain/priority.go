@@ -0,0 +1,66 @@
+package domain
+
+import "fmt"
+
+// Priority ranks how urgent an entry is. Its zero value, PriorityNone, sorts below every
+// other priority.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+var priorityNames = map[Priority]string{
+	PriorityNone:   "none",
+	PriorityLow:    "low",
+	PriorityMedium: "medium",
+	PriorityHigh:   "high",
+}
+
+// ParsePriority returns the priority with the given name.
+func ParsePriority(name string) (Priority, error) {
+	for p, n := range priorityNames {
+		if n == name {
+			return p, nil
+		}
+	}
+	return PriorityNone, NewValidationError("priority", "must be one of none, low, medium or high")
+}
+
+// Valid reports whether p is one of the defined priorities.
+func (p Priority) Valid() bool {
+	_, ok := priorityNames[p]
+	return ok
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+// MarshalText encodes the priority as its name.
+func (p Priority) MarshalText() ([]byte, error) {
+	if !p.Valid() {
+		return nil, fmt.Errorf("invalid priority %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a priority from its name. An empty name decodes to PriorityNone.
+func (p *Priority) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*p = PriorityNone
+		return nil
+	}
+	parsed, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortField names the Entry attribute used to order a listing.
+type SortField string
+
+const (
+	SortByID          SortField = "id"
+	SortByTitle       SortField = "title"
+	SortByPriority    SortField = "priority"
+	SortByDueAt       SortField = "due_at"
+	SortByCreatedAt   SortField = "created_at"
+	SortByUpdatedAt   SortField = "updated_at"
+	SortByCompletedAt SortField = "completed_at"
+)
+
+// Valid reports whether f is one of the sortable fields.
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByID, SortByTitle, SortByPriority, SortByDueAt, SortByCreatedAt, SortByUpdatedAt, SortByCompletedAt:
+		return true
+	}
+	return false
+}
+
+// SortOrder is the direction in which a listing is ordered.
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// ListQuery describes which entries a listing should return and in which order.
+//
+// ParentID restricts the listing to the subtasks of the entry with that ID; pointing it at an
+// empty string lists top-level entries only. ListID likewise restricts the listing to the
+// entries of a list, or to entries in no list. OwnerID restricts the listing to the entries of
+// a user; services always set it to the user the listing is made for. Filter, when set, further
+// restricts the listing to the entries matching a filter expression.
+type ListQuery struct {
+	OwnerID       string
+	Done          *bool
+	ParentID      *string
+	ListID        *string
+	TitleContains string
+	Tags          []string
+	Priority      *Priority
+	DueBefore     *time.Time
+	DueAfter      *time.Time
+	Filter        *Filter
+	SortBy        SortField
+	Order         SortOrder
+	Cursor        string
+	Limit         int
+}
+
+// EntryPage is a single page of a listing. NextCursor is empty on the last page.
+type EntryPage struct {
+	Entries    []*Entry `json:"entries"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+type cursor struct {
+	SortBy SortField `json:"s"`
+	Order  SortOrder `json:"o"`
+	Key    string    `json:"k"`
+	ID     string    `json:"i"`
+}
+
+// Matches reports whether the entry satisfies the filters of the query.
+func (q ListQuery) Matches(entry *Entry) bool {
+	if q.OwnerID != "" && entry.OwnerID != q.OwnerID {
+		return false
+	}
+	if q.Done != nil && entry.Done != *q.Done {
+		return false
+	}
+	if q.ParentID != nil && entry.ParentID != *q.ParentID {
+		return false
+	}
+	if q.ListID != nil && entry.ListID != *q.ListID {
+		return false
+	}
+	if q.TitleContains != "" &&
+		!strings.Contains(strings.ToLower(entry.Title), strings.ToLower(q.TitleContains)) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !entry.HasTag(tag) {
+			return false
+		}
+	}
+	if q.Priority != nil && entry.Priority != *q.Priority {
+		return false
+	}
+	if q.DueBefore != nil && (entry.DueAt == nil || !entry.DueAt.Before(*q.DueBefore)) {
+		return false
+	}
+	if q.DueAfter != nil && (entry.DueAt == nil || !entry.DueAt.After(*q.DueAfter)) {
+		return false
+	}
+	if q.Filter != nil && !q.Filter.Matches(entry) {
+		return false
+	}
+
+	return true
+}
+
+// CountTags returns how many of the given entries carry each tag, ordered by descending count
+// and then by tag.
+func CountTags(entries []*Entry) []TagCount {
+	counts := map[string]int{}
+	for _, entry := range entries {
+		for _, tag := range entry.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
+
+// Paginate orders the given entries as requested by the query, skips everything up to and
+// including the position encoded in the query cursor and returns at most Limit entries.
+// A Limit of zero or less returns every remaining entry.
+func Paginate(entries []*Entry, q ListQuery) (*EntryPage, error) {
+	sortBy, order := q.SortBy, q.Order
+	if sortBy == "" {
+		sortBy = SortByID
+	}
+	if order == "" {
+		order = SortAscending
+	}
+
+	sorted := make([]*Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return before(sorted[i], sorted[j], sortBy, order)
+	})
+
+	start := 0
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return &EntryPage{}, err
+		}
+		if c.SortBy != sortBy || c.Order != order {
+			return &EntryPage{}, NewValidationError("cursor", "does not match the requested sort order")
+		}
+		start = sort.Search(len(sorted), func(i int) bool {
+			return afterPosition(sorted[i], c)
+		})
+	}
+
+	end := len(sorted)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+
+	page := &EntryPage{Entries: sorted[start:end]}
+	if end < len(sorted) {
+		last := sorted[end-1]
+		page.NextCursor = encodeCursor(cursor{
+			SortBy: sortBy,
+			Order:  order,
+			Key:    sortKey(last, sortBy),
+			ID:     last.ID,
+		})
+	}
+
+	return page, nil
+}
+
+// SortableTimeLayout formats times in UTC with a fixed width, so that their lexical order
+// matches their chronological order.
+const SortableTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// noTimeKey sorts after every formatted time, placing entries without the time last in
+// ascending order.
+const noTimeKey = "~"
+
+// sortKey returns a string representation of the sorted attribute whose lexical order
+// matches the order of the attribute itself.
+func sortKey(entry *Entry, field SortField) string {
+	switch field {
+	case SortByTitle:
+		return strings.ToLower(entry.Title)
+	case SortByPriority:
+		return strconv.Itoa(int(entry.Priority))
+	case SortByDueAt:
+		return timeKey(entry.DueAt)
+	case SortByCreatedAt:
+		return timeKey(&entry.CreatedAt)
+	case SortByUpdatedAt:
+		return timeKey(&entry.UpdatedAt)
+	case SortByCompletedAt:
+		return timeKey(entry.CompletedAt)
+	default:
+		return entry.ID
+	}
+}
+
+func timeKey(t *time.Time) string {
+	if t == nil {
+		return noTimeKey
+	}
+	return t.UTC().Format(SortableTimeLayout)
+}
+
+func compare(key, id, otherKey, otherID string) int {
+	if key != otherKey {
+		return strings.Compare(key, otherKey)
+	}
+	return strings.Compare(id, otherID)
+}
+
+func before(a, b *Entry, field SortField, order SortOrder) bool {
+	c := compare(sortKey(a, field), a.ID, sortKey(b, field), b.ID)
+	if order == SortDescending {
+		return c > 0
+	}
+	return c < 0
+}
+
+func afterPosition(entry *Entry, c cursor) bool {
+	cmp := compare(sortKey(entry, c.SortBy), entry.ID, c.Key, c.ID)
+	if c.Order == SortDescending {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+func encodeCursor(c cursor) string {
+	bytes, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	c := cursor{}
+	bytes, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, NewValidationError("cursor", "is malformed")
+	}
+	if err := json.Unmarshal(bytes, &c); err != nil {
+		return c, NewValidationError("cursor", "is malformed")
+	}
+	return c, nil
+}
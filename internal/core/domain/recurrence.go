@@ -0,0 +1,425 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	// Recurrence rules name their time zone, which has to resolve on hosts without a zoneinfo database.
+	_ "time/tzdata"
+)
+
+// Frequency is the unit of time by which a recurrence repeats.
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum selects a weekday. In monthly rules a non-zero N narrows it down to the Nth such
+// day of the month, counting from the end of the month when negative; -1FR is the last Friday.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var weekdayCodes = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+func (w WeekdayNum) String() string {
+	if w.N != 0 {
+		return strconv.Itoa(w.N) + weekdayCodes[w.Weekday]
+	}
+	return weekdayCodes[w.Weekday]
+}
+
+// Recurrence describes how an entry repeats, using the subset of iCalendar (RFC 5545) RRULEs made
+// of FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL. Occurrences keep the wall clock time of
+// the first one in the time zone named by TZID, an extension to RRULE standing in for the zone of
+// DTSTART, so that an entry due at 9:00 stays due at 9:00 across daylight saving changes. Rules
+// without TZID repeat in UTC.
+//
+// Recurrences are encoded as their RRULE text, e.g. FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH.
+type Recurrence struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+	TZID       string
+}
+
+// ParseRecurrence parses an RRULE, with or without its "RRULE:" prefix.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	r := &Recurrence{}
+	var until string
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"), ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, recurrenceError("malformed rule part " + strconv.Quote(part))
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			until = value
+		case "TZID":
+			r.TZID = value
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, err := parseWeekdayNum(strings.ToUpper(day))
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil {
+					return nil, recurrenceError("BYMONTHDAY must list day numbers")
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		default:
+			return nil, recurrenceError("unsupported rule part " + name)
+		}
+		if err != nil {
+			return nil, recurrenceError(strings.ToUpper(name) + " must be a number")
+		}
+	}
+
+	if until != "" {
+		t, err := parseUntil(until, r.Location())
+		if err != nil {
+			return nil, err
+		}
+		r.Until = &t
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	if len(s) < 2 {
+		return WeekdayNum{}, recurrenceError("malformed BYDAY " + strconv.Quote(s))
+	}
+
+	w := WeekdayNum{}
+	code := s[len(s)-2:]
+	for weekday, c := range weekdayCodes {
+		if c == code {
+			w.Weekday = weekday
+			break
+		}
+	}
+	if weekdayCodes[w.Weekday] != code {
+		return WeekdayNum{}, recurrenceError("malformed BYDAY " + strconv.Quote(s))
+	}
+
+	if n := s[:len(s)-2]; n != "" {
+		var err error
+		if w.N, err = strconv.Atoi(n); err != nil || w.N == 0 {
+			return WeekdayNum{}, recurrenceError("malformed BYDAY " + strconv.Quote(s))
+		}
+	}
+	return w, nil
+}
+
+// parseUntil parses an UNTIL value, which is either a UTC date-time or a date. Dates include
+// the whole day in the given location.
+func parseUntil(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", s, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second).UTC(), nil
+	}
+	return time.Time{}, recurrenceError("UNTIL must be a date or a UTC date-time")
+}
+
+// Validate checks that the rule is supported.
+func (r *Recurrence) Validate() error {
+	switch r.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+	default:
+		return recurrenceError("FREQ must be one of DAILY, WEEKLY, MONTHLY or YEARLY")
+	}
+	if r.Interval < 0 {
+		return recurrenceError("INTERVAL must be positive")
+	}
+	if r.Count < 0 {
+		return recurrenceError("COUNT must be positive")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return recurrenceError("COUNT and UNTIL cannot be combined")
+	}
+	for _, day := range r.ByDay {
+		if day.N != 0 && r.Freq != FreqMonthly {
+			return recurrenceError("numbered BYDAY values are only supported in MONTHLY rules")
+		}
+		if day.N < -5 || day.N > 5 {
+			return recurrenceError("BYDAY numbers must be between -5 and 5")
+		}
+	}
+	if r.Freq == FreqYearly && len(r.ByDay) > 0 {
+		return recurrenceError("BYDAY is not supported in YEARLY rules")
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != FreqMonthly {
+		return recurrenceError("BYMONTHDAY is only supported in MONTHLY rules")
+	}
+	for _, day := range r.ByMonthDay {
+		if day == 0 || day < -31 || day > 31 {
+			return recurrenceError("BYMONTHDAY values must be between 1 and 31 or -31 and -1")
+		}
+	}
+	if r.TZID != "" {
+		if _, err := time.LoadLocation(r.TZID); err != nil {
+			return recurrenceError("TZID must name a known time zone")
+		}
+	}
+	return nil
+}
+
+// Location returns the time zone occurrences are computed in.
+func (r *Recurrence) Location() *time.Location {
+	if r.TZID == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(r.TZID)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// maxSearch bounds the number of periods Next looks at, so that rules matching no date at all,
+// like the 31st of every other February, cannot loop forever.
+const maxSearch = 1000
+
+// Next returns the occurrence following from, which is the occurrence with the given 1-based
+// number in the series. It reports false when the series has ended.
+func (r *Recurrence) Next(from time.Time, occurrence int) (time.Time, bool) {
+	if r.Count > 0 && occurrence >= r.Count {
+		return time.Time{}, false
+	}
+
+	local := from.In(r.Location())
+	var next time.Time
+	var ok bool
+	switch r.Freq {
+	case FreqDaily:
+		next, ok = r.nextDaily(local)
+	case FreqWeekly:
+		next, ok = r.nextWeekly(local)
+	case FreqMonthly:
+		next, ok = r.nextMonthly(local)
+	case FreqYearly:
+		next, ok = r.nextYearly(local)
+	}
+
+	if !ok || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r *Recurrence) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// onDay returns the given date at the wall clock time of t, in the location of t.
+func onDay(t time.Time, year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+func (r *Recurrence) matchesWeekday(day time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, w := range r.ByDay {
+		if w.Weekday == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recurrence) nextDaily(from time.Time) (time.Time, bool) {
+	for i := 1; i <= maxSearch; i++ {
+		next := onDay(from, from.Year(), from.Month(), from.Day()+i*r.interval())
+		if r.matchesWeekday(next.Weekday()) {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// nextWeekly walks the days following from, accepting those on one of the BYDAY weekdays, or on
+// the weekday of from when there are none, in every INTERVAL-th week. Weeks start on Monday.
+func (r *Recurrence) nextWeekly(from time.Time) (time.Time, bool) {
+	if len(r.ByDay) == 0 {
+		return onDay(from, from.Year(), from.Month(), from.Day()+7*r.interval()), true
+	}
+
+	weekStart := from.Day() - (int(from.Weekday())+6)%7
+	for i := 1; i <= 7*r.interval()+7; i++ {
+		next := onDay(from, from.Year(), from.Month(), from.Day()+i)
+		week := (from.Day() + i - weekStart) / 7
+		if week%r.interval() == 0 && r.matchesWeekday(next.Weekday()) {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// nextMonthly looks for the first matching day after from in the month of from and then in every
+// INTERVAL-th month. Without BYDAY or BYMONTHDAY, occurrences fall on the day of the month of
+// from, skipping months too short to have it.
+func (r *Recurrence) nextMonthly(from time.Time) (time.Time, bool) {
+	for i := 0; i <= maxSearch; i++ {
+		first := time.Date(from.Year(), from.Month()+time.Month(i*r.interval()), 1, 0, 0, 0, 0, from.Location())
+		for _, day := range r.monthDays(first.Year(), first.Month(), from.Day()) {
+			next := onDay(from, first.Year(), first.Month(), day)
+			if next.After(from) {
+				return next, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// monthDays returns the ascending days of the given month the rule selects.
+func (r *Recurrence) monthDays(year int, month time.Month, defaultDay int) []int {
+	length := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	selected := map[int]bool{}
+
+	for _, day := range r.ByMonthDay {
+		if day < 0 {
+			day = length + day + 1
+		}
+		if day >= 1 && day <= length {
+			selected[day] = true
+		}
+	}
+
+	firstWeekday := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	for _, w := range r.ByDay {
+		first := 1 + (int(w.Weekday)-int(firstWeekday)+7)%7
+		var candidates []int
+		for day := first; day <= length; day += 7 {
+			candidates = append(candidates, day)
+		}
+		switch {
+		case w.N == 0:
+			for _, day := range candidates {
+				selected[day] = true
+			}
+		case w.N > 0 && w.N <= len(candidates):
+			selected[candidates[w.N-1]] = true
+		case w.N < 0 && -w.N <= len(candidates):
+			selected[candidates[len(candidates)+w.N]] = true
+		}
+	}
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 && defaultDay <= length {
+		selected[defaultDay] = true
+	}
+
+	days := make([]int, 0, len(selected))
+	for day := range selected {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	return days
+}
+
+// nextYearly repeats the month and day of from every INTERVAL-th year, skipping years in which
+// that day does not exist.
+func (r *Recurrence) nextYearly(from time.Time) (time.Time, bool) {
+	for i := 1; i <= maxSearch; i++ {
+		next := onDay(from, from.Year()+i*r.interval(), from.Month(), from.Day())
+		if next.Day() == from.Day() {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// String returns the rule in RRULE syntax, without its "RRULE:" prefix.
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.TZID != "" {
+		parts = append(parts, "TZID="+r.TZID)
+	}
+	return strings.Join(parts, ";")
+}
+
+// MarshalText encodes the recurrence as its RRULE.
+func (r Recurrence) MarshalText() ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid recurrence: %w", err)
+	}
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText decodes a recurrence from its RRULE.
+func (r *Recurrence) UnmarshalText(text []byte) error {
+	parsed, err := ParseRecurrence(string(text))
+	if err != nil {
+		return err
+	}
+	*r = *parsed
+	return nil
+}
+
+func recurrenceError(message string) error {
+	return NewValidationError("recurrence", message)
+}
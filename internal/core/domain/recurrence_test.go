@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "should parse a rule with a prefix and lower case values",
+			input:    "RRULE:FREQ=weekly;INTERVAL=2;BYDAY=mo,th",
+			expected: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+		},
+		{
+			name:     "should parse numbered weekdays in monthly rules",
+			input:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=12;TZID=Europe/London",
+			expected: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=12;TZID=Europe/London",
+		},
+		{
+			name:     "should parse an until date as the end of that day",
+			input:    "FREQ=DAILY;UNTIL=20211231",
+			expected: "FREQ=DAILY;UNTIL=20211231T235959Z",
+		},
+		{
+			name:  "should return error when frequency is unknown",
+			input: "FREQ=HOURLY",
+			err:   true,
+		},
+		{
+			name:  "should return error when count and until are combined",
+			input: "FREQ=DAILY;COUNT=3;UNTIL=20211231",
+			err:   true,
+		},
+		{
+			name:  "should return error when numbered weekdays are used in weekly rules",
+			input: "FREQ=WEEKLY;BYDAY=2MO",
+			err:   true,
+		},
+		{
+			name:  "should return error when time zone is unknown",
+			input: "FREQ=DAILY;TZID=Mars/Olympus_Mons",
+			err:   true,
+		},
+		{
+			name:  "should return error when rule part is unsupported",
+			input: "FREQ=DAILY;BYHOUR=9",
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := ParseRecurrence(test.input)
+			if test.err {
+				assert.ErrorIs(t, err, ErrValidation)
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualValues(t, test.expected, r.String())
+		})
+	}
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		rule       string
+		from       time.Time
+		occurrence int
+		expected   []time.Time
+		ends       bool
+	}{
+		{
+			name: "should repeat every other day",
+			rule: "FREQ=DAILY;INTERVAL=2",
+			from: time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2021, 3, 3, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, 3, 5, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "should repeat on weekdays only",
+			rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			from: time.Date(2021, 3, 5, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2021, 3, 8, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, 3, 9, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "should repeat on given weekdays every other week",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			from: time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2021, 3, 4, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, 3, 15, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, 3, 18, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "should keep the wall clock time across daylight saving changes",
+			rule: "FREQ=WEEKLY;TZID=Europe/London",
+			from: time.Date(2021, 3, 22, 9, 0, 0, 0, london),
+			expected: []time.Time{
+				time.Date(2021, 3, 29, 8, 0, 0, 0, time.UTC),
+				time.Date(2021, 4, 5, 8, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "should skip months without the day of the first occurrence",
+			rule: "FREQ=MONTHLY",
+			from: time.Date(2021, 1, 31, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2021, 3, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, 5, 31, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "should repeat on the last day of every month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			from: time.Date(2021, 1, 31, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2021, 2, 28, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, 3, 31, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "should repeat on the last friday of every month",
+			rule: "FREQ=MONTHLY;BYDAY=-1FR",
+			from: time.Date(2021, 1, 29, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2021, 2, 26, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, 3, 26, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "should skip years without the leap day",
+			rule: "FREQ=YEARLY",
+			from: time.Date(2020, 2, 29, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "should stop after the given number of occurrences",
+			rule:       "FREQ=DAILY;COUNT=3",
+			from:       time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC),
+			occurrence: 1,
+			expected: []time.Time{
+				time.Date(2021, 3, 2, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, 3, 3, 9, 0, 0, 0, time.UTC),
+			},
+			ends: true,
+		},
+		{
+			name: "should stop after the until time",
+			rule: "FREQ=DAILY;UNTIL=20210302",
+			from: time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2021, 3, 2, 9, 0, 0, 0, time.UTC),
+			},
+			ends: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := ParseRecurrence(test.rule)
+			require.NoError(t, err)
+
+			from, occurrence := test.from, test.occurrence
+			for _, expected := range test.expected {
+				next, ok := r.Next(from, occurrence)
+				require.True(t, ok)
+				assert.EqualValues(t, expected, next.UTC())
+				from, occurrence = next, occurrence+1
+			}
+
+			_, ok := r.Next(from, occurrence)
+			assert.Equal(t, !test.ends, ok)
+		})
+	}
+}
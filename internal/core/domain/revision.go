@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RevisionAction names the kind of change a revision records.
+type RevisionAction string
+
+const (
+	RevisionCreated  RevisionAction = "created"
+	RevisionUpdated  RevisionAction = "updated"
+	RevisionDeleted  RevisionAction = "deleted"
+	RevisionRestored RevisionAction = "restored"
+)
+
+// Revision records a change made to an entry by the user with ActorID. Revisions are numbered
+// from 1 for each entry, and never change once recorded. Entry holds the entry as it was after
+// the change, and is nil for deletions.
+type Revision struct {
+	EntryID string         `json:"entry_id"`
+	Number  int            `json:"number"`
+	Action  RevisionAction `json:"action"`
+	ActorID string         `json:"actor_id"`
+	At      time.Time      `json:"at"`
+	Changes []FieldChange  `json:"changes"`
+	Entry   *Entry         `json:"entry,omitempty"`
+}
+
+// FieldChange is the change of a single field of an entry, named as in its JSON encoding, from
+// one JSON value to another. From is left out when the entry was created, and To when it was
+// deleted.
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from,omitempty"`
+	To    json.RawMessage `json:"to,omitempty"`
+}
+
+// bookkeepingFields are the fields of an entry maintained along with every change, which are
+// left out of diffs.
+var bookkeepingFields = map[string]bool{"id": true, "version": true, "created_at": true, "updated_at": true}
+
+// entryFields lists the JSON names of the fields of Entry, in order.
+var entryFields = func() []string {
+	typ := reflect.TypeOf(Entry{})
+	fields := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}()
+
+// Diff returns the changes made to the fields of an entry going from before to after, in the
+// order of the fields of Entry. A nil before stands for an entry being created, and a nil after
+// for an entry being deleted; their fields are compared to those of an empty entry. The fields
+// maintained with every change, id, version, created_at and updated_at, are left out.
+func Diff(before, after *Entry) ([]FieldChange, error) {
+	from, err := entryValues(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := entryValues(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	for _, field := range entryFields {
+		if bookkeepingFields[field] || bytes.Equal(from[field], to[field]) {
+			continue
+		}
+		change := FieldChange{Field: field}
+		if before != nil {
+			change.From = from[field]
+		}
+		if after != nil {
+			change.To = to[field]
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// entryValues returns the JSON encoding of every field of entry, or of an empty entry when it is
+// nil. Fields left out of the encoding of the entry are null.
+func entryValues(entry *Entry) (map[string]json.RawMessage, error) {
+	if entry == nil {
+		entry = &Entry{}
+	}
+
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return nil, Internal("encoding entry failed", err)
+	}
+	values := map[string]json.RawMessage{}
+	if err := json.Unmarshal(encoded, &values); err != nil {
+		return nil, Internal("decoding entry failed", err)
+	}
+	for _, field := range entryFields {
+		if _, ok := values[field]; !ok {
+			values[field] = json.RawMessage("null")
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	created := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2021, 3, 5, 17, 0, 0, 0, time.UTC)
+	entry := &Entry{ID: "release", Version: 1, OwnerID: "alice", Title: "Release", Tags: []string{"#work"}, CreatedAt: created, UpdatedAt: created}
+
+	raw := func(s string) json.RawMessage { return json.RawMessage(s) }
+
+	tests := []struct {
+		name     string
+		before   *Entry
+		after    func() *Entry
+		expected []FieldChange
+	}{
+		{
+			name:   "should list the fields set on a created entry",
+			before: nil,
+			after:  func() *Entry { return entry },
+			expected: []FieldChange{
+				{Field: "owner_id", To: raw(`"alice"`)},
+				{Field: "title", To: raw(`"Release"`)},
+				{Field: "tags", To: raw(`["#work"]`)},
+			},
+		},
+		{
+			name:   "should list the changed fields of an updated entry only",
+			before: entry,
+			after: func() *Entry {
+				updated := *entry
+				updated.Version, updated.UpdatedAt = 2, created.Add(time.Hour)
+				updated.Title, updated.Priority, updated.DueAt, updated.Tags = "Ship release", PriorityHigh, &due, nil
+				return &updated
+			},
+			expected: []FieldChange{
+				{Field: "title", From: raw(`"Release"`), To: raw(`"Ship release"`)},
+				{Field: "priority", From: raw(`"none"`), To: raw(`"high"`)},
+				{Field: "tags", From: raw(`["#work"]`), To: raw(`null`)},
+				{Field: "due_at", From: raw(`null`), To: raw(`"2021-03-05T17:00:00Z"`)},
+			},
+		},
+		{
+			name:     "should return no changes when only bookkeeping fields change",
+			before:   entry,
+			after:    func() *Entry { updated := *entry; updated.Version = 5; return &updated },
+			expected: []FieldChange{},
+		},
+		{
+			name:   "should list the fields of a deleted entry",
+			before: entry,
+			after:  func() *Entry { return nil },
+			expected: []FieldChange{
+				{Field: "owner_id", From: raw(`"alice"`)},
+				{Field: "title", From: raw(`"Release"`)},
+				{Field: "tags", From: raw(`["#work"]`)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := Diff(test.before, test.after())
+			require.NoError(t, err)
+			assert.EqualValues(t, test.expected, changes)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// SearchQuery describes a full-text search of entries: the words to look for in their titles
+// and descriptions, and how many results to return at most.
+type SearchQuery struct {
+	Text  string
+	Limit int
+}
+
+// SearchHit is an entry found by a search, with the relevance of the entry to the search.
+type SearchHit struct {
+	Entry *Entry
+	Score float64
+}
+
+// SearchResult is an entry found by a search together with its title and an excerpt of its
+// description, both HTML escaped and with the matching words wrapped in <mark> elements. The
+// snippet is empty when the description holds none of the words searched for.
+type SearchResult struct {
+	Entry   *Entry  `json:"entry"`
+	Score   float64 `json:"score"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+// snippetWords is the number of words of the description included in a snippet.
+const snippetWords = 24
+
+// NewSearchResult returns the result of a search for text that found the given entry.
+func NewSearchResult(hit SearchHit, text string) *SearchResult {
+	query := parseTextQuery(text)
+	return &SearchResult{
+		Entry:   hit.Entry,
+		Score:   hit.Score,
+		Title:   highlight(hit.Entry.Title, query, 0),
+		Snippet: highlight(hit.Entry.Description, query, snippetWords),
+	}
+}
+
+// TextMatch is the ID of an entry matched by a TextIndex and its relevance.
+type TextMatch struct {
+	ID    string
+	Score float64
+}
+
+// TextPosting counts the occurrences of an indexed term in the title and the description of the
+// entry with the given ID.
+type TextPosting struct {
+	ID, Term           string
+	Title, Description int
+}
+
+// TextIndex is an inverted index from the stemmed words of the titles and descriptions of
+// entries to the entries containing them. It is not safe for concurrent use.
+type TextIndex struct {
+	postings map[string]map[string]*TextPosting
+	terms    map[string][]string
+	lengths  map[string]int
+	total    int
+}
+
+const (
+	// titleWeight is how many occurrences in the description an occurrence in the title is worth.
+	titleWeight = 3
+	// prefixWeight scales the relevance of words that only start with a searched word.
+	prefixWeight = 0.5
+	// bm25K1 and bm25B are the term frequency saturation and length normalisation of Okapi BM25.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// NewTextIndex returns a pointer to an empty text index.
+func NewTextIndex() *TextIndex {
+	return &TextIndex{
+		postings: map[string]map[string]*TextPosting{},
+		terms:    map[string][]string{},
+		lengths:  map[string]int{},
+	}
+}
+
+// TextTerms returns the postings of the terms indexed for the entry, ordered by term, and the
+// length of the entry, in which every word of the title counts several times. Entries without
+// indexed terms have a length of zero.
+func TextTerms(entry *Entry) ([]TextPosting, int) {
+	frequencies := map[string]*TextPosting{}
+	count := func(text string, field func(p *TextPosting) *int) int {
+		words := terms(text)
+		for _, term := range words {
+			if frequencies[term] == nil {
+				frequencies[term] = &TextPosting{ID: entry.ID, Term: term}
+			}
+			*field(frequencies[term])++
+		}
+		return len(words)
+	}
+	length := titleWeight*count(entry.Title, func(p *TextPosting) *int { return &p.Title }) +
+		count(entry.Description, func(p *TextPosting) *int { return &p.Description })
+	if len(frequencies) == 0 {
+		return nil, 0
+	}
+
+	postings := make([]TextPosting, 0, len(frequencies))
+	for _, p := range frequencies {
+		postings = append(postings, *p)
+	}
+	sort.Slice(postings, func(i, j int) bool { return postings[i].Term < postings[j].Term })
+	return postings, length
+}
+
+// TextPrefixes returns the prefixes of the indexed terms a search for text may match: it matches
+// no term starting with none of them.
+func TextPrefixes(text string) []string {
+	var prefixes []string
+	for _, qt := range parseTextQuery(text).terms {
+		prefixes = append(prefixes, qt.word)
+		if qt.stem != qt.word {
+			prefixes = append(prefixes, qt.stem)
+		}
+	}
+	return prefixes
+}
+
+// Add indexes the title and description of the entry, replacing what was indexed for it before.
+func (idx *TextIndex) Add(entry *Entry) {
+	idx.Remove(entry.ID)
+
+	postings, length := TextTerms(entry)
+	for i := range postings {
+		p := &postings[i]
+		if idx.postings[p.Term] == nil {
+			idx.postings[p.Term] = map[string]*TextPosting{}
+		}
+		idx.postings[p.Term][entry.ID] = p
+		idx.terms[entry.ID] = append(idx.terms[entry.ID], p.Term)
+	}
+	if length > 0 {
+		idx.lengths[entry.ID] = length
+		idx.total += length
+	}
+}
+
+// Remove drops the entry with the given ID from the index.
+func (idx *TextIndex) Remove(id string) {
+	for _, term := range idx.terms[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.total -= idx.lengths[id]
+	delete(idx.terms, id)
+	delete(idx.lengths, id)
+}
+
+// Search returns the entries containing every word of text, as TextCorpus.Search does.
+func (idx *TextIndex) Search(text string) []TextMatch {
+	corpus := &TextCorpus{Entries: len(idx.lengths), Length: idx.total, Lengths: idx.lengths}
+	prefixes := TextPrefixes(text)
+	for term, postings := range idx.postings {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(term, prefix) {
+				for _, p := range postings {
+					corpus.Postings = append(corpus.Postings, *p)
+				}
+				break
+			}
+		}
+	}
+	return corpus.Search(text)
+}
+
+// TextCorpus is what ranking the entries matching a search takes from a text index: the number
+// of entries indexed and the sum of their lengths, every posting of the terms starting with one
+// of the TextPrefixes of the text searched for, and the lengths of the entries they belong to.
+type TextCorpus struct {
+	Entries, Length int
+	Postings        []TextPosting
+	Lengths         map[string]int
+}
+
+// Search returns the entries containing every word of text, ordered by descending relevance
+// and then by ID. Words match their inflections, as well as longer words starting with them,
+// which count for less. Relevance is ranked with Okapi BM25, counting words in the title
+// several times.
+func (c *TextCorpus) Search(text string) []TextMatch {
+	query := parseTextQuery(text)
+	if len(query.terms) == 0 || c.Entries == 0 {
+		return []TextMatch{}
+	}
+
+	documents := map[string]int{}
+	for _, p := range c.Postings {
+		documents[p.Term]++
+	}
+
+	var scores map[string]float64
+	for _, qt := range query.terms {
+		termScores := c.score(qt, documents)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	matches := make([]TextMatch, 0, len(scores))
+	for id, score := range scores {
+		matches = append(matches, TextMatch{ID: id, Score: score})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// score returns the relevance of the query term to every entry it matches, taking the best of
+// the indexed terms it matches in each entry. documents counts the entries containing each term.
+func (c *TextCorpus) score(qt queryTerm, documents map[string]int) map[string]float64 {
+	docs := float64(c.Entries)
+	average := float64(c.Length) / docs
+
+	scores := map[string]float64{}
+	for _, p := range c.Postings {
+		weight := qt.weight(p.Term)
+		if weight == 0 {
+			continue
+		}
+
+		n := float64(documents[p.Term])
+		idf := math.Log(1 + (docs-n+0.5)/(n+0.5))
+		tf := float64(titleWeight*p.Title + p.Description)
+		norm := 1 - bm25B + bm25B*float64(c.Lengths[p.ID])/average
+		score := weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		if score > scores[p.ID] {
+			scores[p.ID] = score
+		}
+	}
+	return scores
+}
+
+// textQuery is the parsed text of a search.
+type textQuery struct {
+	terms []queryTerm
+}
+
+// queryTerm is a word searched for, lower cased, along with its stem.
+type queryTerm struct {
+	word, stem string
+}
+
+func parseTextQuery(text string) *textQuery {
+	query := &textQuery{}
+	seen := map[string]bool{}
+	for _, t := range tokenize(text) {
+		if stopWords[t.word] || seen[t.word] {
+			continue
+		}
+		seen[t.word] = true
+		query.terms = append(query.terms, queryTerm{word: t.word, stem: stem(t.word)})
+	}
+	return query
+}
+
+// weight returns how much the indexed term counts towards the query term: fully when they share
+// their stem, partly when the term starts with the query term and not at all otherwise.
+func (qt queryTerm) weight(term string) float64 {
+	switch {
+	case term == qt.stem:
+		return 1
+	case strings.HasPrefix(term, qt.word), strings.HasPrefix(term, qt.stem):
+		return prefixWeight
+	}
+	return 0
+}
+
+// matches reports whether the given lower cased word matches any of the query terms.
+func (q *textQuery) matches(word string) bool {
+	s := stem(word)
+	for _, qt := range q.terms {
+		if qt.weight(s) > 0 || strings.HasPrefix(word, qt.word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTextIndex_Search(t *testing.T) {
+	idx := NewTextIndex()
+	for _, entry := range []*Entry{
+		{ID: "1", Title: "Deploy the website", Description: "Run the release pipeline"},
+		{ID: "2", Title: "Write release notes", Description: "Summarise what was deployed this week"},
+		{ID: "3", Title: "Buy groceries", Description: "Milk, eggs and bread"},
+		{ID: "4", Title: "Plan the deployment", Description: "Decide when to deploy"},
+	} {
+		idx.Add(entry)
+	}
+
+	ids := func(matches []TextMatch) []string {
+		ids := []string{}
+		for _, match := range matches {
+			ids = append(ids, match.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "should rank entries with the word in their title first",
+			text:     "deploying",
+			expected: []string{"1", "4", "2"},
+		},
+		{
+			name:     "should only return entries containing every word",
+			text:     "release deployed",
+			expected: []string{"2", "1"},
+		},
+		{
+			name:     "should match words starting with the searched word",
+			text:     "groc",
+			expected: []string{"3"},
+		},
+		{
+			name:     "should ignore case and punctuation",
+			text:     "MILK, Bread!",
+			expected: []string{"3"},
+		},
+		{
+			name:     "should return nothing when searching for stop words only",
+			text:     "the and",
+			expected: []string{},
+		},
+		{
+			name:     "should return nothing when no entry matches",
+			text:     "holiday",
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualValues(t, test.expected, ids(idx.Search(test.text)))
+		})
+	}
+}
+
+func TestTextIndex_Remove(t *testing.T) {
+	idx := NewTextIndex()
+	idx.Add(&Entry{ID: "1", Title: "Water the plants"})
+	idx.Add(&Entry{ID: "2", Title: "Water the garden"})
+
+	idx.Add(&Entry{ID: "1", Title: "Feed the cat"})
+	idx.Remove("2")
+
+	assert.Empty(t, idx.Search("water"))
+	assert.Len(t, idx.Search("cat"), 1)
+	assert.Empty(t, idx.postings["water"])
+	assert.Zero(t, idx.total-idx.lengths["1"])
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxTagLength is the largest number of characters a normalised tag may have.
+const MaxTagLength = 50
+
+// NormalizeTag returns the canonical form of a tag: lower case, with surrounding whitespace
+// removed and inner runs of whitespace replaced by a single dash. A tag may start with one
+// '@' (a context, like @home) or '#' (a topic, like #release-2.3) and otherwise consist of
+// letters, digits and the characters '-', '_', '.' and '/'.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+
+	body := strings.TrimLeft(tag, "@#")
+	if len(tag)-len(body) > 1 {
+		return "", NewValidationError("tags", "tag "+tag+" may start with at most one @ or #")
+	}
+	if body == "" {
+		return "", NewValidationError("tags", "tags cannot be empty")
+	}
+	for _, r := range body {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_./", r) {
+			return "", NewValidationError("tags", "tag "+tag+" contains invalid character "+string(r))
+		}
+	}
+	if len([]rune(tag)) > MaxTagLength {
+		return "", NewValidationError("tags", "tag "+tag+" is longer than 50 characters")
+	}
+
+	return tag, nil
+}
@@ -0,0 +1,321 @@
+package domain
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// token is a word of a text, lower cased, together with its byte offsets in the text.
+type token struct {
+	word       string
+	start, end int
+}
+
+// stopWords are common English words that carry no meaning on their own and are neither indexed
+// nor searched for.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"that": true, "the": true, "this": true, "to": true, "was": true, "with": true,
+}
+
+// tokenize splits text into its words: runs of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// terms returns the stemmed words of text that are not stop words, in order.
+func terms(text string) []string {
+	var terms []string
+	for _, t := range tokenize(text) {
+		if !stopWords[t.word] {
+			terms = append(terms, stem(t.word))
+		}
+	}
+	return terms
+}
+
+// stem reduces an English word to its stem using the Porter stemming algorithm, so that
+// inflections such as "deploy", "deploys" and "deployed" share the stem "deploi". Words that are
+// not made of lower case ASCII letters are returned unchanged.
+func stem(word string) string {
+	if len(word) <= 2 || strings.Trim(word, "abcdefghijklmnopqrstuvwxyz") != "" {
+		return word
+	}
+
+	s := &stemmer{b: []byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.replaceFirst(step2Rules)
+	s.replaceFirst(step3Rules)
+	s.step4()
+	s.step5()
+	return string(s.b)
+}
+
+type stemmer struct {
+	b []byte
+}
+
+type suffixRule struct {
+	suffix, replacement string
+}
+
+var step2Rules = []suffixRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"}, {"izer", "ize"},
+	{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"},
+	{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"},
+	{"fulness", "ful"}, {"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+}
+
+var step3Rules = []suffixRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"}, {"ical", "ic"}, {"ful", ""},
+	{"ness", ""},
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent", "ion", "ou",
+	"ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+// consonant reports whether the letter at i is a consonant: any letter but a vowel, and y when
+// it follows a vowel or starts the word.
+func (s *stemmer) consonant(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.consonant(i-1)
+	}
+	return true
+}
+
+// measureOf returns the number of vowel-consonant sequences in the first n letters.
+func (s *stemmer) measureOf(n int) int {
+	m, i := 0, 0
+	for i < n && s.consonant(i) {
+		i++
+	}
+	for i < n {
+		for i < n && !s.consonant(i) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		for i < n && s.consonant(i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func (s *stemmer) measure() int {
+	return s.measureOf(len(s.b))
+}
+
+// hasVowel reports whether the first n letters contain a vowel.
+func (s *stemmer) hasVowel(n int) bool {
+	for i := 0; i < n; i++ {
+		if !s.consonant(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleConsonant reports whether the first n letters end with a double consonant.
+func (s *stemmer) doubleConsonant(n int) bool {
+	return n >= 2 && s.b[n-1] == s.b[n-2] && s.consonant(n-1)
+}
+
+// cvc reports whether the first n letters end consonant-vowel-consonant, where the final
+// consonant is not w, x or y.
+func (s *stemmer) cvc(n int) bool {
+	if n < 3 || !s.consonant(n-1) || s.consonant(n-2) || !s.consonant(n-3) {
+		return false
+	}
+	switch s.b[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func (s *stemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// base returns the number of letters before suffix.
+func (s *stemmer) base(suffix string) int {
+	return len(s.b) - len(suffix)
+}
+
+func (s *stemmer) replace(suffix, replacement string) {
+	s.b = append(s.b[:s.base(suffix)], replacement...)
+}
+
+func (s *stemmer) step1a() {
+	switch {
+	case s.hasSuffix("sses"):
+		s.replace("sses", "ss")
+	case s.hasSuffix("ies"):
+		s.replace("ies", "i")
+	case s.hasSuffix("ss"):
+	case s.hasSuffix("s"):
+		s.replace("s", "")
+	}
+}
+
+func (s *stemmer) step1b() {
+	if s.hasSuffix("eed") {
+		if s.measureOf(s.base("eed")) > 0 {
+			s.replace("eed", "ee")
+		}
+		return
+	}
+
+	removed := false
+	for _, suffix := range []string{"ed", "ing"} {
+		if s.hasSuffix(suffix) && s.hasVowel(s.base(suffix)) {
+			s.replace(suffix, "")
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		return
+	}
+
+	n := len(s.b)
+	switch {
+	case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+		s.b = append(s.b, 'e')
+	case s.doubleConsonant(n) && s.b[n-1] != 'l' && s.b[n-1] != 's' && s.b[n-1] != 'z':
+		s.b = s.b[:n-1]
+	case s.measure() == 1 && s.cvc(n):
+		s.b = append(s.b, 'e')
+	}
+}
+
+func (s *stemmer) step1c() {
+	if s.hasSuffix("y") && s.hasVowel(s.base("y")) {
+		s.replace("y", "i")
+	}
+}
+
+// replaceFirst applies the first rule whose suffix the word ends with, provided the rest of
+// the word has a measure above zero.
+func (s *stemmer) replaceFirst(rules []suffixRule) {
+	for _, rule := range rules {
+		if s.hasSuffix(rule.suffix) {
+			if s.measureOf(s.base(rule.suffix)) > 0 {
+				s.replace(rule.suffix, rule.replacement)
+			}
+			return
+		}
+	}
+}
+
+func (s *stemmer) step4() {
+	for _, suffix := range step4Suffixes {
+		if !s.hasSuffix(suffix) {
+			continue
+		}
+		n := s.base(suffix)
+		if suffix == "ion" && (n == 0 || (s.b[n-1] != 's' && s.b[n-1] != 't')) {
+			return
+		}
+		if s.measureOf(n) > 1 {
+			s.replace(suffix, "")
+		}
+		return
+	}
+}
+
+func (s *stemmer) step5() {
+	if s.hasSuffix("e") {
+		n := s.base("e")
+		if m := s.measureOf(n); m > 1 || (m == 1 && !s.cvc(n)) {
+			s.b = s.b[:n]
+		}
+	}
+
+	n := len(s.b)
+	if s.b[n-1] == 'l' && s.doubleConsonant(n) && s.measure() > 1 {
+		s.b = s.b[:n-1]
+	}
+}
+
+// highlight returns text, HTML escaped, with the words matched by the query wrapped in <mark>
+// elements. When context is above zero, only an excerpt of about that many words around the
+// first match is returned, or nothing when no word matches.
+func highlight(text string, query *textQuery, context int) string {
+	tokens := tokenize(text)
+	first := -1
+	matched := make([]bool, len(tokens))
+	for i, t := range tokens {
+		if !stopWords[t.word] && query.matches(t.word) {
+			matched[i] = true
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+
+	from, to := 0, len(text)
+	if context > 0 {
+		if first < 0 {
+			return ""
+		}
+		start := first - context/3
+		if start < 0 {
+			start = 0
+		}
+		end := start + context
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		from, to = tokens[start].start, tokens[end-1].end
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for i, t := range tokens {
+		if !matched[i] || t.start < from || t.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:t.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[t.start:t.end]))
+		b.WriteString("</mark>")
+		pos = t.end
+	}
+	b.WriteString(html.EscapeString(text[pos:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"hopping":        "hop",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"generalization": "gener",
+		"connection":     "connect",
+		"connected":      "connect",
+		"deploys":        "deploi",
+		"deployed":       "deploi",
+		"controll":       "control",
+		"go":             "go",
+		"v2":             "v2",
+		"café":           "café",
+	}
+
+	for word, expected := range tests {
+		t.Run(word, func(t *testing.T) {
+			assert.EqualValues(t, expected, stem(word))
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		query    string
+		context  int
+		expected string
+	}{
+		{
+			name:     "should mark inflections and prefixes of the searched words",
+			text:     "Deployed the connections",
+			query:    "deploy connect",
+			expected: "<mark>Deployed</mark> the <mark>connections</mark>",
+		},
+		{
+			name:     "should escape the text around and within marks",
+			text:     "<b>Fix</b> & fixing",
+			query:    "fix",
+			expected: "&lt;b&gt;<mark>Fix</mark>&lt;/b&gt; &amp; <mark>fixing</mark>",
+		},
+		{
+			name:     "should not mark stop words",
+			text:     "the theme",
+			query:    "the",
+			expected: "the theme",
+		},
+		{
+			name:     "should excerpt the words around the first match",
+			text:     "one two three four five six seven eight nine ten",
+			query:    "six",
+			context:  4,
+			expected: "…five <mark>six</mark> seven eight…",
+		},
+		{
+			name:    "should return nothing when excerpting text without matches",
+			text:    "one two three",
+			query:   "four",
+			context: 4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualValues(t, test.expected, highlight(test.text, parseTextQuery(test.query), test.context))
+		})
+	}
+}
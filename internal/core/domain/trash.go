@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// TrashedEntry is an entry moved to the trash by the user with DeletedBy, together with the
+// subtasks deleted along with it, parents before their subtasks. It is known by the ID of its
+// entry, and can be restored until it is purged.
+type TrashedEntry struct {
+	*Entry
+	Subtasks  []*Entry  `json:"subtasks,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	uuid2 "github.com/google/uuid"
+	"time"
+)
+
+// User is an account owning entries and lists. Users authenticate with their password or with
+// one of their API tokens.
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UserInput holds the values chosen when registering a user.
+type UserInput struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Token is an API token of a user. Only a hash of the secret is stored; the secret itself is
+// shown once, when the token is created.
+type Token struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Identity describes the authenticated user a request is made on behalf of.
+type Identity struct {
+	UserID   string
+	Username string
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the given identity.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom returns the identity carried by ctx, if any.
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok && identity.UserID != ""
+}
+
+// UserID returns the ID of the user ctx acts for, or an unauthenticated error when it acts for
+// none.
+func UserID(ctx context.Context) (string, error) {
+	identity, ok := IdentityFrom(ctx)
+	if !ok {
+		return "", Unauthenticated("request is not made on behalf of a user")
+	}
+	return identity.UserID, nil
+}
+
+// NewUser returns a pointer to a new User object.
+func NewUser(username string, passwordHash []byte) *User {
+	return &User{
+		ID:           uuid2.NewString(),
+		Username:     username,
+		PasswordHash: passwordHash,
+	}
+}
+
+// NewToken returns a pointer to a new Token object of the user with userID, whose secret hashes
+// to the given hash.
+func NewToken(userID, name, hash string) *Token {
+	return &Token{
+		ID:     uuid2.NewString(),
+		UserID: userID,
+		Name:   name,
+		Hash:   hash,
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	uuid2 "github.com/google/uuid"
+	"time"
+)
+
+// View is a filter expression saved under a name by a user, also known as a smart list. Its
+// entries are the entries of the user matching the expression at the time they are listed; see
+// Filter for the syntax of the expression.
+type View struct {
+	ID        string    `json:"id"`
+	OwnerID   string    `json:"owner_id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ViewInput holds the values a user can choose when saving a view.
+type ViewInput struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// NewView returns a pointer to a new View object.
+func NewView(name, query string) *View {
+	return &View{
+		ID:    uuid2.NewString(),
+		Name:  name,
+		Query: query,
+	}
+}
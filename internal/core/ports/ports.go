@@ -1,21 +1,194 @@
 package ports
 
-import "github.com/Nikym/go-todo/internal/core/domain"
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"time"
+)
 
 // EntryRepository is the interface for the repository port handling the
 // retrieval and storage of to-do entries.
+//
+// Update is a compare-and-swap: it only replaces the stored entry while the stored version equals
+// the version of the given entry, and fails with domain.ErrPreconditionFailed otherwise. On
+// success, the version of the given entry is incremented to match the stored one.
 type EntryRepository interface {
 	Get(id string) (*domain.Entry, error)
 	Save(entry *domain.Entry) error
 	Delete(id string) error
 	Update(id string, entry *domain.Entry) error
+	List(query domain.ListQuery) (*domain.EntryPage, error)
+	Tags(query domain.ListQuery) ([]domain.TagCount, error)
+}
+
+// AtomicEntryRepository is implemented by entry repositories able to apply several writes as
+// a single all-or-nothing transaction. Every write made through the repository passed to fn is
+// discarded if fn returns an error.
+type AtomicEntryRepository interface {
+	EntryRepository
+	Atomic(fn func(repo EntryRepository) error) error
+}
+
+// SearchableEntryRepository is implemented by entry repositories maintaining a full-text index
+// of their entries. Search returns every entry whose title or description contains all the words
+// of text, ordered by descending relevance.
+type SearchableEntryRepository interface {
+	EntryRepository
+	Search(text string) ([]domain.SearchHit, error)
 }
 
 // EntryService is the interface for the driver port handling the
-// interactions with entries (domain.Entry)
+// interactions with entries (domain.Entry). Every method acts on behalf of the user whose
+// identity is carried by the context, and only sees the entries that user owns or that are
+// shared with them, except for PurgeTrash, which empties the trash of every user of the entries
+// kept in it for longer than the given age. Undo and Redo step back and forth through the recent
+// creations, updates and deletions made by that user, as recorded by Create, Update, Delete and
+// EmptyList, which takes the entries out of a list being deleted as asked by its options.
 type EntryService interface {
-	Get(id string) (*domain.Entry, error)
-	Create(title, description string) (*domain.Entry, error)
-	Update(id string, entry *domain.Entry) error
+	Get(ctx context.Context, id string) (*domain.Entry, error)
+	Create(ctx context.Context, input domain.EntryInput) (*domain.Entry, error)
+	Parse(ctx context.Context, input domain.EntryInput) (*domain.EntryInput, error)
+	Update(ctx context.Context, id string, entry *domain.Entry) error
+	Delete(ctx context.Context, id string, opts domain.DeleteOptions) error
+	EmptyList(ctx context.Context, listID string, opts domain.ListDeleteOptions) error
+	List(ctx context.Context, query domain.ListQuery) (*domain.EntryPage, error)
+	Children(ctx context.Context, id string) ([]*domain.Entry, error)
+	Tree(ctx context.Context, id string) (*domain.EntryNode, error)
+	AddTags(ctx context.Context, id string, tags []string) (*domain.Entry, error)
+	RemoveTags(ctx context.Context, id string, tags []string) (*domain.Entry, error)
+	Tags(ctx context.Context, query domain.ListQuery) ([]domain.TagCount, error)
+	Search(ctx context.Context, query domain.SearchQuery) ([]*domain.SearchResult, error)
+	Trash(ctx context.Context) ([]*domain.TrashedEntry, error)
+	Restore(ctx context.Context, id string) (*domain.Entry, error)
+	Purge(ctx context.Context, id string) error
+	EmptyTrash(ctx context.Context) error
+	PurgeTrash(ctx context.Context, olderThan time.Duration) (int, error)
+	History(ctx context.Context, id string) ([]*domain.Revision, error)
+	Revert(ctx context.Context, id string, number int, version int64) (*domain.Entry, error)
+	Undo(ctx context.Context) (*domain.Operation, error)
+	Redo(ctx context.Context) (*domain.Operation, error)
+}
+
+// TrashRepository is the interface for the repository port handling the retrieval and storage
+// of the entries moved to the trash (domain.TrashedEntry), keyed by the ID of their entry.
+type TrashRepository interface {
+	Get(id string) (*domain.TrashedEntry, error)
+	Save(entry *domain.TrashedEntry) error
+	Delete(id string) error
+	List() ([]*domain.TrashedEntry, error)
+}
+
+// RevisionRepository is the interface for the repository port handling the storage of the
+// revisions (domain.Revision) making up the history of entries. Append stores a revision as the
+// next one of its entry, setting its number; revisions are never changed once appended.
+type RevisionRepository interface {
+	Append(revision *domain.Revision) error
+	Get(entryID string, number int) (*domain.Revision, error)
+	List(entryID string) ([]*domain.Revision, error)
+}
+
+// EventHandler handles an event (domain.Event) delivered by an event bus. The context is the one
+// the event was published with, which asynchronous subscribers receive without its cancellation.
+type EventHandler func(ctx context.Context, event *domain.Event)
+
+// EventBus is the interface for the port publishing the changes made to entries (domain.Event) to
+// subscribers within the process. Synchronous subscribers handle every event before Publish
+// returns, in the order they were published. Asynchronous subscribers handle them in that order
+// in the background, from a buffer of bounded size; a full buffer makes Publish wait or drop the
+// event, as configured by the subscription. Unsubscribing stops the deliveries to the subscriber
+// once it handled the events already buffered.
+type EventBus interface {
+	Publish(ctx context.Context, events ...*domain.Event)
+	Subscribe(handler EventHandler) (unsubscribe func())
+	SubscribeAsync(handler EventHandler, subscription domain.Subscription) (unsubscribe func())
+}
+
+// ListRepository is the interface for the repository port handling the
+// retrieval and storage of lists (domain.List).
+type ListRepository interface {
+	Get(id string) (*domain.List, error)
+	Save(list *domain.List) error
 	Delete(id string) error
+	Update(id string, list *domain.List) error
+	List() ([]*domain.List, error)
+}
+
+// ListService is the interface for the driver port handling the
+// interactions with lists (domain.List) and the entries they own, on behalf of the user whose
+// identity is carried by the context.
+type ListService interface {
+	Get(ctx context.Context, id string) (*domain.List, error)
+	Create(ctx context.Context, input domain.ListInput) (*domain.List, error)
+	Update(ctx context.Context, id string, list *domain.List) error
+	Delete(ctx context.Context, id string, opts domain.ListDeleteOptions) error
+	List(ctx context.Context) ([]*domain.List, error)
+	Entries(ctx context.Context, id string, query domain.ListQuery) (*domain.EntryPage, error)
+	Move(ctx context.Context, entryID, listID string) (*domain.Entry, error)
+}
+
+// UserRepository is the interface for the repository port handling the
+// retrieval and storage of users (domain.User) and their API tokens (domain.Token).
+type UserRepository interface {
+	Get(id string) (*domain.User, error)
+	GetByUsername(username string) (*domain.User, error)
+	Save(user *domain.User) error
+	SaveToken(token *domain.Token) error
+	GetToken(hash string) (*domain.Token, error)
+	DeleteToken(id string) error
+	Tokens(userID string) ([]*domain.Token, error)
+}
+
+// UserService is the interface for the driver port handling user accounts and
+// the authentication of requests.
+type UserService interface {
+	Register(input domain.UserInput) (*domain.User, error)
+	Login(username, password string) (domain.Identity, error)
+	Authenticate(token string) (domain.Identity, error)
+	Me(ctx context.Context) (*domain.User, error)
+	CreateToken(ctx context.Context, name string) (*domain.Token, string, error)
+	Tokens(ctx context.Context) ([]*domain.Token, error)
+	RevokeToken(ctx context.Context, id string) error
+}
+
+// GrantRepository is the interface for the repository port handling the
+// retrieval and storage of the grants (domain.Grant) sharing resources with users. A user has
+// at most one grant per resource; saving another replaces it.
+type GrantRepository interface {
+	Get(kind domain.ResourceKind, resourceID, userID string) (*domain.Grant, error)
+	Save(grant *domain.Grant) error
+	Delete(kind domain.ResourceKind, resourceID, userID string) error
+	ForResource(kind domain.ResourceKind, resourceID string) ([]*domain.Grant, error)
+	ForUser(userID string) ([]*domain.Grant, error)
+}
+
+// AccessService is the interface for the driver port deciding which role the user whose
+// identity is carried by the context has on lists and entries, and sharing them with others.
+type AccessService interface {
+	EntryRole(ctx context.Context, entry *domain.Entry) (domain.Role, error)
+	ListRole(ctx context.Context, list *domain.List) (domain.Role, error)
+	Share(ctx context.Context, kind domain.ResourceKind, id string, input domain.GrantInput) (*domain.Grant, error)
+	Revoke(ctx context.Context, kind domain.ResourceKind, id, userID string) error
+	Collaborators(ctx context.Context, kind domain.ResourceKind, id string) ([]*domain.Grant, error)
+	Shared(ctx context.Context, kind domain.ResourceKind) ([]*domain.Grant, error)
+}
+
+// ViewRepository is the interface for the repository port handling the
+// retrieval and storage of views (domain.View).
+type ViewRepository interface {
+	Get(id string) (*domain.View, error)
+	Save(view *domain.View) error
+	Delete(id string) error
+	Update(id string, view *domain.View) error
+	List(ownerID string) ([]*domain.View, error)
+}
+
+// ViewService is the interface for the driver port handling the views (domain.View) of the user
+// whose identity is carried by the context. Views are named by their name, unique for each user.
+type ViewService interface {
+	Get(ctx context.Context, name string) (*domain.View, error)
+	Create(ctx context.Context, input domain.ViewInput) (*domain.View, error)
+	Update(ctx context.Context, name string, input domain.ViewInput) (*domain.View, error)
+	Delete(ctx context.Context, name string) error
+	List(ctx context.Context) ([]*domain.View, error)
+	Entries(ctx context.Context, name string, query domain.ListQuery) (*domain.EntryPage, error)
 }
@@ -0,0 +1,13 @@
+package accessSrv
+
+import "time"
+
+// Option configures optional behaviour of the access service.
+type Option func(srv *service)
+
+// WithClock makes the service read the current time from now instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(srv *service) {
+		srv.now = now
+	}
+}
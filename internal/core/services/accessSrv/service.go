@@ -0,0 +1,239 @@
+package accessSrv
+
+import (
+	"context"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"time"
+)
+
+type service struct {
+	grantRepository ports.GrantRepository
+	entryRepository ports.EntryRepository
+	listRepository  ports.ListRepository
+	userRepository  ports.UserRepository
+	now             domain.Clock
+}
+
+// New returns a pointer to a new access service object, deciding on access to the entries and
+// lists stored in the given repositories.
+func New(grants ports.GrantRepository, entries ports.EntryRepository, lists ports.ListRepository, users ports.UserRepository, opts ...Option) *service {
+	srv := &service{
+		grantRepository: grants,
+		entryRepository: entries,
+		listRepository:  lists,
+		userRepository:  users,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv
+}
+
+// EntryRole returns the role of the user the context acts for on the entry: owners of the entry
+// or of its list are owners, and everyone else has the highest role granted on the list, the
+// entry or any entry above it. Users without access have the empty role.
+func (srv *service) EntryRole(ctx context.Context, entry *domain.Entry) (domain.Role, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if entry.OwnerID == userID {
+		return domain.RoleOwner, nil
+	}
+
+	role := domain.Role("")
+	if entry.ListID != "" {
+		list, err := srv.listRepository.Get(entry.ListID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return "", domain.Categorise("retrieving list from repository failed", err)
+		}
+		if err == nil {
+			if role, err = srv.listRole(userID, list); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for current := entry; role != domain.RoleOwner; {
+		granted, err := srv.granted(domain.ResourceEntry, current.ID, userID)
+		if err != nil {
+			return "", err
+		}
+		role = role.Max(granted)
+
+		if current.ParentID == "" {
+			break
+		}
+		if current, err = srv.entryRepository.Get(current.ParentID); err != nil {
+			return "", domain.Categorise("retrieving parent entry from repository failed", err)
+		}
+	}
+	return role, nil
+}
+
+// ListRole returns the role of the user the context acts for on the list: its owner is an
+// owner, and everyone else has the role granted on the list, if any.
+func (srv *service) ListRole(ctx context.Context, list *domain.List) (domain.Role, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return srv.listRole(userID, list)
+}
+
+func (srv *service) listRole(userID string, list *domain.List) (domain.Role, error) {
+	if list.OwnerID == userID {
+		return domain.RoleOwner, nil
+	}
+	return srv.granted(domain.ResourceList, list.ID, userID)
+}
+
+// Share gives the user named in the input the chosen role on a resource, replacing any role
+// granted to them before. Only owners of the resource may share it.
+func (srv *service) Share(ctx context.Context, kind domain.ResourceKind, id string, input domain.GrantInput) (*domain.Grant, error) {
+	resourceOwner, err := srv.authorize(ctx, kind, id, domain.RoleOwner)
+	if err != nil {
+		return &domain.Grant{}, err
+	}
+	if !input.Role.Valid() {
+		return &domain.Grant{}, domain.NewValidationError("role", "must be one of viewer, editor or owner")
+	}
+
+	user, err := srv.userRepository.GetByUsername(input.Username)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return &domain.Grant{}, domain.NewValidationError("username", "user does not exist")
+		}
+		return &domain.Grant{}, domain.Categorise("retrieving user from repository failed", err)
+	}
+	if user.ID == resourceOwner {
+		return &domain.Grant{}, domain.NewValidationError("username", "user already owns the "+string(kind))
+	}
+
+	grant := &domain.Grant{
+		Kind:       kind,
+		ResourceID: id,
+		UserID:     user.ID,
+		Role:       input.Role,
+		CreatedAt:  srv.now.Timestamp(),
+	}
+	if err := srv.grantRepository.Save(grant); err != nil {
+		return &domain.Grant{}, domain.Categorise("saving grant to repository failed", err)
+	}
+
+	grant.Username = user.Username
+	return grant, nil
+}
+
+// Revoke takes the role granted on a resource away from the user with userID. Owners of the
+// resource may revoke anyone's role, and every user may give up their own.
+func (srv *service) Revoke(ctx context.Context, kind domain.ResourceKind, id, userID string) error {
+	required := domain.RoleOwner
+	if caller, err := domain.UserID(ctx); err == nil && caller == userID {
+		required = domain.RoleViewer
+	}
+	if _, err := srv.authorize(ctx, kind, id, required); err != nil {
+		return err
+	}
+
+	if err := srv.grantRepository.Delete(kind, id, userID); err != nil {
+		return domain.Categorise("deleting grant from repository failed", err)
+	}
+	return nil
+}
+
+// Collaborators returns every grant on a resource, naming the users they were given to.
+func (srv *service) Collaborators(ctx context.Context, kind domain.ResourceKind, id string) ([]*domain.Grant, error) {
+	if _, err := srv.authorize(ctx, kind, id, domain.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	grants, err := srv.grantRepository.ForResource(kind, id)
+	if err != nil {
+		return nil, domain.Categorise("listing grants from repository failed", err)
+	}
+	for _, grant := range grants {
+		user, err := srv.userRepository.Get(grant.UserID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.Categorise("retrieving user from repository failed", err)
+		}
+		grant.Username = user.Username
+	}
+	return grants, nil
+}
+
+// Shared returns every grant of a kind of resource given to the user the context acts for.
+func (srv *service) Shared(ctx context.Context, kind domain.ResourceKind) ([]*domain.Grant, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := srv.grantRepository.ForUser(userID)
+	if err != nil {
+		return nil, domain.Categorise("listing grants from repository failed", err)
+	}
+
+	shared := grants[:0]
+	for _, grant := range grants {
+		if grant.Kind == kind {
+			shared = append(shared, grant)
+		}
+	}
+	return shared, nil
+}
+
+// authorize checks that the user the context acts for has at least the required role on a
+// resource, returning the ID of the owner of the resource. Resources the user has no access to
+// are not found.
+func (srv *service) authorize(ctx context.Context, kind domain.ResourceKind, id string, required domain.Role) (string, error) {
+	var role domain.Role
+	var resourceOwner string
+	switch kind {
+	case domain.ResourceList:
+		list, err := srv.listRepository.Get(id)
+		if err != nil {
+			return "", domain.Categorise("retrieving list from repository failed", err)
+		}
+		resourceOwner = list.OwnerID
+		role, err = srv.ListRole(ctx, list)
+		if err != nil {
+			return "", err
+		}
+	case domain.ResourceEntry:
+		entry, err := srv.entryRepository.Get(id)
+		if err != nil {
+			return "", domain.Categorise("retrieving entry from repository failed", err)
+		}
+		resourceOwner = entry.OwnerID
+		role, err = srv.EntryRole(ctx, entry)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", domain.NewValidationError("resource_type", "must be either list or entry")
+	}
+
+	if role == "" {
+		return "", domain.NotFound(string(kind) + " not found in repository")
+	}
+	if !role.Includes(required) {
+		return "", domain.Forbidden("requires the " + string(required) + " role on the " + string(kind))
+	}
+	return resourceOwner, nil
+}
+
+// granted returns the role granted to the user with userID on a resource, if any.
+func (srv *service) granted(kind domain.ResourceKind, id, userID string) (domain.Role, error) {
+	grant, err := srv.grantRepository.Get(kind, id, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", nil
+		}
+		return "", domain.Categorise("retrieving grant from repository failed", err)
+	}
+	return grant.Role, nil
+}
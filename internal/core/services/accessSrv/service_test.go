@@ -0,0 +1,275 @@
+package accessSrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/grantRepo"
+	"github.com/Nikym/go-todo/internal/repositories/listRepo"
+	"github.com/Nikym/go-todo/internal/repositories/userRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// as returns the context of the user with the given username, whose ID is the username itself.
+func as(username string) context.Context {
+	return domain.WithIdentity(context.Background(), domain.Identity{UserID: username, Username: username})
+}
+
+// newService returns a service over the lists and entries of alice: entry a in her sprint list
+// with its subtask b, and entry c in no list. Bob may view the sprint list, carol may edit
+// entry a, and dave may view the sprint list and owns entry a.
+func newService(t *testing.T) *service {
+	users := userRepo.NewMemKVS()
+	for _, username := range []string{"alice", "bob", "carol", "dave"} {
+		require.NoError(t, users.Save(&domain.User{ID: username, Username: username}))
+	}
+
+	lists := listRepo.NewMemKVS()
+	require.NoError(t, lists.Save(&domain.List{ID: "sprint", Name: "Sprint", OwnerID: "alice"}))
+
+	entries := entryRepo.NewMemKVS()
+	for _, entry := range []*domain.Entry{
+		{ID: "a", Title: "Plan", ListID: "sprint", OwnerID: "alice"},
+		{ID: "b", Title: "Estimate", ListID: "sprint", ParentID: "a", OwnerID: "alice"},
+		{ID: "c", Title: "Holiday", OwnerID: "alice"},
+	} {
+		require.NoError(t, entries.Save(entry))
+	}
+
+	grants := grantRepo.NewMemKVS()
+	for _, grant := range []*domain.Grant{
+		{Kind: domain.ResourceList, ResourceID: "sprint", UserID: "bob", Role: domain.RoleViewer},
+		{Kind: domain.ResourceEntry, ResourceID: "a", UserID: "carol", Role: domain.RoleEditor},
+		{Kind: domain.ResourceList, ResourceID: "sprint", UserID: "dave", Role: domain.RoleViewer},
+		{Kind: domain.ResourceEntry, ResourceID: "a", UserID: "dave", Role: domain.RoleOwner},
+	} {
+		require.NoError(t, grants.Save(grant))
+	}
+
+	return New(grants, entries, lists, users)
+}
+
+func TestService_EntryRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		entry    string
+		expected domain.Role
+	}{
+		{
+			name:     "should return owner for the owner of the entry",
+			user:     "alice",
+			entry:    "c",
+			expected: domain.RoleOwner,
+		},
+		{
+			name:     "should return the role granted on the list of the entry",
+			user:     "bob",
+			entry:    "b",
+			expected: domain.RoleViewer,
+		},
+		{
+			name:     "should return the role granted on a parent of the entry",
+			user:     "carol",
+			entry:    "b",
+			expected: domain.RoleEditor,
+		},
+		{
+			name:     "should return the highest role granted to the user",
+			user:     "dave",
+			entry:    "b",
+			expected: domain.RoleOwner,
+		},
+		{
+			name:  "should return no role when nothing is granted to the user",
+			user:  "carol",
+			entry: "c",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newService(t)
+			entry, err := srv.entryRepository.Get(test.entry)
+			require.NoError(t, err)
+
+			role, err := srv.EntryRole(as(test.user), entry)
+			require.NoError(t, err)
+			assert.EqualValues(t, test.expected, role)
+		})
+	}
+}
+
+func TestService_EntryRole_Unauthenticated(t *testing.T) {
+	srv := newService(t)
+
+	_, err := srv.EntryRole(context.Background(), &domain.Entry{ID: "c", OwnerID: "alice"})
+	assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+}
+
+func TestService_Share(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  string
+		kind  domain.ResourceKind
+		id    string
+		input domain.GrantInput
+		err   error
+	}{
+		{
+			name:  "should share a list owned by the user",
+			user:  "alice",
+			kind:  domain.ResourceList,
+			id:    "sprint",
+			input: domain.GrantInput{Username: "Carol", Role: domain.RoleEditor},
+		},
+		{
+			name:  "should share an entry the user was made owner of",
+			user:  "dave",
+			kind:  domain.ResourceEntry,
+			id:    "b",
+			input: domain.GrantInput{Username: "carol", Role: domain.RoleViewer},
+		},
+		{
+			name:  "should return forbidden when user is not an owner",
+			user:  "carol",
+			kind:  domain.ResourceEntry,
+			id:    "a",
+			input: domain.GrantInput{Username: "bob", Role: domain.RoleViewer},
+			err:   domain.ErrForbidden,
+		},
+		{
+			name:  "should return not found when user has no access",
+			user:  "carol",
+			kind:  domain.ResourceEntry,
+			id:    "c",
+			input: domain.GrantInput{Username: "bob", Role: domain.RoleViewer},
+			err:   domain.ErrNotFound,
+		},
+		{
+			name:  "should return validation error when role is unknown",
+			user:  "alice",
+			kind:  domain.ResourceList,
+			id:    "sprint",
+			input: domain.GrantInput{Username: "carol", Role: "admin"},
+			err:   domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when user does not exist",
+			user:  "alice",
+			kind:  domain.ResourceList,
+			id:    "sprint",
+			input: domain.GrantInput{Username: "erin", Role: domain.RoleViewer},
+			err:   domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when user owns the resource",
+			user:  "dave",
+			kind:  domain.ResourceEntry,
+			id:    "a",
+			input: domain.GrantInput{Username: "alice", Role: domain.RoleViewer},
+			err:   domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newService(t)
+
+			grant, err := srv.Share(as(test.user), test.kind, test.id, test.input)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualValues(t, "carol", grant.Username)
+			assert.EqualValues(t, test.input.Role, grant.Role)
+
+			grants, err := srv.Shared(as("carol"), test.kind)
+			require.NoError(t, err)
+			assert.Contains(t, grants, &domain.Grant{
+				Kind:       test.kind,
+				ResourceID: test.id,
+				UserID:     "carol",
+				Role:       test.input.Role,
+				CreatedAt:  grant.CreatedAt,
+			})
+		})
+	}
+}
+
+func TestService_Revoke(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   string
+		kind   domain.ResourceKind
+		id     string
+		userID string
+		err    error
+	}{
+		{
+			name:   "should revoke a role on a resource owned by the user",
+			user:   "alice",
+			kind:   domain.ResourceList,
+			id:     "sprint",
+			userID: "bob",
+		},
+		{
+			name:   "should let users give up their own role",
+			user:   "bob",
+			kind:   domain.ResourceList,
+			id:     "sprint",
+			userID: "bob",
+		},
+		{
+			name:   "should return forbidden when revoking the role of another user without owning the resource",
+			user:   "bob",
+			kind:   domain.ResourceList,
+			id:     "sprint",
+			userID: "dave",
+			err:    domain.ErrForbidden,
+		},
+		{
+			name:   "should return validation error when resource type is unknown",
+			user:   "alice",
+			kind:   "board",
+			id:     "sprint",
+			userID: "bob",
+			err:    domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newService(t)
+
+			err := srv.Revoke(as(test.user), test.kind, test.id, test.userID)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			role, err := srv.granted(test.kind, test.id, test.userID)
+			require.NoError(t, err)
+			assert.Empty(t, role)
+		})
+	}
+}
+
+func TestService_Collaborators(t *testing.T) {
+	srv := newService(t)
+
+	grants, err := srv.Collaborators(as("bob"), domain.ResourceList, "sprint")
+	require.NoError(t, err)
+	usernames := make([]string, len(grants))
+	for i, grant := range grants {
+		usernames[i] = grant.Username
+	}
+	assert.EqualValues(t, []string{"bob", "dave"}, usernames)
+
+	_, err = srv.Collaborators(as("carol"), domain.ResourceList, "sprint")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
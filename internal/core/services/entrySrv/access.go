@@ -0,0 +1,52 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+)
+
+// role returns the role of the user the context acts for on the entry. Without an access
+// service, only the owner of an entry has access to it.
+func (srv *service) role(ctx context.Context, entry *domain.Entry) (domain.Role, error) {
+	if srv.access != nil {
+		return srv.access.EntryRole(ctx, entry)
+	}
+	return ownerRole(ctx, entry.OwnerID)
+}
+
+// listRole returns the role of the user the context acts for on the list.
+func (srv *service) listRole(ctx context.Context, list *domain.List) (domain.Role, error) {
+	if srv.access != nil {
+		return srv.access.ListRole(ctx, list)
+	}
+	return ownerRole(ctx, list.OwnerID)
+}
+
+// authorize checks that the user the context acts for has at least the required role on the
+// entry. Entries the user has no access to are not found.
+func (srv *service) authorize(ctx context.Context, entry *domain.Entry, required domain.Role) error {
+	role, err := srv.role(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return domain.NotFound("entry not found in repository")
+	}
+	if !role.Includes(required) {
+		return domain.Forbidden("requires the " + string(required) + " role on the entry")
+	}
+	return nil
+}
+
+// ownerRole returns the owner role when the context acts for the user with ownerID, and the
+// empty role otherwise.
+func ownerRole(ctx context.Context, ownerID string) (domain.Role, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if userID != ownerID {
+		return "", nil
+	}
+	return domain.RoleOwner, nil
+}
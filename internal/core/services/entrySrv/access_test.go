@@ -0,0 +1,114 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/services/accessSrv"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/grantRepo"
+	"github.com/Nikym/go-todo/internal/repositories/listRepo"
+	"github.com/Nikym/go-todo/internal/repositories/userRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestService_Access(t *testing.T) {
+	bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+	carol := domain.WithIdentity(context.Background(), domain.Identity{UserID: "carol", Username: "carol"})
+
+	// setUp returns a service over the sprint list of alice, holding a single entry, which bob
+	// may view and carol may edit.
+	setUp := func(t *testing.T) (*service, *domain.List, *domain.Entry) {
+		users := userRepo.NewMemKVS()
+		for _, username := range []string{"alice", "bob", "carol"} {
+			require.NoError(t, users.Save(&domain.User{ID: username, Username: username}))
+		}
+		lists := listRepo.NewMemKVS()
+		sprint := domain.NewList("Sprint", "")
+		sprint.OwnerID = "alice"
+		require.NoError(t, lists.Save(sprint))
+
+		entries := entryRepo.NewMemKVS()
+		access := accessSrv.New(grantRepo.NewMemKVS(), entries, lists, users)
+		srv := New(entries, WithLists(lists), WithAccess(access))
+
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release", ListID: sprint.ID})
+		require.NoError(t, err)
+		_, err = access.Share(alice, domain.ResourceList, sprint.ID, domain.GrantInput{Username: "bob", Role: domain.RoleViewer})
+		require.NoError(t, err)
+		_, err = access.Share(alice, domain.ResourceList, sprint.ID, domain.GrantInput{Username: "carol", Role: domain.RoleEditor})
+		require.NoError(t, err)
+		return srv, sprint, entry
+	}
+
+	t.Run("should let viewers get but not change entries", func(t *testing.T) {
+		srv, _, entry := setUp(t)
+
+		stored, err := srv.Get(bob, entry.ID)
+		require.NoError(t, err)
+		stored.Title = "Ship release"
+		assert.ErrorIs(t, srv.Update(bob, entry.ID, stored), domain.ErrForbidden)
+		assert.ErrorIs(t, srv.Delete(bob, entry.ID, domain.DeleteOptions{}), domain.ErrForbidden)
+	})
+
+	t.Run("should let editors change entries and keep their owner", func(t *testing.T) {
+		srv, _, entry := setUp(t)
+
+		entry.Title = "Ship release"
+		require.NoError(t, srv.Update(carol, entry.ID, entry))
+
+		stored, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Ship release", stored.Title)
+		assert.EqualValues(t, "alice", stored.OwnerID)
+
+		require.NoError(t, srv.Delete(carol, entry.ID, domain.DeleteOptions{}))
+		_, err = srv.Get(alice, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should let editors add entries and subtasks to the list", func(t *testing.T) {
+		srv, sprint, entry := setUp(t)
+
+		_, err := srv.Create(carol, domain.EntryInput{Title: "Write notes", ListID: sprint.ID})
+		require.NoError(t, err)
+		child, err := srv.Create(carol, domain.EntryInput{Title: "Tag release", ParentID: entry.ID})
+		require.NoError(t, err)
+		assert.EqualValues(t, sprint.ID, child.ListID)
+
+		_, err = srv.Create(bob, domain.EntryInput{Title: "Write notes", ListID: sprint.ID})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = srv.Create(bob, domain.EntryInput{Title: "Tag release", ParentID: entry.ID})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("should list the entries of every user in a shared list", func(t *testing.T) {
+		srv, sprint, _ := setUp(t)
+		_, err := srv.Create(carol, domain.EntryInput{Title: "Write notes", ListID: sprint.ID})
+		require.NoError(t, err)
+
+		for _, ctx := range []context.Context{alice, bob, carol} {
+			page, err := srv.List(ctx, domain.ListQuery{ListID: &sprint.ID})
+			require.NoError(t, err)
+			assert.Len(t, page.Entries, 2)
+		}
+
+		page, err := srv.List(bob, domain.ListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Entries)
+	})
+
+	t.Run("should hide entries from users without access", func(t *testing.T) {
+		srv, _, entry := setUp(t)
+		dave := domain.WithIdentity(context.Background(), domain.Identity{UserID: "dave", Username: "dave"})
+
+		_, err := srv.Get(dave, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.ErrorIs(t, srv.Update(dave, entry.ID, entry), domain.ErrNotFound)
+		assert.ErrorIs(t, srv.Delete(dave, entry.ID, domain.DeleteOptions{}), domain.ErrNotFound)
+
+		_, err = srv.Get(alice, entry.ID)
+		assert.NoError(t, err)
+	})
+}
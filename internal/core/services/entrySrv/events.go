@@ -0,0 +1,115 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"sync"
+	"time"
+)
+
+// DefaultPublishTimeout is how long publishing the events of a change waits for subscribers
+// without room in their buffer, unless configured otherwise.
+const DefaultPublishTimeout = 5 * time.Second
+
+// announce stages the events announcing the change of the entry with the given UUID from before
+// to after, which changed the given fields, for publishing once the change is stored. Changes of
+// no field are not announced. Subscribers get a copy of the entry, so that they cannot alter the
+// revisions and steps recorded along with the events.
+func (r *recorder) announce(id string, before, after *domain.Entry, changes []domain.FieldChange, at time.Time) error {
+	entry, err := copyEntry(after)
+	if err != nil {
+		return err
+	}
+
+	event := &domain.Event{EntryID: id, ActorID: r.actorID, At: at, Entry: entry}
+	switch {
+	case before == nil:
+		event.Type = domain.EventCreated
+	case after == nil:
+		event.Type = domain.EventDeleted
+	case len(changes) == 0:
+		return nil
+	default:
+		event.Type, event.Changes = domain.EventUpdated, changes
+	}
+	r.events = append(r.events, event)
+
+	if before != nil && after != nil && before.Done != after.Done {
+		toggled := *event
+		toggled.Type, toggled.Changes = domain.EventReopened, nil
+		if after.Done {
+			toggled.Type = domain.EventCompleted
+		}
+		r.events = append(r.events, &toggled)
+	}
+	return nil
+}
+
+// outbox holds the events of stored changes until they are published, so that they are published
+// in the order the changes were stored, and only once they were. It is kept in memory only, so
+// delivery is best-effort: the events not yet published when the process ends are lost, as are
+// those dropped by the bus for subscribers that have no room for them in time.
+type outbox struct {
+	mu       sync.Mutex
+	pending  []*outgoing
+	draining bool
+}
+
+// outgoing is a batch of events staged in an outbox, published with ctx.
+type outgoing struct {
+	ctx    context.Context
+	events []*domain.Event
+	done   chan struct{}
+}
+
+type publishingKey struct{}
+
+// send stages events in the outbox and publishes them to bus after the events staged before, and
+// returns once they were published. Publishing is not cancelled along with ctx, as the changes
+// are stored either way, but gives up on subscribers without room for the events once timeout
+// passes, unless it is 0 or less, so that a stuck subscriber cannot hold up writes for good.
+// Events staged while handling others, by the subscribers to bus, are published once the others
+// were, without waiting for them.
+func (o *outbox) send(ctx context.Context, bus ports.EventBus, events []*domain.Event, timeout time.Duration) {
+	batch := &outgoing{
+		ctx:    context.WithValue(context.WithoutCancel(ctx), publishingKey{}, true),
+		events: events,
+		done:   make(chan struct{}),
+	}
+
+	o.mu.Lock()
+	o.pending = append(o.pending, batch)
+	if o.draining {
+		o.mu.Unlock()
+		// The subscribers handling the events before these would otherwise wait for themselves.
+		if ctx.Value(publishingKey{}) == nil {
+			<-batch.done
+		}
+		return
+	}
+
+	o.draining = true
+	for len(o.pending) > 0 {
+		next := o.pending[0]
+		o.mu.Unlock()
+		publish(next, bus, timeout)
+		close(next.done)
+		o.mu.Lock()
+		o.pending = o.pending[1:]
+	}
+	o.draining = false
+	o.mu.Unlock()
+}
+
+// publish publishes the events of batch to bus, giving up on subscribers without room for them
+// once timeout passes, unless it is 0 or less.
+func publish(batch *outgoing, bus ports.EventBus, timeout time.Duration) {
+	ctx := batch.ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	bus.Publish(ctx, batch.events...)
+}
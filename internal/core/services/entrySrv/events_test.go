@@ -0,0 +1,279 @@
+package entrySrv
+
+import (
+	"context"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/eventBus"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// failingRepository is an entry repository failing to update or delete the entry with the given
+// UUID.
+type failingRepository struct {
+	ports.EntryRepository
+	failing string
+}
+
+func (r failingRepository) Update(id string, entry *domain.Entry) error {
+	if id == r.failing {
+		return domain.Internal("writing entry failed", errors.New("disk full"))
+	}
+	return r.EntryRepository.Update(id, entry)
+}
+
+func (r failingRepository) Delete(id string) error {
+	if id == r.failing {
+		return domain.Internal("writing entry failed", errors.New("disk full"))
+	}
+	return r.EntryRepository.Delete(id)
+}
+
+// failingAtomicRepository is an entry repository supporting transactions, in which it fails to
+// update or delete the entry with the given UUID.
+type failingAtomicRepository struct {
+	ports.AtomicEntryRepository
+	failing string
+}
+
+func (r *failingAtomicRepository) Atomic(fn func(repo ports.EntryRepository) error) error {
+	return r.AtomicEntryRepository.Atomic(func(repo ports.EntryRepository) error {
+		return fn(failingRepository{EntryRepository: repo, failing: r.failing})
+	})
+}
+
+func TestService_Events(t *testing.T) {
+	// setUp returns a service publishing to a bus and the events it published so far.
+	setUp := func(repo ports.EntryRepository, opts ...Option) (*service, *[]*domain.Event) {
+		bus := eventBus.New()
+		t.Cleanup(bus.Close)
+		published := &[]*domain.Event{}
+		bus.Subscribe(func(_ context.Context, event *domain.Event) {
+			*published = append(*published, event)
+		})
+		return New(repo, append(opts, WithEvents(bus))...), published
+	}
+	types := func(events []*domain.Event) []domain.EventType {
+		types := []domain.EventType{}
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		return types
+	}
+	fields := func(event *domain.Event) []string {
+		fields := []string{}
+		for _, change := range event.Changes {
+			fields = append(fields, change.Field)
+		}
+		return fields
+	}
+
+	t.Run("should publish the creation of an entry", func(t *testing.T) {
+		srv, published := setUp(entryRepo.NewMemKVS())
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+
+		require.Len(t, *published, 1)
+		event := (*published)[0]
+		assert.EqualValues(t, domain.EventCreated, event.Type)
+		assert.EqualValues(t, entry.ID, event.EntryID)
+		assert.EqualValues(t, "alice", event.ActorID)
+		assert.False(t, event.At.IsZero())
+		assert.EqualValues(t, entry, event.Entry)
+	})
+
+	t.Run("should publish the fields changed by an update and the completion of the entry", func(t *testing.T) {
+		srv, published := setUp(entryRepo.NewMemKVS())
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		entry.Title, entry.Done = "Release 1.0", true
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		events := (*published)[1:]
+		assert.EqualValues(t, []domain.EventType{domain.EventUpdated, domain.EventCompleted}, types(events))
+		assert.EqualValues(t, []string{"title", "done", "completed_at"}, fields(events[0]))
+		assert.EqualValues(t, "Release 1.0", events[0].Entry.Title)
+
+		entry.Done = false
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+		events = (*published)[3:]
+		assert.EqualValues(t, []domain.EventType{domain.EventUpdated, domain.EventReopened}, types(events))
+	})
+
+	t.Run("should not publish updates changing no field", func(t *testing.T) {
+		srv, published := setUp(entryRepo.NewMemKVS())
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		assert.Len(t, *published, 1)
+	})
+
+	t.Run("should publish the deletion of an entry and its subtasks", func(t *testing.T) {
+		srv, published := setUp(entryRepo.NewMemKVS())
+		entries := chain(t, srv, 2)
+		require.NoError(t, srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true}))
+
+		events := (*published)[2:]
+		assert.EqualValues(t, []domain.EventType{domain.EventDeleted, domain.EventDeleted}, types(events))
+		assert.ElementsMatch(t, []string{entries[0].ID, entries[1].ID}, []string{events[0].EntryID, events[1].EntryID})
+		assert.Nil(t, events[0].Entry)
+	})
+
+	t.Run("should publish the completions rolled up to the parent", func(t *testing.T) {
+		srv, published := setUp(entryRepo.NewMemKVS(), WithCompletionRollup(RollupAutomatic))
+		entries := chain(t, srv, 2)
+		subtask := entries[1]
+		subtask.Done = true
+		require.NoError(t, srv.Update(alice, subtask.ID, subtask))
+
+		events := (*published)[2:]
+		assert.EqualValues(t, []domain.EventType{domain.EventUpdated, domain.EventCompleted, domain.EventUpdated, domain.EventCompleted}, types(events))
+		assert.ElementsMatch(t, []string{subtask.ID, entries[0].ID}, []string{events[0].EntryID, events[2].EntryID})
+	})
+
+	t.Run("should publish nothing for writes that failed", func(t *testing.T) {
+		repo := &failingRepository{EntryRepository: entryRepo.NewMemKVS()}
+		srv, published := setUp(repo, WithCompletionRollup(RollupAutomatic))
+		entries := chain(t, srv, 2)
+		subtask := entries[1]
+		subtask.Done = true
+
+		repo.failing = subtask.ID
+		err := srv.Update(alice, subtask.ID, subtask)
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		assert.Len(t, *published, 2)
+
+		// The subtask is stored before its parent is rolled up, so only its completion is published.
+		repo.failing = entries[0].ID
+		err = srv.Update(alice, subtask.ID, subtask)
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		events := (*published)[2:]
+		assert.EqualValues(t, []domain.EventType{domain.EventUpdated, domain.EventCompleted}, types(events))
+		assert.EqualValues(t, subtask.ID, events[0].EntryID)
+	})
+
+	t.Run("should publish the writes stored before a failure without transactions", func(t *testing.T) {
+		repo := &failingRepository{EntryRepository: entryRepo.NewMemKVS()}
+		srv, published := setUp(repo)
+		entries := chain(t, srv, 3)
+		repo.failing = entries[0].ID
+
+		err := srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true})
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		events := (*published)[3:]
+		assert.EqualValues(t, []domain.EventType{domain.EventDeleted, domain.EventDeleted}, types(events))
+		assert.ElementsMatch(t, []string{entries[1].ID, entries[2].ID}, []string{events[0].EntryID, events[1].EntryID})
+	})
+
+	t.Run("should publish no write of a failed transaction", func(t *testing.T) {
+		db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		repo := &failingAtomicRepository{AtomicEntryRepository: entryRepo.NewBolt(db)}
+		srv, published := setUp(repo)
+		entries := chain(t, srv, 3)
+		repo.failing = entries[0].ID
+
+		err = srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true})
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		assert.Len(t, *published, 3)
+	})
+
+	t.Run("should publish the changes made by subscribers after the changes they handle", func(t *testing.T) {
+		bus := eventBus.New()
+		t.Cleanup(bus.Close)
+		srv := New(entryRepo.NewMemKVS(), WithEvents(bus))
+		published := []*domain.Event{}
+		bus.Subscribe(func(ctx context.Context, event *domain.Event) {
+			published = append(published, event)
+			if event.Type == domain.EventCreated && event.Entry.ParentID == "" {
+				_, err := srv.Create(ctx, domain.EntryInput{Title: "Write notes", ParentID: event.EntryID})
+				require.NoError(t, err)
+			}
+		})
+
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		require.Len(t, published, 2)
+		assert.EqualValues(t, entry.ID, published[0].EntryID)
+		assert.EqualValues(t, entry.ID, published[1].Entry.ParentID)
+	})
+
+	t.Run("should publish the changes made by requests cancelled since", func(t *testing.T) {
+		bus := eventBus.New()
+		srv := New(entryRepo.NewMemKVS(), WithEvents(bus))
+		release := make(chan struct{})
+		handled := 0
+		bus.SubscribeAsync(func(context.Context, *domain.Event) {
+			<-release
+			handled++
+		}, domain.Subscription{Buffer: 1, Overflow: domain.OverflowBlock})
+
+		ctx, cancel := context.WithCancel(alice)
+		cancel()
+		time.AfterFunc(20*time.Millisecond, func() { close(release) })
+		for i := 0; i < 3; i++ {
+			_, err := srv.Create(ctx, domain.EntryInput{Title: "Release"})
+			require.NoError(t, err)
+		}
+		bus.Close()
+
+		assert.EqualValues(t, 3, handled)
+		assert.Zero(t, bus.Dropped())
+	})
+
+	t.Run("should give up on subscribers blocked for longer than the publish timeout", func(t *testing.T) {
+		bus := eventBus.New()
+		srv := New(entryRepo.NewMemKVS(), WithEvents(bus), WithPublishTimeout(20*time.Millisecond))
+		taken, release := make(chan struct{}, 3), make(chan struct{})
+		bus.SubscribeAsync(func(context.Context, *domain.Event) {
+			taken <- struct{}{}
+			<-release
+		}, domain.Subscription{Buffer: 1, Overflow: domain.OverflowBlock})
+
+		_, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		<-taken
+		created := make(chan error)
+		go func() {
+			for i := 0; i < 2; i++ {
+				if _, err := srv.Create(alice, domain.EntryInput{Title: "Release"}); err != nil {
+					created <- err
+					return
+				}
+			}
+			created <- nil
+		}()
+		select {
+		case err := <-created:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("creating entries waited for the blocked subscriber")
+		}
+		assert.EqualValues(t, 1, bus.Dropped())
+
+		close(release)
+		bus.Close()
+	})
+
+	t.Run("should publish the changes made by undoing an operation", func(t *testing.T) {
+		srv, published := setUp(entryRepo.NewMemKVS(), WithUndo(10))
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		_, err = srv.Undo(alice)
+		require.NoError(t, err)
+
+		events := (*published)[1:]
+		require.Len(t, events, 1)
+		assert.EqualValues(t, domain.EventDeleted, events[0].Type)
+		assert.EqualValues(t, entry.ID, events[0].EntryID)
+	})
+}
@@ -0,0 +1,291 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"strconv"
+)
+
+// DefaultMaxDepth is the number of levels an entry hierarchy may have when no other limit is
+// configured: top-level entries, their subtasks and so on.
+const DefaultMaxDepth = 5
+
+// CompletionRollup decides how the completion of subtasks affects their parent.
+type CompletionRollup int
+
+const (
+	// RollupRequireChildren keeps a parent from being done while any of its subtasks is open:
+	// marking such a parent done, or adding or reopening a subtask of a done parent, is rejected.
+	RollupRequireChildren CompletionRollup = iota
+	// RollupAutomatic marks a parent done once all of its subtasks are done and reopens it
+	// as soon as one of them is open again.
+	RollupAutomatic
+	// RollupNone lets parents and subtasks be completed independently.
+	RollupNone
+)
+
+// Children returns the direct subtasks of the entry with the given UUID.
+func (srv *service) Children(ctx context.Context, id string) ([]*domain.Entry, error) {
+	if _, err := srv.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return srv.children(srv.entryRepository, id)
+}
+
+// Tree returns the entry with the given UUID together with all of its subtasks, recursively.
+func (srv *service) Tree(ctx context.Context, id string) (*domain.EntryNode, error) {
+	entry, err := srv.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return srv.tree(entry)
+}
+
+func (srv *service) tree(entry *domain.Entry) (*domain.EntryNode, error) {
+	children, err := srv.children(srv.entryRepository, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &domain.EntryNode{Entry: entry, Children: make([]*domain.EntryNode, 0, len(children))}
+	for _, child := range children {
+		childNode, err := srv.tree(child)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
+
+// children returns every direct subtask of the entry with the given UUID, ordered by creation.
+func (srv *service) children(repo ports.EntryRepository, id string) ([]*domain.Entry, error) {
+	page, err := repo.List(domain.ListQuery{ParentID: &id, SortBy: domain.SortByCreatedAt})
+	if err != nil {
+		return nil, domain.Categorise("listing subtasks from repository failed", err)
+	}
+	return page.Entries, nil
+}
+
+// checkPlacement verifies that the entry with the given UUID, or a new entry when id is empty,
+// can become a subtask of the entry with parentID: the parent must exist, must not be the entry
+// itself or one of its subtasks, and the resulting hierarchy must not exceed the maximum depth.
+// It returns the parent, or nil for top-level entries.
+func (srv *service) checkPlacement(id, parentID string) (*domain.Entry, error) {
+	if parentID == "" {
+		return nil, nil
+	}
+	if parentID == id {
+		return nil, domain.NewValidationError("parent_id", "an entry cannot be its own parent")
+	}
+
+	parent, err := srv.entryRepository.Get(parentID)
+	if err != nil && !isNotFound(err) {
+		return nil, domain.Categorise("retrieving parent entry from repository failed", err)
+	}
+	if err != nil {
+		return nil, domain.NewValidationError("parent_id", "parent entry does not exist")
+	}
+
+	depth := 1
+	for ancestor := parent; ancestor.ParentID != ""; depth++ {
+		if ancestor.ParentID == id {
+			return nil, domain.NewValidationError("parent_id", "an entry cannot be moved below one of its subtasks")
+		}
+		ancestor, err = srv.entryRepository.Get(ancestor.ParentID)
+		if err != nil {
+			return nil, domain.Categorise("retrieving ancestor entry from repository failed", err)
+		}
+	}
+
+	height := 1
+	if id != "" {
+		if height, err = srv.height(id); err != nil {
+			return nil, err
+		}
+	}
+	if depth+height > srv.maxDepth {
+		return nil, domain.NewValidationError("parent_id", "subtasks cannot be nested more than "+strconv.Itoa(srv.maxDepth)+" levels deep")
+	}
+
+	return parent, nil
+}
+
+// height returns the number of levels of the hierarchy below and including the given entry.
+func (srv *service) height(id string) (int, error) {
+	children, err := srv.children(srv.entryRepository, id)
+	if err != nil {
+		return 0, err
+	}
+
+	height := 0
+	for _, child := range children {
+		h, err := srv.height(child.ID)
+		if err != nil {
+			return 0, err
+		}
+		if h > height {
+			height = h
+		}
+	}
+	return height + 1, nil
+}
+
+// checkParent verifies that the user the context acts for may add subtasks to parent, which
+// requires the editor role on it. Parents the user has no access to do not exist.
+func (srv *service) checkParent(ctx context.Context, parent *domain.Entry) error {
+	if parent == nil {
+		return nil
+	}
+
+	err := srv.authorize(ctx, parent, domain.RoleEditor)
+	if isNotFound(err) {
+		return domain.NewValidationError("parent_id", "parent entry does not exist")
+	}
+	return err
+}
+
+// checkCompletion enforces RollupRequireChildren for an entry about to be stored with the given
+// state: a done entry cannot have open subtasks, and an open entry cannot have a done parent.
+func (srv *service) checkCompletion(entry *domain.Entry, parent *domain.Entry) error {
+	if srv.rollup != RollupRequireChildren {
+		return nil
+	}
+
+	if !entry.Done && parent != nil && parent.Done {
+		return domain.Conflict("parent entry is done; reopen it before adding or reopening subtasks")
+	}
+
+	if entry.Done && entry.ID != "" {
+		children, err := srv.children(srv.entryRepository, entry.ID)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if !child.Done {
+				return domain.Conflict("entry has open subtasks; complete them first")
+			}
+		}
+	}
+
+	return nil
+}
+
+// rollUp applies RollupAutomatic to the entry with the given UUID, marking it done when all of
+// its subtasks are done and reopening it when any is open. Entries without subtasks are left
+// untouched. Changes propagate to the parents of the entry, regardless of the role of the user
+// on them.
+func (srv *service) rollUp(ctx context.Context, id string) error {
+	if srv.rollup != RollupAutomatic || id == "" {
+		return nil
+	}
+
+	parent, err := srv.entryRepository.Get(id)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return domain.Categorise("retrieving parent entry from repository failed", err)
+	}
+
+	children, err := srv.children(srv.entryRepository, id)
+	if err != nil || len(children) == 0 {
+		return err
+	}
+
+	done := true
+	for _, child := range children {
+		done = done && child.Done
+	}
+	if parent.Done == done {
+		return nil
+	}
+
+	updated := *parent
+	updated.Done = done
+	return srv.update(ctx, id, parent, &updated)
+}
+
+// deleteTree removes the entry with the given UUID and, when cascading, all of its subtasks,
+// deepest first. Entries with subtasks are only removed when cascading.
+func (srv *service) deleteTree(repo ports.EntryRepository, id string, cascade bool) error {
+	children, err := srv.children(repo, id)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 && !cascade {
+		return domain.Conflict("entry has subtasks; delete them first or delete with cascade")
+	}
+
+	for _, child := range children {
+		if err := srv.deleteTree(repo, child.ID, cascade); err != nil {
+			return err
+		}
+	}
+
+	if err := repo.Delete(id); err != nil {
+		return domain.Categorise("deleting entry from repository failed", err)
+	}
+	return nil
+}
+
+// atomic runs fn in a single transaction when the repository supports them, and directly
+// against the repository otherwise. When the service keeps a history, the writes made by fn are
+// recorded as revisions on behalf of the user the context acts for, once they were stored;
+// when the context carries an operation being journaled, they are added to it as well, and when
+// the service publishes events, the events announcing them are staged along with the writes and
+// published once the writes were stored. Failed writes are thus never announced.
+func (srv *service) atomic(ctx context.Context, fn func(repo ports.EntryRepository) error) error {
+	op := operationFrom(ctx)
+	if srv.revisionRepository == nil && srv.events == nil && op == nil {
+		return srv.transaction(fn)
+	}
+
+	actorID, err := domain.UserID(ctx)
+	if err != nil {
+		return err
+	}
+	rec := &recorder{actorID: actorID, now: srv.now.Timestamp, publishing: srv.events != nil}
+	err = srv.transaction(func(repo ports.EntryRepository) error {
+		rec.EntryRepository, rec.revisions, rec.steps, rec.events = repo, nil, nil, nil
+		return fn(rec)
+	})
+	if err != nil && srv.transactional() {
+		return err
+	}
+
+	// Without transactions, the writes made before a failure were stored all the same, so they
+	// are added to the operation, their revisions appended and their events published before the
+	// failure is reported.
+	if op != nil {
+		op.steps = append(op.steps, rec.steps...)
+	}
+	if srv.revisionRepository != nil {
+		if failed := srv.appendRevisions(rec.revisions); err == nil {
+			err = failed
+		}
+	}
+	if len(rec.events) > 0 {
+		srv.outbox.send(ctx, srv.events, rec.events, srv.publishTimeout)
+	}
+	return err
+}
+
+// transactional reports whether the repository makes the writes of a transaction either all or
+// none.
+func (srv *service) transactional() bool {
+	_, ok := srv.entryRepository.(ports.AtomicEntryRepository)
+	return ok
+}
+
+// transaction runs fn in a single transaction when the repository supports them, and directly
+// against the repository otherwise.
+func (srv *service) transaction(fn func(repo ports.EntryRepository) error) error {
+	if repo, ok := srv.entryRepository.(ports.AtomicEntryRepository); ok {
+		return repo.Atomic(fn)
+	}
+	return fn(srv.entryRepository)
+}
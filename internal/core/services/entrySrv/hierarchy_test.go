@@ -0,0 +1,166 @@
+package entrySrv
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// chain creates n entries, each a subtask of the previous one, returning them from the top down.
+func chain(t *testing.T, srv *service, n int) []*domain.Entry {
+	entries := make([]*domain.Entry, 0, n)
+	parentID := ""
+	for i := 0; i < n; i++ {
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Step", ParentID: parentID})
+		require.NoError(t, err)
+		entries = append(entries, entry)
+		parentID = entry.ID
+	}
+	return entries
+}
+
+func TestService_Placement(t *testing.T) {
+	t.Run("should return validation error when parent does not exist", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS())
+
+		_, err := srv.Create(alice, domain.EntryInput{Title: "Orphan", ParentID: "missing"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("should return validation error when entry is its own parent", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS())
+		entry := chain(t, srv, 1)[0]
+
+		entry.ParentID = entry.ID
+		assert.ErrorIs(t, srv.Update(alice, entry.ID, entry), domain.ErrValidation)
+	})
+
+	t.Run("should return validation error when entry is moved below its own subtask", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS())
+		entries := chain(t, srv, 3)
+
+		entries[0].ParentID = entries[2].ID
+		assert.ErrorIs(t, srv.Update(alice, entries[0].ID, entries[0]), domain.ErrValidation)
+	})
+
+	t.Run("should return validation error when subtasks are nested too deep", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS(), WithMaxDepth(3))
+		entries := chain(t, srv, 3)
+
+		_, err := srv.Create(alice, domain.EntryInput{Title: "Too deep", ParentID: entries[2].ID})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("should return validation error when a moved subtree would be nested too deep", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS(), WithMaxDepth(3))
+		entries := chain(t, srv, 2)
+		other := chain(t, srv, 2)
+
+		other[0].ParentID = entries[1].ID
+		assert.ErrorIs(t, srv.Update(alice, other[0].ID, other[0]), domain.ErrValidation)
+
+		other[0].ParentID = entries[0].ID
+		assert.NoError(t, srv.Update(alice, other[0].ID, other[0]))
+	})
+}
+
+func TestService_CompletionRollup(t *testing.T) {
+	t.Run("should return conflict when a parent with open subtasks is marked done", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS())
+		entries := chain(t, srv, 2)
+
+		entries[0].Done = true
+		assert.ErrorIs(t, srv.Update(alice, entries[0].ID, entries[0]), domain.ErrConflict)
+
+		entries[1].Done = true
+		require.NoError(t, srv.Update(alice, entries[1].ID, entries[1]))
+		assert.NoError(t, srv.Update(alice, entries[0].ID, entries[0]))
+	})
+
+	t.Run("should return conflict when a subtask is added to a done parent", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS())
+		parent := chain(t, srv, 1)[0]
+		parent.Done = true
+		require.NoError(t, srv.Update(alice, parent.ID, parent))
+
+		_, err := srv.Create(alice, domain.EntryInput{Title: "Late step", ParentID: parent.ID})
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("should complete and reopen the parents of subtasks automatically", func(t *testing.T) {
+		repo := entryRepo.NewMemKVS()
+		srv := New(repo, WithCompletionRollup(RollupAutomatic))
+		entries := chain(t, srv, 3)
+
+		entries[2].Done = true
+		require.NoError(t, srv.Update(alice, entries[2].ID, entries[2]))
+		for _, entry := range entries {
+			stored, err := repo.Get(entry.ID)
+			require.NoError(t, err)
+			assert.True(t, stored.Done)
+			assert.NotNil(t, stored.CompletedAt)
+		}
+
+		_, err := srv.Create(alice, domain.EntryInput{Title: "Another step", ParentID: entries[1].ID})
+		require.NoError(t, err)
+		for _, entry := range entries[:2] {
+			stored, err := repo.Get(entry.ID)
+			require.NoError(t, err)
+			assert.False(t, stored.Done)
+		}
+	})
+
+	t.Run("should complete parents and subtasks independently when rollup is disabled", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS(), WithCompletionRollup(RollupNone))
+		entries := chain(t, srv, 2)
+
+		entries[0].Done = true
+		assert.NoError(t, srv.Update(alice, entries[0].ID, entries[0]))
+	})
+}
+
+func TestService_DeleteHierarchy(t *testing.T) {
+	t.Run("should return conflict when deleting an entry with subtasks without cascade", func(t *testing.T) {
+		repo := entryRepo.NewMemKVS()
+		srv := New(repo)
+		entries := chain(t, srv, 2)
+
+		assert.ErrorIs(t, srv.Delete(alice, entries[0].ID, domain.DeleteOptions{}), domain.ErrConflict)
+		_, err := repo.Get(entries[1].ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should delete every subtask when deleting with cascade", func(t *testing.T) {
+		repo := entryRepo.NewMemKVS()
+		srv := New(repo)
+		entries := chain(t, srv, 3)
+
+		require.NoError(t, srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true}))
+		for _, entry := range entries {
+			_, err := repo.Get(entry.ID)
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+		}
+	})
+}
+
+func TestService_Tree(t *testing.T) {
+	srv := New(entryRepo.NewMemKVS())
+	entries := chain(t, srv, 3)
+	sibling, err := srv.Create(alice, domain.EntryInput{Title: "Sibling", ParentID: entries[0].ID})
+	require.NoError(t, err)
+
+	children, err := srv.Children(alice, entries[0].ID)
+	require.NoError(t, err)
+	assert.Len(t, children, 2)
+
+	tree, err := srv.Tree(alice, entries[0].ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, entries[0].ID, tree.ID)
+	assert.Len(t, tree.Children, 2)
+	assert.ElementsMatch(t, []string{entries[1].ID, sibling.ID}, []string{tree.Children[0].ID, tree.Children[1].ID})
+
+	_, err = srv.Tree(alice, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,204 @@
+package entrySrv
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"time"
+)
+
+// History returns the revisions of the entry with the given UUID, oldest first.
+func (srv *service) History(ctx context.Context, id string) ([]*domain.Revision, error) {
+	if _, err := srv.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if srv.revisionRepository == nil {
+		return []*domain.Revision{}, nil
+	}
+
+	revisions, err := srv.revisionRepository.List(id)
+	if err != nil {
+		return nil, domain.Categorise("listing revisions from repository failed", err)
+	}
+	return revisions, nil
+}
+
+// Revert sets the fields of the entry with the given UUID that users can edit back to their
+// values as of the revision with the given number, and returns the entry. The change is made by
+// Update, so that it is recorded as a new revision and can be undone, and is based on the given
+// version of the entry, or on its current version when version is 0.
+func (srv *service) Revert(ctx context.Context, id string, number int, version int64) (*domain.Entry, error) {
+	existing, err := srv.Get(ctx, id)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+	if err := srv.authorize(ctx, existing, domain.RoleEditor); err != nil {
+		return &domain.Entry{}, err
+	}
+	if srv.revisionRepository == nil {
+		return &domain.Entry{}, domain.NotFound("revision not found in repository")
+	}
+
+	revision, err := srv.revisionRepository.Get(id, number)
+	if err != nil {
+		return &domain.Entry{}, domain.Categorise("retrieving revision from repository failed", err)
+	}
+	past := revision.Entry
+	if past == nil {
+		return &domain.Entry{}, domain.NewValidationError("number", "revision records the deletion of the entry")
+	}
+
+	entry := *existing
+	entry.Title = past.Title
+	entry.Description = past.Description
+	entry.Done = past.Done
+	entry.ParentID = past.ParentID
+	entry.ListID = past.ListID
+	entry.Priority = past.Priority
+	entry.Tags = past.Tags
+	entry.DueAt = past.DueAt
+	entry.Recurrence = past.Recurrence
+	if version != 0 {
+		entry.Version = version
+	}
+	if err := srv.Update(ctx, id, &entry); err != nil {
+		return &domain.Entry{}, err
+	}
+	return &entry, nil
+}
+
+// appendRevisions appends the revisions recorded by a recorder to the history. An entry created
+// again after having been deleted was restored, and changed from its state before the deletion.
+func (srv *service) appendRevisions(revisions []*domain.Revision) error {
+	for _, revision := range revisions {
+		if revision.Action == domain.RevisionCreated {
+			previous, err := srv.revisionRepository.List(revision.EntryID)
+			if err != nil {
+				return domain.Categorise("listing revisions from repository failed", err)
+			}
+			if len(previous) > 0 && previous[len(previous)-1].Action == domain.RevisionDeleted {
+				revision.Action = domain.RevisionRestored
+				for i := len(previous) - 1; i >= 0; i-- {
+					if previous[i].Entry == nil {
+						continue
+					}
+					if revision.Changes, err = domain.Diff(previous[i].Entry, revision.Entry); err != nil {
+						return err
+					}
+					break
+				}
+			}
+		}
+
+		if err := srv.revisionRepository.Append(revision); err != nil {
+			return domain.Categorise("appending revision to repository failed", err)
+		}
+	}
+	return nil
+}
+
+// recorder is an entry repository recording every change made through it as a revision of the
+// changed entry, made by the user with actorID, and as a step of the operation being journaled.
+// When publishing, it also stages the events announcing the changes.
+type recorder struct {
+	ports.EntryRepository
+	actorID    string
+	now        func() time.Time
+	publishing bool
+	revisions  []*domain.Revision
+	steps      []step
+	events     []*domain.Event
+}
+
+// Save stores entry in the underlying repository and records its creation.
+func (r *recorder) Save(entry *domain.Entry) error {
+	if err := r.EntryRepository.Save(entry); err != nil {
+		return err
+	}
+	return r.record(domain.RevisionCreated, entry.ID, nil, entry)
+}
+
+// Update updates the entry with the given UUID in the underlying repository and records the
+// changes made to it. Updates not changing any field are not recorded.
+func (r *recorder) Update(id string, entry *domain.Entry) error {
+	before, err := r.EntryRepository.Get(id)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err := r.EntryRepository.Update(id, entry); err != nil {
+		return err
+	}
+	return r.record(domain.RevisionUpdated, id, before, entry)
+}
+
+// Delete removes the entry with the given UUID from the underlying repository and records its
+// deletion, if it existed.
+func (r *recorder) Delete(id string) error {
+	before, err := r.EntryRepository.Get(id)
+	if err != nil {
+		if isNotFound(err) {
+			return r.EntryRepository.Delete(id)
+		}
+		return err
+	}
+	if err := r.EntryRepository.Delete(id); err != nil {
+		return err
+	}
+	return r.record(domain.RevisionDeleted, id, before, nil)
+}
+
+// record adds the revision of the entry with the given UUID going from before to after.
+func (r *recorder) record(action domain.RevisionAction, id string, before, after *domain.Entry) error {
+	if action == domain.RevisionCreated {
+		before = nil
+	}
+	changes, err := domain.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	// Callers keep using the entry after storing it, so the revision holds a copy of it.
+	snapshot, err := copyEntry(after)
+	if err != nil {
+		return err
+	}
+	// Even updates changing no field change the version, which undoing later steps relies on.
+	r.steps = append(r.steps, step{before: before, after: snapshot})
+	at := r.now()
+	if r.publishing {
+		if err := r.announce(id, before, after, changes, at); err != nil {
+			return err
+		}
+	}
+	if action == domain.RevisionUpdated && len(changes) == 0 {
+		return nil
+	}
+
+	r.revisions = append(r.revisions, &domain.Revision{
+		EntryID: id,
+		Action:  action,
+		ActorID: r.actorID,
+		At:      at,
+		Changes: changes,
+		Entry:   snapshot,
+	})
+	return nil
+}
+
+// copyEntry returns a deep copy of entry, or nil when entry is nil.
+func copyEntry(entry *domain.Entry) (*domain.Entry, error) {
+	if entry == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return nil, domain.Internal("encoding entry failed", err)
+	}
+	var copied *domain.Entry
+	if err := json.Unmarshal(encoded, &copied); err != nil {
+		return nil, domain.Internal("decoding entry failed", err)
+	}
+	return copied, nil
+}
@@ -0,0 +1,214 @@
+package entrySrv
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/revisionRepo"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/Nikym/go-todo/internal/repositories/trashRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_History(t *testing.T) {
+	bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+	now := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	setUp := func() *service {
+		return New(entryRepo.NewMemKVS(), WithHistory(revisionRepo.NewMemKVS()), WithTrash(trashRepo.NewMemKVS()), WithClock(func() time.Time { return now }))
+	}
+
+	// actions returns the actions of the given revisions, in order.
+	actions := func(revisions []*domain.Revision) []domain.RevisionAction {
+		actions := make([]domain.RevisionAction, len(revisions))
+		for i, revision := range revisions {
+			actions[i] = revision.Action
+		}
+		return actions
+	}
+
+	t.Run("should record every change with its actor and diff", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		entry.Title = "Ship release"
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+		entry.Done = true
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		history, err := srv.History(alice, entry.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.EqualValues(t, []domain.RevisionAction{domain.RevisionCreated, domain.RevisionUpdated, domain.RevisionUpdated}, actions(history))
+		assert.EqualValues(t, []int{1, 2, 3}, []int{history[0].Number, history[1].Number, history[2].Number})
+		assert.EqualValues(t, "alice", history[1].ActorID)
+		assert.EqualValues(t, now, history[1].At)
+		assert.EqualValues(t, []domain.FieldChange{
+			{Field: "title", From: json.RawMessage(`"Release"`), To: json.RawMessage(`"Ship release"`)},
+		}, history[1].Changes)
+		assert.EqualValues(t, "done", history[2].Changes[0].Field)
+		assert.EqualValues(t, "completed_at", history[2].Changes[1].Field)
+		assert.EqualValues(t, 3, history[2].Entry.Version)
+	})
+
+	t.Run("should not record updates that change nothing", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		history, err := srv.History(alice, entry.ID)
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("should record the changes made to other entries along with an entry", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS(), WithHistory(revisionRepo.NewMemKVS()), WithCompletionRollup(RollupAutomatic))
+		entries := chain(t, srv, 2)
+		entries[1].Done = true
+		require.NoError(t, srv.Update(alice, entries[1].ID, entries[1]))
+
+		history, err := srv.History(alice, entries[0].ID)
+		require.NoError(t, err)
+		if assert.Len(t, history, 2) {
+			assert.EqualValues(t, "done", history[1].Changes[0].Field)
+		}
+	})
+
+	t.Run("should record deletions and restorations", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{}))
+		_, err = srv.Restore(alice, entry.ID)
+		require.NoError(t, err)
+
+		history, err := srv.History(alice, entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, []domain.RevisionAction{domain.RevisionCreated, domain.RevisionDeleted, domain.RevisionRestored}, actions(history))
+		assert.Nil(t, history[1].Entry)
+		assert.Empty(t, history[2].Changes)
+	})
+
+	t.Run("should revert an entry to a past revision", func(t *testing.T) {
+		srv := setUp()
+		due := now.Add(24 * time.Hour)
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release", Priority: domain.PriorityLow, DueAt: &due, Tags: []string{"#work"}})
+		require.NoError(t, err)
+		entry.Title, entry.Priority, entry.DueAt, entry.Tags = "Ship it", domain.PriorityHigh, nil, nil
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		reverted, err := srv.Revert(alice, entry.ID, 1, 0)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Release", reverted.Title)
+		assert.EqualValues(t, domain.PriorityLow, reverted.Priority)
+		assert.EqualValues(t, due, *reverted.DueAt)
+		assert.EqualValues(t, []string{"#work"}, reverted.Tags)
+		assert.EqualValues(t, 3, reverted.Version)
+
+		history, err := srv.History(alice, entry.ID)
+		require.NoError(t, err)
+		assert.Len(t, history, 3)
+	})
+
+	t.Run("should revert entries only at the given version, undoably", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS(), WithHistory(revisionRepo.NewMemKVS()), WithUndo(10))
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		entry.Title = "Ship it"
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		_, err = srv.Revert(alice, entry.ID, 1, entry.Version-1)
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+		reverted, err := srv.Revert(alice, entry.ID, 1, entry.Version)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Release", reverted.Title)
+
+		_, err = srv.Undo(alice)
+		require.NoError(t, err)
+		undone, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Ship it", undone.Title)
+	})
+
+	t.Run("should return errors when reverting to revisions that cannot be applied", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{}))
+		_, err = srv.Restore(alice, entry.ID)
+		require.NoError(t, err)
+
+		_, err = srv.Revert(alice, entry.ID, 2, 0)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		_, err = srv.Revert(alice, entry.ID, 9, 0)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should hide the history of entries from users without access", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Private"})
+		require.NoError(t, err)
+
+		_, err = srv.History(bob, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = srv.Revert(bob, entry.ID, 1, 0)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should record the writes stored before a failure without transactions", func(t *testing.T) {
+		repo := &failingRepository{EntryRepository: entryRepo.NewMemKVS()}
+		srv := New(repo, WithHistory(revisionRepo.NewMemKVS()))
+		entries := chain(t, srv, 3)
+		repo.failing = entries[0].ID
+
+		err := srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true, Permanent: true})
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		for _, entry := range entries[1:] {
+			history, err := srv.revisionRepository.List(entry.ID)
+			require.NoError(t, err)
+			assert.EqualValues(t, []domain.RevisionAction{domain.RevisionCreated, domain.RevisionDeleted}, actions(history))
+		}
+		history, err := srv.revisionRepository.List(entries[0].ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, []domain.RevisionAction{domain.RevisionCreated}, actions(history))
+	})
+
+	t.Run("should record no write of a failed transaction", func(t *testing.T) {
+		db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		repo := &failingAtomicRepository{AtomicEntryRepository: entryRepo.NewBolt(db)}
+		srv := New(repo, WithHistory(revisionRepo.NewMemKVS()))
+		entries := chain(t, srv, 3)
+		repo.failing = entries[0].ID
+
+		err = srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true, Permanent: true})
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		for _, entry := range entries {
+			history, err := srv.revisionRepository.List(entry.ID)
+			require.NoError(t, err)
+			assert.EqualValues(t, []domain.RevisionAction{domain.RevisionCreated}, actions(history))
+		}
+	})
+
+	t.Run("should record changes made to a database holding the history too", func(t *testing.T) {
+		db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		srv := New(entryRepo.NewSQLite(db), WithHistory(revisionRepo.NewSQLite(db)))
+
+		entries := chain(t, srv, 2)
+		require.NoError(t, srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true}))
+
+		history, err := srv.revisionRepository.List(entries[1].ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, []domain.RevisionAction{domain.RevisionCreated, domain.RevisionDeleted}, actions(history))
+	})
+}
@@ -0,0 +1,183 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"time"
+)
+
+// placeInList settles the list of an entry placed below parent, or at the top level when parent
+// is nil. Subtasks take the list of their parent, and naming any other list than the one the
+// entry was in before is rejected. Other entries may be moved to any list the user the context
+// acts for is an editor of. previous is the list the entry belonged to before, empty for new
+// entries.
+func (srv *service) placeInList(ctx context.Context, entry, parent *domain.Entry, previous string) error {
+	if parent != nil {
+		if entry.ListID != previous && entry.ListID != parent.ListID {
+			return domain.NewValidationError("list_id", "subtasks belong to the list of their parent")
+		}
+		entry.ListID = parent.ListID
+		return nil
+	}
+
+	if entry.ListID == "" || entry.ListID == previous || srv.listRepository == nil {
+		return nil
+	}
+	list, err := srv.listRepository.Get(entry.ListID)
+	if err != nil && !isNotFound(err) {
+		return domain.Categorise("retrieving list from repository failed", err)
+	}
+	role := domain.Role("")
+	if err == nil {
+		if role, err = srv.listRole(ctx, list); err != nil {
+			return err
+		}
+	}
+	if role == "" {
+		return domain.NewValidationError("list_id", "list does not exist")
+	}
+	if !role.Includes(domain.RoleEditor) {
+		return domain.Forbidden("requires the editor role on the list")
+	}
+	return nil
+}
+
+// sharedList reports whether listID names a list the user the context acts for may view, in
+// which case listings include the entries of every user in the list.
+func (srv *service) sharedList(ctx context.Context, listID *string) (bool, error) {
+	if listID == nil || *listID == "" || srv.listRepository == nil {
+		return false, nil
+	}
+
+	list, err := srv.listRepository.Get(*listID)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, domain.Categorise("retrieving list from repository failed", err)
+	}
+	role, err := srv.listRole(ctx, list)
+	if err != nil {
+		return false, err
+	}
+	return role.Includes(domain.RoleViewer), nil
+}
+
+// relistSubtasks moves every subtask of the entry with the given UUID to the list with listID.
+func (srv *service) relistSubtasks(repo ports.EntryRepository, id, listID string, now time.Time) error {
+	children, err := srv.children(repo, id)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		child.ListID = listID
+		child.UpdatedAt = now
+		if err := repo.Update(child.ID, child); err != nil {
+			return domain.Categorise("updating subtask in repository failed", err)
+		}
+		if err := srv.relistSubtasks(repo, child.ID, listID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmptyList takes every entry out of the list with the given UUID, as asked by the options of
+// deleting the list: entries are deleted along with their subtasks when cascading, and moved to
+// the list named by ReassignTo otherwise. The user the context acts for must be an editor of
+// every entry, and of the list the entries are moved to. When the repository supports
+// transactions, either every entry is taken out of the list or none is.
+func (srv *service) EmptyList(ctx context.Context, listID string, opts domain.ListDeleteOptions) error {
+	kind := domain.OperationUpdate
+	if opts.Cascade {
+		kind = domain.OperationDelete
+	}
+	return srv.journaled(ctx, kind, "", func(ctx context.Context) error {
+		return srv.emptyList(ctx, listID, opts)
+	})
+}
+
+// emptyList takes every entry out of the list with the given UUID, as by EmptyList.
+func (srv *service) emptyList(ctx context.Context, listID string, opts domain.ListDeleteOptions) error {
+	if _, err := domain.UserID(ctx); err != nil {
+		return err
+	}
+
+	topLevel := ""
+	page, err := srv.entryRepository.List(domain.ListQuery{ListID: &listID, ParentID: &topLevel, SortBy: domain.SortByCreatedAt})
+	if err != nil {
+		return domain.Categorise("listing entries from repository failed", err)
+	}
+	entries := page.Entries
+	if len(entries) == 0 {
+		return nil
+	}
+	if !opts.Cascade && opts.ReassignTo == "" {
+		return domain.Conflict("list still has entries; delete them with cascade or reassign them to another list")
+	}
+	for _, entry := range entries {
+		if err := srv.authorize(ctx, entry, domain.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if opts.ReassignTo != "" {
+		if err := srv.placeInList(ctx, &domain.Entry{ListID: opts.ReassignTo}, nil, listID); err != nil {
+			return err
+		}
+	}
+
+	var trashed []*domain.TrashedEntry
+	if opts.Cascade && srv.trashRepository != nil {
+		for _, entry := range entries {
+			copied, err := srv.copyToTrash(ctx, entry.ID)
+			if err != nil {
+				srv.discardTrashed(trashed)
+				return err
+			}
+			trashed = append(trashed, copied)
+		}
+		if op := operationFrom(ctx); op != nil {
+			op.trashed = true
+		}
+	}
+
+	now := srv.now.Timestamp()
+	if err := srv.atomic(ctx, func(repo ports.EntryRepository) error {
+		for i, entry := range entries {
+			if trashed != nil {
+				if err := srv.checkTrashed(repo, trashed[i]); err != nil {
+					return err
+				}
+			}
+			if opts.Cascade {
+				if err := srv.deleteTree(repo, entry.ID, true); err != nil {
+					return err
+				}
+				continue
+			}
+
+			current, err := repo.Get(entry.ID)
+			if err != nil {
+				return domain.Categorise("retrieving entry from repository failed", err)
+			}
+			if current.ListID != listID {
+				return domain.Conflict("entry changed while emptying the list; try again")
+			}
+			current.ListID = opts.ReassignTo
+			current.UpdatedAt = now
+			if err := repo.Update(current.ID, current); err != nil {
+				return domain.Categorise("updating entry in repository failed", err)
+			}
+			if err := srv.relistSubtasks(repo, current.ID, opts.ReassignTo, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		srv.discardTrashed(trashed)
+		return err
+	}
+	return nil
+}
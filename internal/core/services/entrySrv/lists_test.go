@@ -0,0 +1,166 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/listRepo"
+	"github.com/Nikym/go-todo/internal/repositories/trashRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_Lists(t *testing.T) {
+	setUp := func(t *testing.T) (*service, *domain.List, *domain.List) {
+		lists := listRepo.NewMemKVS()
+		sprint, ops := domain.NewList("Sprint", ""), domain.NewList("Ops", "")
+		sprint.OwnerID, ops.OwnerID = "alice", "alice"
+		require.NoError(t, lists.Save(sprint))
+		require.NoError(t, lists.Save(ops))
+		return New(entryRepo.NewMemKVS(), WithLists(lists)), sprint, ops
+	}
+
+	t.Run("should return validation error when list does not exist", func(t *testing.T) {
+		srv, _, _ := setUp(t)
+
+		_, err := srv.Create(alice, domain.EntryInput{Title: "Deploy", ListID: "missing"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("should return validation error when list is owned by another user", func(t *testing.T) {
+		srv, sprint, _ := setUp(t)
+		bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+
+		_, err := srv.Create(bob, domain.EntryInput{Title: "Deploy", ListID: sprint.ID})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("should place subtasks in the list of their parent", func(t *testing.T) {
+		srv, sprint, ops := setUp(t)
+		parent, err := srv.Create(alice, domain.EntryInput{Title: "Release", ListID: sprint.ID})
+		require.NoError(t, err)
+
+		child, err := srv.Create(alice, domain.EntryInput{Title: "Tag release", ParentID: parent.ID})
+		require.NoError(t, err)
+		assert.EqualValues(t, sprint.ID, child.ListID)
+
+		_, err = srv.Create(alice, domain.EntryInput{Title: "Page on-call", ParentID: parent.ID, ListID: ops.ID})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+
+		child.ListID = ops.ID
+		assert.ErrorIs(t, srv.Update(alice, child.ID, child), domain.ErrValidation)
+	})
+
+	t.Run("should move subtasks along with their parent", func(t *testing.T) {
+		srv, sprint, ops := setUp(t)
+		entries := chain(t, srv, 3)
+
+		entries[0].ListID = sprint.ID
+		require.NoError(t, srv.Update(alice, entries[0].ID, entries[0]))
+		entries[0].ListID = ops.ID
+		require.NoError(t, srv.Update(alice, entries[0].ID, entries[0]))
+
+		for _, entry := range entries {
+			stored, err := srv.Get(alice, entry.ID)
+			require.NoError(t, err)
+			assert.EqualValues(t, ops.ID, stored.ListID)
+		}
+	})
+}
+
+func TestService_EmptyList(t *testing.T) {
+	setUp := func(t *testing.T, repo ports.EntryRepository) (*service, *domain.List, *domain.List, []*domain.Entry) {
+		lists := listRepo.NewMemKVS()
+		sprint, ops := domain.NewList("Sprint", ""), domain.NewList("Ops", "")
+		sprint.OwnerID, ops.OwnerID = "alice", "alice"
+		require.NoError(t, lists.Save(sprint))
+		require.NoError(t, lists.Save(ops))
+		srv := New(repo, WithLists(lists), WithTrash(trashRepo.NewMemKVS()))
+
+		release, err := srv.Create(alice, domain.EntryInput{Title: "Release", ListID: sprint.ID})
+		require.NoError(t, err)
+		notes, err := srv.Create(alice, domain.EntryInput{Title: "Write notes", ParentID: release.ID})
+		require.NoError(t, err)
+		deploy, err := srv.Create(alice, domain.EntryInput{Title: "Deploy", ListID: sprint.ID})
+		require.NoError(t, err)
+		return srv, sprint, ops, []*domain.Entry{release, notes, deploy}
+	}
+	bolt := func(t *testing.T) ports.AtomicEntryRepository {
+		db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return entryRepo.NewBolt(db)
+	}
+
+	t.Run("should move the entries and their subtasks to another list", func(t *testing.T) {
+		srv, sprint, ops, entries := setUp(t, entryRepo.NewMemKVS())
+		require.NoError(t, srv.EmptyList(alice, sprint.ID, domain.ListDeleteOptions{ReassignTo: ops.ID}))
+
+		for _, entry := range entries {
+			stored, err := srv.Get(alice, entry.ID)
+			require.NoError(t, err)
+			assert.EqualValues(t, ops.ID, stored.ListID)
+		}
+	})
+
+	t.Run("should move the entries and their subtasks to the trash when cascading", func(t *testing.T) {
+		srv, sprint, _, entries := setUp(t, entryRepo.NewMemKVS())
+		require.NoError(t, srv.EmptyList(alice, sprint.ID, domain.ListDeleteOptions{Cascade: true}))
+
+		for _, entry := range entries {
+			_, err := srv.Get(alice, entry.ID)
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+		}
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Len(t, trash, 2)
+	})
+
+	t.Run("should return conflict when the list has entries and no option is given", func(t *testing.T) {
+		srv, sprint, _, _ := setUp(t, entryRepo.NewMemKVS())
+		err := srv.EmptyList(alice, sprint.ID, domain.ListDeleteOptions{})
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("should return not found when an entry is owned by another user", func(t *testing.T) {
+		srv, sprint, ops, _ := setUp(t, entryRepo.NewMemKVS())
+		bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+		err := srv.EmptyList(bob, sprint.ID, domain.ListDeleteOptions{ReassignTo: ops.ID})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should move no entry when moving one of them fails", func(t *testing.T) {
+		repo := &failingAtomicRepository{AtomicEntryRepository: bolt(t)}
+		srv, sprint, ops, entries := setUp(t, repo)
+		repo.failing = entries[2].ID
+
+		err := srv.EmptyList(alice, sprint.ID, domain.ListDeleteOptions{ReassignTo: ops.ID})
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		for _, entry := range entries {
+			stored, err := srv.Get(alice, entry.ID)
+			require.NoError(t, err)
+			assert.EqualValues(t, sprint.ID, stored.ListID)
+		}
+	})
+
+	t.Run("should delete no entry when deleting one of them fails", func(t *testing.T) {
+		repo := &failingAtomicRepository{AtomicEntryRepository: bolt(t)}
+		srv, sprint, _, entries := setUp(t, repo)
+		repo.failing = entries[2].ID
+
+		err := srv.EmptyList(alice, sprint.ID, domain.ListDeleteOptions{Cascade: true})
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		for _, entry := range entries {
+			_, err := srv.Get(alice, entry.ID)
+			assert.NoError(t, err)
+		}
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+}
@@ -0,0 +1,92 @@
+package entrySrv
+
+import (
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"time"
+)
+
+// Option configures optional behaviour of the entry service.
+type Option func(srv *service)
+
+// WithClock makes the service read the current time from now instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(srv *service) {
+		srv.now = now
+	}
+}
+
+// WithMaxDepth limits entry hierarchies to the given number of levels.
+func WithMaxDepth(levels int) Option {
+	return func(srv *service) {
+		srv.maxDepth = levels
+	}
+}
+
+// WithCompletionRollup sets how the completion of subtasks affects their parent. The default
+// is RollupRequireChildren.
+func WithCompletionRollup(rollup CompletionRollup) Option {
+	return func(srv *service) {
+		srv.rollup = rollup
+	}
+}
+
+// WithLists makes the service check that the lists entries are placed in exist in the given
+// repository.
+func WithLists(repository ports.ListRepository) Option {
+	return func(srv *service) {
+		srv.listRepository = repository
+	}
+}
+
+// WithAccess makes the service consult the given access service on the role of users, letting
+// them reach the entries shared with them. Without it, users can only reach their own entries.
+func WithAccess(access ports.AccessService) Option {
+	return func(srv *service) {
+		srv.access = access
+	}
+}
+
+// WithTrash makes Delete move entries to the trash kept by the given repository, from which they
+// can be restored until they are purged, instead of removing them right away.
+func WithTrash(repository ports.TrashRepository) Option {
+	return func(srv *service) {
+		srv.trashRepository = repository
+	}
+}
+
+// WithHistory makes the service record every change made to entries as a revision kept by the
+// given repository, from which entries can be reverted.
+func WithHistory(repository ports.RevisionRepository) Option {
+	return func(srv *service) {
+		srv.revisionRepository = repository
+	}
+}
+
+// WithUndo makes the service journal the creations, updates and deletions made by each user, so
+// that the most recent depth of them can be undone and redone. Depths below 1 disable the journal.
+func WithUndo(depth int) Option {
+	return func(srv *service) {
+		srv.journal = nil
+		if depth > 0 {
+			srv.journal = newJournal(depth)
+		}
+	}
+}
+
+// WithEvents makes the service publish the changes made to entries to the given event bus, once
+// they are stored.
+func WithEvents(bus ports.EventBus) Option {
+	return func(srv *service) {
+		srv.events = bus
+		srv.outbox = &outbox{}
+	}
+}
+
+// WithPublishTimeout limits how long publishing the events of a change waits for subscribers
+// without room in their buffer; the events they still have no room for are dropped. The default
+// is DefaultPublishTimeout, and timeouts below or equal to 0 wait without limit.
+func WithPublishTimeout(timeout time.Duration) Option {
+	return func(srv *service) {
+		srv.publishTimeout = timeout
+	}
+}
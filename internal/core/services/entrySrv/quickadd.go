@@ -0,0 +1,444 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse returns the input with the title parsed in quick-add mode, without creating an entry.
+// Words of the title set other fields of the entry, and are removed from the title:
+//
+//   - words starting with '#' or '@' are tags, e.g. #health or @phone;
+//   - !high, !medium, !low and !none, or !1 to !3 from high to low, set the priority;
+//   - a word starting with '+' names the list of the entry, e.g. +Groceries or +"Weekly shop";
+//   - dates and times set the due date, e.g. tomorrow 3pm, friday, next week, in 2 days,
+//     march 5th at 14:30 or 2021-03-05, along with a preceding on, by, due or at.
+//
+// Dates and times are read in the time zone of the service clock. Dates without a time are due
+// at the start of the day, and times without a date are due at their next occurrence. Fields
+// given explicitly in the input take precedence over the ones parsed from the title, and tags
+// are combined.
+func (srv *service) Parse(ctx context.Context, input domain.EntryInput) (*domain.EntryInput, error) {
+	if _, err := domain.UserID(ctx); err != nil {
+		return &domain.EntryInput{}, err
+	}
+
+	parsed := parseQuickAdd(input.Title, srv.now())
+	input.Quick = false
+	input.Title = parsed.title
+	input.Tags = append(input.Tags, parsed.tags...)
+	if input.Priority == domain.PriorityNone && parsed.priority != nil {
+		input.Priority = *parsed.priority
+	}
+	if input.DueAt == nil {
+		input.DueAt = parsed.due
+	}
+	if input.ListID == "" && parsed.list != "" {
+		listID, err := srv.listNamed(ctx, parsed.list)
+		if err != nil {
+			return &domain.EntryInput{}, err
+		}
+		input.ListID = listID
+	}
+
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return &domain.EntryInput{}, err
+	}
+	input.Tags = tags
+	return &input, nil
+}
+
+// listNamed returns the ID of the list with the given name, ignoring case, that the user the
+// context acts for may view, preferring the lists they own.
+func (srv *service) listNamed(ctx context.Context, name string) (string, error) {
+	if srv.listRepository == nil {
+		return "", domain.NewValidationError("list", "no list named "+strconv.Quote(name))
+	}
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	lists, err := srv.listRepository.List()
+	if err != nil {
+		return "", domain.Categorise("listing lists from repository failed", err)
+	}
+	found := ""
+	for _, list := range lists {
+		if !strings.EqualFold(list.Name, name) {
+			continue
+		}
+		if list.OwnerID == ownerID {
+			return list.ID, nil
+		}
+		role, err := srv.listRole(ctx, list)
+		if err != nil {
+			return "", err
+		}
+		if found == "" && role.Includes(domain.RoleViewer) {
+			found = list.ID
+		}
+	}
+	if found == "" {
+		return "", domain.NewValidationError("list", "no list named "+strconv.Quote(name))
+	}
+	return found, nil
+}
+
+// quickAdd holds the fields parsed from a quick-add title.
+type quickAdd struct {
+	title    string
+	tags     []string
+	priority *domain.Priority
+	due      *time.Time
+	list     string
+}
+
+// quickPriorities maps the priority markers of quick-add titles, without their '!', to priorities.
+var quickPriorities = map[string]domain.Priority{
+	"none": domain.PriorityNone, "low": domain.PriorityLow, "medium": domain.PriorityMedium, "high": domain.PriorityHigh,
+	"1": domain.PriorityHigh, "2": domain.PriorityMedium, "3": domain.PriorityLow,
+}
+
+// quickPriority returns the priority marked by word, if it is a priority marker.
+func quickPriority(word string) (domain.Priority, bool) {
+	if !strings.HasPrefix(word, "!") {
+		return domain.PriorityNone, false
+	}
+	priority, ok := quickPriorities[word[1:]]
+	return priority, ok
+}
+
+// parseQuickAdd extracts the fields of an entry from a quick-add title, relative to now.
+func parseQuickAdd(text string, now time.Time) quickAdd {
+	words := splitWords(text)
+	p := &dateParser{now: now, words: make([]string, len(words))}
+	for i, word := range words {
+		p.words[i] = strings.ToLower(word)
+	}
+
+	parsed := quickAdd{}
+	var title []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if priority, ok := quickPriority(p.words[i]); ok {
+			parsed.priority = &priority
+			continue
+		}
+
+		switch {
+		case len(word) > 1 && (word[0] == '#' || word[0] == '@'):
+			parsed.tags = append(parsed.tags, word)
+		case len(word) > 1 && word[0] == '+' && parsed.list == "":
+			parsed.list = strings.Trim(word[1:], `"`)
+		default:
+			if n := p.parse(i); n > 0 {
+				i += n - 1
+				continue
+			}
+			title = append(title, word)
+		}
+	}
+
+	parsed.title = strings.Join(title, " ")
+	parsed.due = p.due()
+	return parsed
+}
+
+// splitWords splits text at whitespace, keeping double quoted runs of words together.
+func splitWords(text string) []string {
+	var words []string
+	var word strings.Builder
+	quoted := false
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			word.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// dateParser recognises the date and time phrases of a quick-add title. A title sets at most
+// one date, or instant, and one time of day; later phrases are left in the title.
+type dateParser struct {
+	now     time.Time
+	words   []string
+	date    *time.Time
+	instant *time.Time
+	clock   *[2]int
+}
+
+var (
+	isoDate      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	dayOfMonth   = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)?$`)
+	clock12      = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	clock12Words = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?$`)
+	clock24      = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April, "jun": time.June,
+	"jul": time.July, "aug": time.August, "sep": time.September, "sept": time.September, "oct": time.October,
+	"nov": time.November, "dec": time.December,
+}
+
+// parse recognises a date or time phrase starting at the word with index i, optionally preceded
+// by a connecting word, and returns the number of words it spans, or zero.
+func (p *dateParser) parse(i int) int {
+	switch p.word(i) {
+	case "on", "by", "due", "at":
+		if n := p.phrase(i + 1); n > 0 {
+			return n + 1
+		}
+		return 0
+	}
+	return p.phrase(i)
+}
+
+func (p *dateParser) word(i int) string {
+	if i < len(p.words) {
+		return p.words[i]
+	}
+	return ""
+}
+
+func (p *dateParser) phrase(i int) int {
+	if p.clock == nil {
+		if n := p.parseClock(i); n > 0 {
+			return n
+		}
+	}
+	if p.date == nil && p.instant == nil {
+		return p.parseDate(i)
+	}
+	return 0
+}
+
+func (p *dateParser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+func (p *dateParser) setDate(date time.Time) {
+	p.date = &date
+}
+
+// parseDate recognises dates and instants relative to now.
+func (p *dateParser) parseDate(i int) int {
+	today := p.today()
+	word := p.word(i)
+
+	switch word {
+	case "today":
+		p.setDate(today)
+		return 1
+	case "tomorrow":
+		p.setDate(today.AddDate(0, 0, 1))
+		return 1
+	case "next":
+		switch next := p.word(i + 1); next {
+		case "week":
+			days := (int(time.Monday) - int(today.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			p.setDate(today.AddDate(0, 0, days))
+			return 2
+		case "month":
+			p.setDate(time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()))
+			return 2
+		default:
+			if _, ok := weekdays[next]; ok {
+				return 1 + p.parseDate(i+1)
+			}
+		}
+		return 0
+	case "in":
+		return p.parseOffset(i)
+	}
+
+	if weekday, ok := weekdays[word]; ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		p.setDate(today.AddDate(0, 0, days))
+		return 1
+	}
+	if isoDate.MatchString(word) {
+		if date, err := time.ParseInLocation("2006-01-02", word, today.Location()); err == nil {
+			p.setDate(date)
+			return 1
+		}
+		return 0
+	}
+
+	// Dates such as 5 march, 5th of march, march 5 and march 5th.
+	if day, ok := parseDay(word); ok {
+		n := 1
+		if p.word(i+n) == "of" {
+			n++
+		}
+		if month, ok := months[p.word(i+n)]; ok {
+			return p.setDayOfMonth(month, day, n+1)
+		}
+	}
+	if month, ok := months[word]; ok {
+		if day, ok := parseDay(p.word(i + 1)); ok {
+			return p.setDayOfMonth(month, day, 2)
+		}
+	}
+	return 0
+}
+
+// setDayOfMonth sets the next date falling on the given day of the given month, returning n,
+// or zero when the day does not exist.
+func (p *dateParser) setDayOfMonth(month time.Month, day, n int) int {
+	today := p.today()
+	date := time.Date(today.Year(), month, day, 0, 0, 0, 0, today.Location())
+	if date.Month() != month {
+		return 0
+	}
+	if date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	p.setDate(date)
+	return n
+}
+
+func parseDay(word string) (int, bool) {
+	m := dayOfMonth.FindStringSubmatch(word)
+	if m == nil {
+		return 0, false
+	}
+	day, _ := strconv.Atoi(m[1])
+	return day, day >= 1 && day <= 31
+}
+
+// parseOffset recognises offsets from now such as in 3 days or in an hour.
+func (p *dateParser) parseOffset(i int) int {
+	count, err := strconv.Atoi(p.word(i + 1))
+	if w := p.word(i + 1); w == "a" || w == "an" {
+		count, err = 1, nil
+	}
+	if err != nil || count < 0 {
+		return 0
+	}
+
+	unit := strings.TrimSuffix(p.word(i+2), "s")
+	var at time.Time
+	switch unit {
+	case "minute", "min":
+		at = p.now.Add(time.Duration(count) * time.Minute)
+	case "hour":
+		at = p.now.Add(time.Duration(count) * time.Hour)
+	case "day":
+		p.setDate(p.today().AddDate(0, 0, count))
+		return 3
+	case "week":
+		p.setDate(p.today().AddDate(0, 0, 7*count))
+		return 3
+	case "month":
+		p.setDate(p.today().AddDate(0, count, 0))
+		return 3
+	default:
+		return 0
+	}
+	p.instant = &at
+	return 3
+}
+
+// parseClock recognises times of day such as 3pm, 3:30 pm, 15:00, noon and midnight.
+func (p *dateParser) parseClock(i int) int {
+	word := p.word(i)
+	switch word {
+	case "noon":
+		p.clock = &[2]int{12, 0}
+		return 1
+	case "midnight":
+		p.clock = &[2]int{0, 0}
+		return 1
+	}
+
+	n, m := 1, clock12.FindStringSubmatch(word)
+	if m == nil {
+		if suffix := p.word(i + 1); suffix == "am" || suffix == "pm" {
+			if m = clock12Words.FindStringSubmatch(word); m != nil {
+				n, m = 2, append(m, suffix)
+			}
+		}
+	}
+	if m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour < 1 || hour > 12 || minute > 59 {
+			return 0
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+		p.clock = &[2]int{hour, minute}
+		return n
+	}
+
+	if m := clock24.FindStringSubmatch(word); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return 0
+		}
+		p.clock = &[2]int{hour, minute}
+		return 1
+	}
+	return 0
+}
+
+// due combines the recognised phrases into the due date, if any.
+func (p *dateParser) due() *time.Time {
+	var due time.Time
+	switch {
+	case p.instant != nil && p.clock == nil:
+		due = *p.instant
+	case p.instant != nil:
+		due = time.Date(p.instant.Year(), p.instant.Month(), p.instant.Day(), p.clock[0], p.clock[1], 0, 0, p.instant.Location())
+	case p.date != nil && p.clock != nil:
+		due = time.Date(p.date.Year(), p.date.Month(), p.date.Day(), p.clock[0], p.clock[1], 0, 0, p.date.Location())
+	case p.date != nil:
+		due = *p.date
+	case p.clock != nil:
+		today := p.today()
+		due = time.Date(today.Year(), today.Month(), today.Day(), p.clock[0], p.clock[1], 0, 0, today.Location())
+		if due.Before(p.now) {
+			due = due.AddDate(0, 0, 1)
+		}
+	default:
+		return nil
+	}
+	due = due.UTC()
+	return &due
+}
@@ -0,0 +1,165 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/listRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// Wednesday morning.
+	now := time.Date(2021, 3, 10, 10, 30, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) *time.Time {
+		t := time.Date(2021, month, day, hour, minute, 0, 0, time.UTC)
+		return &t
+	}
+	high, low := domain.PriorityHigh, domain.PriorityLow
+
+	tests := []struct {
+		name     string
+		text     string
+		expected quickAdd
+	}{
+		{
+			name:     "should parse dates, times, tags and priorities",
+			text:     "Call dentist tomorrow 3pm #health !high",
+			expected: quickAdd{title: "Call dentist", tags: []string{"#health"}, priority: &high, due: at(3, 11, 15, 0)},
+		},
+		{
+			name:     "should parse list names, quoted or not",
+			text:     `Buy milk +"Weekly shop" @errands !3`,
+			expected: quickAdd{title: "Buy milk", tags: []string{"@errands"}, priority: &low, list: "Weekly shop"},
+		},
+		{
+			name:     "should drop connecting words before dates and times",
+			text:     "Submit report by friday at 17:30",
+			expected: quickAdd{title: "Submit report", due: at(3, 12, 17, 30)},
+		},
+		{
+			name:     "should parse the next weekday, skipping today",
+			text:     "Team lunch next wednesday at noon",
+			expected: quickAdd{title: "Team lunch", due: at(3, 17, 12, 0)},
+		},
+		{
+			name:     "should parse days of months in either order",
+			text:     "Renew passport on 5th of april",
+			expected: quickAdd{title: "Renew passport", due: at(4, 5, 0, 0)},
+		},
+		{
+			name:     "should move dates of months that have passed to next year",
+			text:     "Pay taxes march 1st",
+			expected: quickAdd{title: "Pay taxes", due: func() *time.Time { t := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC); return &t }()},
+		},
+		{
+			name:     "should parse ISO dates and times with separate suffixes",
+			text:     "Board meeting 2021-04-01 9:15 am",
+			expected: quickAdd{title: "Board meeting", due: at(4, 1, 9, 15)},
+		},
+		{
+			name:     "should parse offsets from now",
+			text:     "Check the oven in 20 minutes",
+			expected: quickAdd{title: "Check the oven", due: at(3, 10, 10, 50)},
+		},
+		{
+			name:     "should parse offsets in days with a time",
+			text:     "Follow up in 3 days 9am",
+			expected: quickAdd{title: "Follow up", due: at(3, 13, 9, 0)},
+		},
+		{
+			name:     "should move times that have passed to tomorrow",
+			text:     "Stand-up 9:00",
+			expected: quickAdd{title: "Stand-up", due: at(3, 11, 9, 0)},
+		},
+		{
+			name:     "should parse next week as its monday",
+			text:     "Plan sprint next week",
+			expected: quickAdd{title: "Plan sprint", due: at(3, 15, 0, 0)},
+		},
+		{
+			name:     "should keep words that only resemble dates and markers",
+			text:     "Look at it in detail ! + #",
+			expected: quickAdd{title: "Look at it in detail ! + #"},
+		},
+		{
+			name:     "should keep later dates in the title",
+			text:     "Move meeting from today to tomorrow",
+			expected: quickAdd{title: "Move meeting from to tomorrow", due: at(3, 10, 0, 0)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualValues(t, test.expected, parseQuickAdd(test.text, now))
+		})
+	}
+}
+
+func TestParseQuickAdd_TimeZone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2021, 3, 10, 22, 0, 0, 0, newYork)
+
+	parsed := parseQuickAdd("Call home tomorrow 8am", now)
+	require.NotNil(t, parsed.due)
+	assert.EqualValues(t, time.Date(2021, 3, 11, 13, 0, 0, 0, time.UTC), *parsed.due)
+}
+
+func TestService_Parse(t *testing.T) {
+	bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+	now := time.Date(2021, 3, 10, 10, 30, 0, 0, time.UTC)
+
+	lists := listRepo.NewMemKVS()
+	require.NoError(t, lists.Save(&domain.List{ID: "groceries", OwnerID: "alice", Name: "Groceries"}))
+	require.NoError(t, lists.Save(&domain.List{ID: "other", OwnerID: "bob", Name: "Errands"}))
+	srv := New(entryRepo.NewMemKVS(), WithLists(lists), WithClock(func() time.Time { return now }))
+
+	t.Run("should create an entry from a quick-add title", func(t *testing.T) {
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Buy milk tomorrow +groceries #Dairy !2", Quick: true})
+		require.NoError(t, err)
+		assert.EqualValues(t, "Buy milk", entry.Title)
+		assert.EqualValues(t, "groceries", entry.ListID)
+		assert.EqualValues(t, []string{"#dairy"}, entry.Tags)
+		assert.EqualValues(t, domain.PriorityMedium, entry.Priority)
+		assert.EqualValues(t, time.Date(2021, 3, 11, 0, 0, 0, 0, time.UTC), *entry.DueAt)
+	})
+
+	t.Run("should prefer explicit fields and combine tags", func(t *testing.T) {
+		due := now.Add(time.Hour)
+		parsed, err := srv.Parse(alice, domain.EntryInput{
+			Title: "Buy bread tomorrow !low #bakery", Priority: domain.PriorityHigh, DueAt: &due, Tags: []string{"@shop"}, Quick: true,
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, "Buy bread", parsed.Title)
+		assert.EqualValues(t, domain.PriorityHigh, parsed.Priority)
+		assert.EqualValues(t, due, *parsed.DueAt)
+		assert.EqualValues(t, []string{"#bakery", "@shop"}, parsed.Tags)
+		assert.False(t, parsed.Quick)
+	})
+
+	t.Run("should leave titles alone outside of quick mode", func(t *testing.T) {
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Read #1 tomorrow"})
+		require.NoError(t, err)
+		assert.EqualValues(t, "Read #1 tomorrow", entry.Title)
+		assert.Nil(t, entry.DueAt)
+	})
+
+	t.Run("should return validation error when the list is unknown to the user", func(t *testing.T) {
+		_, err := srv.Parse(bob, domain.EntryInput{Title: "Buy milk +groceries", Quick: true})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("should return validation error when a tag is invalid", func(t *testing.T) {
+		_, err := srv.Parse(alice, domain.EntryInput{Title: "Buy milk #dairy!", Quick: true})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("should return error when context has no identity", func(t *testing.T) {
+		_, err := srv.Parse(context.Background(), domain.EntryInput{Title: "Buy milk", Quick: true})
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+	})
+}
@@ -0,0 +1,31 @@
+package entrySrv
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"time"
+)
+
+// nextOccurrence returns the entry following the given occurrence of a recurring entry, created
+// at the given time, or nil when the series has ended. The next occurrence is due at the first
+// date the recurrence yields after the due date of the completed one, even when that date has
+// already passed, so late completions do not shift the series.
+func nextOccurrence(entry *domain.Entry, now time.Time) *domain.Entry {
+	due, ok := entry.Recurrence.Next(*entry.DueAt, entry.Occurrence)
+	if !ok {
+		return nil
+	}
+	due = due.UTC()
+
+	next := domain.NewEntry(entry.Title, entry.Description)
+	next.OwnerID = entry.OwnerID
+	next.ParentID = entry.ParentID
+	next.ListID = entry.ListID
+	next.Priority = entry.Priority
+	next.Tags = append([]string(nil), entry.Tags...)
+	next.DueAt = &due
+	next.Recurrence = entry.Recurrence
+	next.Occurrence = entry.Occurrence + 1
+	next.CreatedAt = now
+	next.UpdatedAt = now
+	return next
+}
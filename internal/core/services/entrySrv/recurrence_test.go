@@ -0,0 +1,81 @@
+package entrySrv
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestService_Recurrence(t *testing.T) {
+	now := time.Date(2021, 3, 5, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("should create the next occurrence when a recurring entry is marked done", func(t *testing.T) {
+		repo := entryRepo.NewMemKVS()
+		srv := New(repo, WithClock(func() time.Time { return now }))
+		rule, err := domain.ParseRecurrence("FREQ=WEEKLY;BYDAY=MO,TH")
+		require.NoError(t, err)
+
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Weekly report", Tags: []string{"@work"}, DueAt: &due, Recurrence: rule})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, entry.Occurrence)
+
+		entry.Done = true
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		page, err := repo.List(domain.ListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Entries, 2)
+
+		var completed, next *domain.Entry
+		for _, e := range page.Entries {
+			if e.ID == entry.ID {
+				completed = e
+			} else {
+				next = e
+			}
+		}
+		assert.True(t, completed.Done)
+		assert.Nil(t, completed.Recurrence)
+		assert.False(t, next.Done)
+		assert.EqualValues(t, "Weekly report", next.Title)
+		assert.EqualValues(t, []string{"@work"}, next.Tags)
+		assert.EqualValues(t, time.Date(2021, 3, 4, 9, 0, 0, 0, time.UTC), *next.DueAt)
+		assert.EqualValues(t, rule.String(), next.Recurrence.String())
+		assert.EqualValues(t, 2, next.Occurrence)
+
+		completed.Done = false
+		require.NoError(t, srv.Update(alice, completed.ID, completed))
+		completed.Done = true
+		require.NoError(t, srv.Update(alice, completed.ID, completed))
+		page, err = repo.List(domain.ListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, page.Entries, 2)
+	})
+
+	t.Run("should not create another occurrence when the series has ended", func(t *testing.T) {
+		repo := entryRepo.NewMemKVS()
+		srv := New(repo)
+		rule, err := domain.ParseRecurrence("FREQ=DAILY;COUNT=1")
+		require.NoError(t, err)
+
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Pay rent", DueAt: &due, Recurrence: rule})
+		require.NoError(t, err)
+		entry.Done = true
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		page, err := repo.List(domain.ListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, page.Entries, 1)
+	})
+
+	t.Run("should return validation error when a recurring entry has no due date", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS())
+
+		_, err := srv.Create(alice, domain.EntryInput{Title: "Pay rent", Recurrence: &domain.Recurrence{Freq: domain.FreqMonthly}})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
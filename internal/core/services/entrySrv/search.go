@@ -0,0 +1,77 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"strings"
+)
+
+// Search returns the entries the user the context acts for may view whose title or description
+// contains every word of the query, most relevant first, with the matching words highlighted.
+// The default page size applies when the query has no limit.
+func (srv *service) Search(ctx context.Context, query domain.SearchQuery) ([]*domain.SearchResult, error) {
+	if _, err := domain.UserID(ctx); err != nil {
+		return nil, err
+	}
+
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, domain.NewValidationError("q", "cannot be empty")
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit < 0 || query.Limit > MaxPageSize {
+		return nil, domain.NewValidationError("limit", "must be between 1 and 100")
+	}
+
+	hits, err := srv.search(query.Text)
+	if err != nil {
+		return nil, domain.Categorise("searching entries in repository failed", err)
+	}
+
+	results := []*domain.SearchResult{}
+	for _, hit := range hits {
+		role, err := srv.role(ctx, hit.Entry)
+		if err != nil {
+			return nil, err
+		}
+		if !role.Includes(domain.RoleViewer) {
+			continue
+		}
+
+		results = append(results, domain.NewSearchResult(hit, query.Text))
+		if len(results) == query.Limit {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// search finds the entries matching text through the index of the repository when it keeps one,
+// and by indexing every stored entry otherwise.
+func (srv *service) search(text string) ([]domain.SearchHit, error) {
+	if repo, ok := srv.entryRepository.(ports.SearchableEntryRepository); ok {
+		return repo.Search(text)
+	}
+
+	page, err := srv.entryRepository.List(domain.ListQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	idx := domain.NewTextIndex()
+	entries := map[string]*domain.Entry{}
+	for _, entry := range page.Entries {
+		idx.Add(entry)
+		entries[entry.ID] = entry
+	}
+
+	var hits []domain.SearchHit
+	for _, match := range idx.Search(text) {
+		hits = append(hits, domain.SearchHit{Entry: entries[match.ID], Score: match.Score})
+	}
+	return hits, nil
+}
@@ -0,0 +1,82 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestService_Search(t *testing.T) {
+	bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+
+	repositories := map[string]func() ports.EntryRepository{
+		"indexed": func() ports.EntryRepository {
+			return entryRepo.NewMemKVS()
+		},
+		"unindexed": func() ports.EntryRepository {
+			// Embedding the repository in a struct hides its Search method.
+			return struct{ ports.EntryRepository }{entryRepo.NewMemKVS()}
+		},
+	}
+
+	for name, newRepo := range repositories {
+		t.Run(name, func(t *testing.T) {
+			srv := New(newRepo())
+			for _, input := range []domain.EntryInput{
+				{Title: "Deploy the website", Description: "Run the <release> pipeline once the deploy is approved"},
+				{Title: "Write release notes", Description: "Summarise what was deployed"},
+				{Title: "Buy groceries"},
+			} {
+				_, err := srv.Create(alice, input)
+				require.NoError(t, err)
+			}
+			_, err := srv.Create(bob, domain.EntryInput{Title: "Deploy the backend"})
+			require.NoError(t, err)
+
+			t.Run("should return the entries of the user by relevance with highlights", func(t *testing.T) {
+				results, err := srv.Search(alice, domain.SearchQuery{Text: "deploying"})
+				require.NoError(t, err)
+				require.Len(t, results, 2)
+
+				assert.EqualValues(t, "Deploy the website", results[0].Entry.Title)
+				assert.EqualValues(t, "<mark>Deploy</mark> the website", results[0].Title)
+				assert.EqualValues(t, "Run the &lt;release&gt; pipeline once the <mark>deploy</mark> is approved", results[0].Snippet)
+				assert.EqualValues(t, "Write release notes", results[1].Title)
+				assert.EqualValues(t, "Summarise what was <mark>deployed</mark>", results[1].Snippet)
+				assert.Greater(t, results[0].Score, results[1].Score)
+			})
+
+			t.Run("should return at most limit results", func(t *testing.T) {
+				results, err := srv.Search(alice, domain.SearchQuery{Text: "deploy", Limit: 1})
+				require.NoError(t, err)
+				assert.Len(t, results, 1)
+			})
+
+			t.Run("should return no results when nothing matches", func(t *testing.T) {
+				results, err := srv.Search(alice, domain.SearchQuery{Text: "holiday"})
+				require.NoError(t, err)
+				assert.Empty(t, results)
+				assert.NotNil(t, results)
+			})
+
+			t.Run("should return validation error when query is empty", func(t *testing.T) {
+				_, err := srv.Search(alice, domain.SearchQuery{Text: "  "})
+				assert.ErrorIs(t, err, domain.ErrValidation)
+			})
+
+			t.Run("should return validation error when limit is too large", func(t *testing.T) {
+				_, err := srv.Search(alice, domain.SearchQuery{Text: "deploy", Limit: MaxPageSize + 1})
+				assert.ErrorIs(t, err, domain.ErrValidation)
+			})
+
+			t.Run("should return error when context has no identity", func(t *testing.T) {
+				_, err := srv.Search(context.Background(), domain.SearchQuery{Text: "deploy"})
+				assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+			})
+		})
+	}
+}
@@ -1,60 +1,398 @@
 package entrySrv
 
 import (
+	"context"
 	"errors"
 	"github.com/Nikym/go-todo/internal/core/domain"
 	"github.com/Nikym/go-todo/internal/core/ports"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the number of entries returned by List when no limit is given.
+	DefaultPageSize = 20
+	// MaxPageSize is the largest number of entries List returns in a single page.
+	MaxPageSize = 100
 )
 
 type service struct {
-	entryRepository ports.EntryRepository
+	entryRepository    ports.EntryRepository
+	listRepository     ports.ListRepository
+	trashRepository    ports.TrashRepository
+	revisionRepository ports.RevisionRepository
+	access             ports.AccessService
+	events             ports.EventBus
+	outbox             *outbox
+	publishTimeout     time.Duration
+	journal            *journal
+	now                domain.Clock
+	maxDepth           int
+	rollup             CompletionRollup
 }
 
 // New returns a pointer to a new entry service object.
-func New(repository ports.EntryRepository) *service {
-	return &service{
+func New(repository ports.EntryRepository, opts ...Option) *service {
+	srv := &service{
 		entryRepository: repository,
+		now:             time.Now,
+		maxDepth:        DefaultMaxDepth,
+		rollup:          RollupRequireChildren,
+		publishTimeout:  DefaultPublishTimeout,
 	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv
 }
 
-// Get returns the domain.Entry object with the given UUID.
-func (srv *service) Get(id string) (*domain.Entry, error) {
+// Get returns the domain.Entry object with the given UUID, provided the user the context acts
+// for may view it.
+func (srv *service) Get(ctx context.Context, id string) (*domain.Entry, error) {
+	if _, err := domain.UserID(ctx); err != nil {
+		return &domain.Entry{}, err
+	}
+
 	entry, err := srv.entryRepository.Get(id)
 	if err != nil {
-		return &domain.Entry{}, errors.New("retrieving entry from repository failed")
+		return &domain.Entry{}, domain.Categorise("retrieving entry from repository failed", err)
+	}
+	if err := srv.authorize(ctx, entry, domain.RoleViewer); err != nil {
+		return &domain.Entry{}, err
 	}
 
 	return entry, nil
 }
 
-// Create makes a new domain.Entry object and saves it to the repository.
-func (srv *service) Create(title, description string) (*domain.Entry, error) {
-	if len(title) < 3 {
-		return &domain.Entry{}, errors.New("title must consist of 3 characters or more")
+// Create makes a new domain.Entry object from the given input and saves it to the repository.
+// The entry is owned by the user the context acts for, who must be an editor of the parent and
+// list it is placed in. Quick inputs are parsed as described by Parse first.
+func (srv *service) Create(ctx context.Context, input domain.EntryInput) (*domain.Entry, error) {
+	var entry *domain.Entry
+	err := srv.journaled(ctx, domain.OperationCreate, "", func(ctx context.Context) (err error) {
+		entry, err = srv.create(ctx, input)
+		return err
+	})
+	return entry, err
+}
+
+// create makes and saves the entry described by input, as by Create.
+func (srv *service) create(ctx context.Context, input domain.EntryInput) (*domain.Entry, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+	if input.Quick {
+		parsed, err := srv.Parse(ctx, input)
+		if err != nil {
+			return &domain.Entry{}, err
+		}
+		input = *parsed
 	}
 
-	entry := domain.NewEntry(title, description)
-	if err := srv.entryRepository.Save(entry); err != nil {
-		return &domain.Entry{}, errors.New("saving entry to repository failed")
+	entry := domain.NewEntry(input.Title, input.Description)
+	entry.OwnerID = ownerID
+	entry.Priority = input.Priority
+	entry.DueAt = input.DueAt
+	entry.Tags = input.Tags
+	entry.ParentID = input.ParentID
+	entry.ListID = input.ListID
+	entry.Recurrence = input.Recurrence
+	if entry.Recurrence != nil {
+		entry.Occurrence = 1
+	}
+	if err := validate(entry); err != nil {
+		return &domain.Entry{}, err
+	}
+
+	parent, err := srv.checkPlacement("", entry.ParentID)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+	if err := srv.checkParent(ctx, parent); err != nil {
+		return &domain.Entry{}, err
+	}
+	if err := srv.placeInList(ctx, entry, parent, ""); err != nil {
+		return &domain.Entry{}, err
+	}
+	if err := srv.checkCompletion(entry, parent); err != nil {
+		return &domain.Entry{}, err
+	}
+
+	now := srv.now.Timestamp()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	if err := srv.atomic(ctx, func(repo ports.EntryRepository) error {
+		if err := repo.Save(entry); err != nil {
+			return domain.Categorise("saving entry to repository failed", err)
+		}
+		return nil
+	}); err != nil {
+		return &domain.Entry{}, err
+	}
+
+	if err := srv.rollUp(ctx, entry.ParentID); err != nil {
+		return &domain.Entry{}, err
 	}
 
 	return entry, nil
 }
 
-// Delete removes an Entry (domain.Entry) from the entry repository.
-func (srv *service) Delete(id string) error {
-	if err := srv.entryRepository.Delete(id); err != nil {
+// Delete removes an Entry (domain.Entry) from the entry repository. Entries that do not exist, or
+// that the user the context acts for may not view, are not found. Entries with subtasks are
+// only removed, together with all of their subtasks, when the options ask for a cascade. Only
+// editors may delete an entry, and only while it is at the version named by the options, if any.
+// When the service keeps a trash, the removed entries are moved to it unless the options ask for
+// a permanent deletion.
+func (srv *service) Delete(ctx context.Context, id string, opts domain.DeleteOptions) error {
+	return srv.journaled(ctx, domain.OperationDelete, id, func(ctx context.Context) error {
+		return srv.delete(ctx, id, opts)
+	})
+}
+
+// delete removes the entry with the given UUID, as by Delete.
+func (srv *service) delete(ctx context.Context, id string, opts domain.DeleteOptions) error {
+	entry, err := srv.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := srv.authorize(ctx, entry, domain.RoleEditor); err != nil {
 		return err
 	}
 
-	return nil
+	var trashed *domain.TrashedEntry
+	if srv.trashRepository != nil && !opts.Permanent {
+		if trashed, err = srv.copyToTrash(ctx, id); err != nil {
+			return err
+		}
+		if op := operationFrom(ctx); op != nil {
+			op.trashed = true
+		}
+	}
+	if err := srv.atomic(ctx, func(repo ports.EntryRepository) error {
+		if opts.Version != 0 {
+			current, err := repo.Get(id)
+			if err != nil {
+				return domain.Categorise("retrieving entry from repository failed", err)
+			}
+			if err := checkVersion(current, opts.Version); err != nil {
+				return err
+			}
+		}
+		if trashed != nil {
+			if err := srv.checkTrashed(repo, trashed); err != nil {
+				return err
+			}
+		}
+		return srv.deleteTree(repo, id, opts.Cascade)
+	}); err != nil {
+		if trashed != nil {
+			srv.discardTrashed([]*domain.TrashedEntry{trashed})
+		}
+		return err
+	}
+
+	return srv.rollUp(ctx, entry.ParentID)
 }
 
 // Update the entry with the given UUID to the values of the specified domain.Entry object.
-func (srv *service) Update(id string, entry *domain.Entry) error {
-	if err := srv.entryRepository.Update(id, entry); err != nil {
+// Timestamps are maintained by the service: CompletedAt is set when the entry is marked done
+// and cleared when it is reopened, while any change to them made by the caller is ignored.
+// Moving the entry below another one and changing its completion are subject to the rules
+// of the entry hierarchy, and moving it to another list moves its subtasks along. Completing a
+// recurring entry creates its next occurrence, which takes the recurrence over from the
+// completed entry. Only editors may update an entry, and its owner cannot be changed. The update
+// is based on the version of the given entry, and fails when the entry has changed since; on
+// success, the given entry carries its new version.
+func (srv *service) Update(ctx context.Context, id string, entry *domain.Entry) error {
+	if err := validate(entry); err != nil {
+		return err
+	}
+
+	existing, err := srv.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := srv.authorize(ctx, existing, domain.RoleEditor); err != nil {
+		return err
+	}
+	if err := checkVersion(existing, entry.Version); err != nil {
 		return err
 	}
 
+	return srv.journaled(ctx, domain.OperationUpdate, id, func(ctx context.Context) error {
+		return srv.update(ctx, id, existing, entry)
+	})
+}
+
+// update replaces the existing entry with the given UUID by entry, without checking the role of
+// the user on the entry itself.
+func (srv *service) update(ctx context.Context, id string, existing, entry *domain.Entry) error {
+	entry.OwnerID = existing.OwnerID
+
+	moved := entry.ParentID != existing.ParentID
+	toggled := entry.Done != existing.Done
+	if moved || toggled || entry.ListID != existing.ListID {
+		parent, err := srv.checkPlacement(id, entry.ParentID)
+		if err != nil {
+			return err
+		}
+		if moved {
+			if err := srv.checkParent(ctx, parent); err != nil {
+				return err
+			}
+		}
+		if err := srv.placeInList(ctx, entry, parent, existing.ListID); err != nil {
+			return err
+		}
+		entry.ID = id
+		if err := srv.checkCompletion(entry, parent); err != nil {
+			return err
+		}
+	}
+
+	now := srv.now.Timestamp()
+	entry.CreatedAt = existing.CreatedAt
+	entry.UpdatedAt = now
+	switch {
+	case entry.Done && !existing.Done:
+		entry.CompletedAt = &now
+	case entry.Done:
+		entry.CompletedAt = existing.CompletedAt
+	default:
+		entry.CompletedAt = nil
+	}
+
+	entry.Occurrence = existing.Occurrence
+	if entry.Recurrence != nil && entry.Occurrence == 0 {
+		entry.Occurrence = 1
+	}
+	var next *domain.Entry
+	if entry.Done && !existing.Done && entry.Recurrence != nil {
+		next = nextOccurrence(entry, now)
+		entry.Recurrence = nil
+	}
+
+	if err := srv.atomic(ctx, func(repo ports.EntryRepository) error {
+		if err := repo.Update(id, entry); err != nil {
+			return domain.Categorise("updating entry in repository failed", err)
+		}
+		if next != nil {
+			if err := repo.Save(next); err != nil {
+				return domain.Categorise("saving next occurrence to repository failed", err)
+			}
+		}
+		if entry.ListID != existing.ListID {
+			return srv.relistSubtasks(repo, id, entry.ListID, now)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if moved || toggled {
+		if err := srv.rollUp(ctx, entry.ParentID); err != nil {
+			return err
+		}
+	}
+	if moved {
+		return srv.rollUp(ctx, existing.ParentID)
+	}
+
+	return nil
+}
+
+// List returns a page of the entries of the user the context acts for that match the given
+// query, applying the default sort order and page size when they are not specified. Listing
+// the entries of a list the user may view returns the entries of every user in it.
+func (srv *service) List(ctx context.Context, query domain.ListQuery) (*domain.EntryPage, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+	shared, err := srv.sharedList(ctx, query.ListID)
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+	if !shared {
+		query.OwnerID = ownerID
+	}
+
+	tags, err := normalizeTags(query.Tags)
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+	query.Tags = tags
+
+	if query.SortBy == "" {
+		query.SortBy = domain.SortByID
+	}
+	if !query.SortBy.Valid() {
+		return &domain.EntryPage{}, domain.NewValidationError("sort", "unknown sort field")
+	}
+
+	switch query.Order {
+	case "":
+		query.Order = domain.SortAscending
+	case domain.SortAscending, domain.SortDescending:
+	default:
+		return &domain.EntryPage{}, domain.NewValidationError("order", "must be either asc or desc")
+	}
+
+	if query.Limit == 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit < 0 || query.Limit > MaxPageSize {
+		return &domain.EntryPage{}, domain.NewValidationError("limit", "must be between 1 and 100")
+	}
+
+	page, err := srv.entryRepository.List(query)
+	if err != nil {
+		return &domain.EntryPage{}, domain.Categorise("listing entries from repository failed", err)
+	}
+
+	if page.Entries == nil {
+		page.Entries = []*domain.Entry{}
+	}
+
+	return page, nil
+}
+
+// validate checks the user editable fields of an entry, normalising its tags.
+func validate(entry *domain.Entry) error {
+	if len(entry.Title) < 3 {
+		return domain.NewValidationError("title", "must consist of 3 characters or more")
+	}
+	if !entry.Priority.Valid() {
+		return domain.NewValidationError("priority", "must be one of none, low, medium or high")
+	}
+
+	if entry.Recurrence != nil {
+		if err := entry.Recurrence.Validate(); err != nil {
+			return err
+		}
+		if entry.DueAt == nil {
+			return domain.NewValidationError("recurrence", "requires a due date")
+		}
+	}
+
+	tags, err := normalizeTags(entry.Tags)
+	if err != nil {
+		return err
+	}
+	entry.Tags = tags
 	return nil
 }
+
+// checkVersion returns a precondition failure unless the entry is at the given version.
+func checkVersion(entry *domain.Entry, version int64) error {
+	if entry.Version != version {
+		return domain.PreconditionFailed("entry has changed since version " + strconv.FormatInt(version, 10))
+	}
+	return nil
+}
+
+// isNotFound reports whether err signals a missing entry.
+func isNotFound(err error) bool {
+	return errors.Is(err, domain.ErrNotFound)
+}
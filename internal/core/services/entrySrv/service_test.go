@@ -1,14 +1,21 @@
 package entrySrv
 
 import (
+	"context"
 	"errors"
 	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
 	"github.com/Nikym/go-todo/mocks/core/ports"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
+// alice is the context of the user owning the entries used by the tests.
+var alice = domain.WithIdentity(context.Background(), domain.Identity{UserID: "alice", Username: "alice"})
+
 func TestService_Get(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -16,6 +23,7 @@ func TestService_Get(t *testing.T) {
 		expectedTitle       string
 		expectedDescription string
 		err                 bool
+		kind                error
 	}{
 		{
 			name:                "should return an entry when given valid id",
@@ -29,6 +37,18 @@ func TestService_Get(t *testing.T) {
 			input: "invalid",
 			err:   true,
 		},
+		{
+			name:  "should return not found error when entry is missing",
+			input: "missing",
+			err:   true,
+			kind:  domain.ErrNotFound,
+		},
+		{
+			name:  "should return not found error when entry is owned by another user",
+			input: "bobs",
+			err:   true,
+			kind:  domain.ErrNotFound,
+		},
 	}
 
 	mockEntryRepository := &mocks.EntryRepository{}
@@ -36,6 +56,7 @@ func TestService_Get(t *testing.T) {
 		On("Get", "17beccd2-c5e8-4744-9b5f-98163b4a479d").
 		Return(&domain.Entry{
 			ID:          "17beccd2-c5e8-4744-9b5f-98163b4a479d",
+			OwnerID:     "alice",
 			Title:       "Test Title",
 			Description: "Test Description",
 			Done:        false,
@@ -43,14 +64,25 @@ func TestService_Get(t *testing.T) {
 	mockEntryRepository.
 		On("Get", "invalid").
 		Return(&domain.Entry{}, errors.New("error get"))
+	mockEntryRepository.
+		On("Get", "missing").
+		Return(&domain.Entry{}, domain.NotFound("entry not found"))
+	mockEntryRepository.
+		On("Get", "bobs").
+		Return(&domain.Entry{ID: "bobs", OwnerID: "bob", Title: "Test Title"}, nil)
 
 	service := New(mockEntryRepository)
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actual, err := service.Get(test.input)
+			actual, err := service.Get(alice, test.input)
 			if err != nil {
 				assert.True(t, test.err)
+				if test.kind != nil {
+					assert.ErrorIs(t, err, test.kind)
+				} else {
+					assert.ErrorIs(t, err, domain.ErrInternal)
+				}
 			} else {
 				assert.IsType(t, &domain.Entry{}, actual)
 				assert.Equal(t, test.expectedTitle, actual.Title)
@@ -106,7 +138,7 @@ func TestService_Create(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actual, err := service.Create(test.inputTitle, test.inputDescription)
+			actual, err := service.Create(alice, domain.EntryInput{Title: test.inputTitle, Description: test.inputDescription})
 			if err != nil {
 				assert.True(t, test.err)
 			} else {
@@ -137,6 +169,15 @@ func TestService_Delete(t *testing.T) {
 	}
 
 	mockEntryRepository := &mocks.EntryRepository{}
+	mockEntryRepository.
+		On("Get", "154b07a0-76bd-4f85-83a5-5090cbf46552").
+		Return(&domain.Entry{ID: "154b07a0-76bd-4f85-83a5-5090cbf46552", OwnerID: "alice", Title: "Test Title"}, nil)
+	mockEntryRepository.
+		On("Get", "invalid").
+		Return(&domain.Entry{ID: "invalid", OwnerID: "alice", Title: "Test Title"}, nil)
+	mockEntryRepository.
+		On("List", mock.Anything).
+		Return(&domain.EntryPage{}, nil)
 	mockEntryRepository.
 		On("Delete", "154b07a0-76bd-4f85-83a5-5090cbf46552").
 		Return(nil)
@@ -148,7 +189,7 @@ func TestService_Delete(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := service.Delete(test.input)
+			err := service.Delete(alice, test.input, domain.DeleteOptions{})
 
 			assert.Equal(t, test.err, err != nil)
 		})
@@ -187,6 +228,12 @@ func TestService_Update(t *testing.T) {
 	}
 
 	mockEntryRepository := &mocks.EntryRepository{}
+	mockEntryRepository.
+		On("Get", "154b07a0-76bd-4f85-83a5-5090cbf46552").
+		Return(&domain.Entry{ID: "154b07a0-76bd-4f85-83a5-5090cbf46552", OwnerID: "alice", Title: "Test Title"}, nil)
+	mockEntryRepository.
+		On("Get", "invalid").
+		Return(&domain.Entry{}, domain.NotFound("entry not found"))
 	mockEntryRepository.
 		On("Update", "154b07a0-76bd-4f85-83a5-5090cbf46552", mock.MatchedBy(
 			func(e *domain.Entry) bool { return true },
@@ -202,8 +249,242 @@ func TestService_Update(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := service.Update(test.inputId, test.inputEntry)
+			err := service.Update(alice, test.inputId, test.inputEntry)
+			assert.Equal(t, test.err, err != nil)
+		})
+	}
+}
+
+func TestService_Timestamps(t *testing.T) {
+	created := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	completed := time.Date(2021, 3, 2, 8, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 3, 5, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		existing          *domain.Entry
+		input             *domain.Entry
+		expectedCompleted *time.Time
+	}{
+		{
+			name:              "should set completion time when entry is marked done",
+			existing:          &domain.Entry{ID: "id", OwnerID: "alice", Title: "Title", CreatedAt: created},
+			input:             &domain.Entry{ID: "id", Title: "Title", Done: true},
+			expectedCompleted: &now,
+		},
+		{
+			name:              "should keep completion time when a done entry is edited",
+			existing:          &domain.Entry{ID: "id", OwnerID: "alice", Title: "Title", Done: true, CreatedAt: created, CompletedAt: &completed},
+			input:             &domain.Entry{ID: "id", Title: "Renamed", Done: true, CompletedAt: &now},
+			expectedCompleted: &completed,
+		},
+		{
+			name:              "should clear completion time when entry is reopened",
+			existing:          &domain.Entry{ID: "id", OwnerID: "alice", Title: "Title", Done: true, CreatedAt: created, CompletedAt: &completed},
+			input:             &domain.Entry{ID: "id", Title: "Title", Done: false, CompletedAt: &completed},
+			expectedCompleted: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockEntryRepository := &mocks.EntryRepository{}
+			mockEntryRepository.On("Get", "id").Return(test.existing, nil)
+			mockEntryRepository.On("Update", "id", test.input).Return(nil)
+			mockEntryRepository.On("List", mock.Anything).Return(&domain.EntryPage{}, nil)
+
+			service := New(mockEntryRepository, WithClock(func() time.Time { return now }))
+
+			assert.NoError(t, service.Update(alice, "id", test.input))
+			assert.EqualValues(t, created, test.input.CreatedAt)
+			assert.EqualValues(t, now, test.input.UpdatedAt)
+			assert.EqualValues(t, test.expectedCompleted, test.input.CompletedAt)
+		})
+	}
+
+	t.Run("should set creation and update times on new entries", func(t *testing.T) {
+		mockEntryRepository := &mocks.EntryRepository{}
+		mockEntryRepository.On("Save", mock.Anything).Return(nil)
+
+		service := New(mockEntryRepository, WithClock(func() time.Time { return now }))
+
+		entry, err := service.Create(alice, domain.EntryInput{Title: "Title", Priority: domain.PriorityHigh})
+		assert.NoError(t, err)
+		assert.EqualValues(t, now, entry.CreatedAt)
+		assert.EqualValues(t, now, entry.UpdatedAt)
+		assert.EqualValues(t, domain.PriorityHigh, entry.Priority)
+		assert.Nil(t, entry.CompletedAt)
+	})
+
+	t.Run("should reject entries with an unknown priority", func(t *testing.T) {
+		service := New(&mocks.EntryRepository{})
+
+		_, err := service.Create(alice, domain.EntryInput{Title: "Title", Priority: domain.Priority(42)})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestService_List(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    domain.ListQuery
+		expected domain.ListQuery
+		err      bool
+	}{
+		{
+			name:     "should apply default sort and limit when none given",
+			input:    domain.ListQuery{},
+			expected: domain.ListQuery{OwnerID: "alice", SortBy: domain.SortByID, Order: domain.SortAscending, Limit: DefaultPageSize},
+			err:      false,
+		},
+		{
+			name:     "should pass through valid sort and limit",
+			input:    domain.ListQuery{SortBy: domain.SortByTitle, Order: domain.SortDescending, Limit: 5},
+			expected: domain.ListQuery{OwnerID: "alice", SortBy: domain.SortByTitle, Order: domain.SortDescending, Limit: 5},
+			err:      false,
+		},
+		{
+			name:  "should return error when sort field is unknown",
+			input: domain.ListQuery{SortBy: "colour"},
+			err:   true,
+		},
+		{
+			name:  "should return error when sort order is unknown",
+			input: domain.ListQuery{Order: "sideways"},
+			err:   true,
+		},
+		{
+			name:  "should return error when limit exceeds the maximum page size",
+			input: domain.ListQuery{Limit: MaxPageSize + 1},
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockEntryRepository := &mocks.EntryRepository{}
+			mockEntryRepository.
+				On("List", test.expected).
+				Return(&domain.EntryPage{}, nil)
+
+			service := New(mockEntryRepository)
+
+			page, err := service.List(alice, test.input)
 			assert.Equal(t, test.err, err != nil)
+			if !test.err {
+				assert.NotNil(t, page.Entries)
+				mockEntryRepository.AssertExpectations(t)
+			}
 		})
 	}
 }
+
+func TestService_Ownership(t *testing.T) {
+	bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+	setUp := func(t *testing.T) (*service, *domain.Entry) {
+		srv := New(entryRepo.NewMemKVS())
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Alice's entry"})
+		require.NoError(t, err)
+		return srv, entry
+	}
+
+	t.Run("should make the user creating an entry its owner", func(t *testing.T) {
+		_, entry := setUp(t)
+		assert.EqualValues(t, "alice", entry.OwnerID)
+	})
+
+	t.Run("should return unauthenticated when context carries no identity", func(t *testing.T) {
+		srv, entry := setUp(t)
+
+		_, err := srv.Get(context.Background(), entry.ID)
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+		_, err = srv.Create(context.Background(), domain.EntryInput{Title: "Anonymous"})
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+	})
+
+	t.Run("should not find the entries of another user", func(t *testing.T) {
+		srv, entry := setUp(t)
+
+		_, err := srv.Get(bob, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.ErrorIs(t, srv.Update(bob, entry.ID, entry), domain.ErrNotFound)
+
+		page, err := srv.List(bob, domain.ListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Entries)
+	})
+
+	t.Run("should not delete the entries of another user", func(t *testing.T) {
+		srv, entry := setUp(t)
+
+		err := srv.Delete(bob, entry.ID, domain.DeleteOptions{})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = srv.Get(alice, entry.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not place subtasks below the entries of another user", func(t *testing.T) {
+		srv, entry := setUp(t)
+
+		_, err := srv.Create(bob, domain.EntryInput{Title: "Bob's subtask", ParentID: entry.ID})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("should keep the owner when an entry is updated", func(t *testing.T) {
+		srv, entry := setUp(t)
+
+		entry.OwnerID = "bob"
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+		stored, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "alice", stored.OwnerID)
+	})
+}
+
+func TestService_Versions(t *testing.T) {
+	setUp := func(t *testing.T) (*service, *domain.Entry) {
+		srv := New(entryRepo.NewMemKVS())
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		return srv, entry
+	}
+
+	t.Run("should start at version 1 and increment the version on update", func(t *testing.T) {
+		srv, entry := setUp(t)
+		assert.EqualValues(t, 1, entry.Version)
+
+		entry.Title = "Ship release"
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+		assert.EqualValues(t, 2, entry.Version)
+
+		stored, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, stored.Version)
+	})
+
+	t.Run("should return precondition failed when updating an outdated version", func(t *testing.T) {
+		srv, entry := setUp(t)
+		outdated := *entry
+
+		entry.Title = "Ship release"
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+		outdated.Done = true
+		assert.ErrorIs(t, srv.Update(alice, entry.ID, &outdated), domain.ErrPreconditionFailed)
+
+		stored, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Ship release", stored.Title)
+		assert.False(t, stored.Done)
+	})
+
+	t.Run("should only delete the given version", func(t *testing.T) {
+		srv, entry := setUp(t)
+
+		err := srv.Delete(alice, entry.ID, domain.DeleteOptions{Version: 2})
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{Version: 1}))
+
+		_, err = srv.Get(alice, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
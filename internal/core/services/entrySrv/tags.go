@@ -0,0 +1,116 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"sort"
+)
+
+// normalizeTags normalises every tag, dropping duplicates and sorting the result. It returns
+// nil when no tags are given.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		n, err := domain.NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[n] {
+			seen[n] = true
+			normalized = append(normalized, n)
+		}
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// AddTags adds the given tags to the entry with the given UUID and returns the updated entry.
+func (srv *service) AddTags(ctx context.Context, id string, tags []string) (*domain.Entry, error) {
+	added, err := normalizeTags(tags)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+
+	entry, err := srv.Get(ctx, id)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+
+	entry.Tags = append(entry.Tags, added...)
+	if err := srv.Update(ctx, id, entry); err != nil {
+		return &domain.Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// RemoveTags removes the given tags from the entry with the given UUID and returns the updated
+// entry. Tags the entry does not carry are ignored.
+func (srv *service) RemoveTags(ctx context.Context, id string, tags []string) (*domain.Entry, error) {
+	removed, err := normalizeTags(tags)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+
+	entry, err := srv.Get(ctx, id)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+
+	unwanted := map[string]bool{}
+	for _, tag := range removed {
+		unwanted[tag] = true
+	}
+
+	var kept []string
+	for _, tag := range entry.Tags {
+		if !unwanted[tag] {
+			kept = append(kept, tag)
+		}
+	}
+	entry.Tags = kept
+	if err := srv.Update(ctx, id, entry); err != nil {
+		return &domain.Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// Tags returns every tag carried by the entries of the user the context acts for that match the
+// query, with the number of entries carrying it. Like List, it counts the entries of every user
+// in a list the user may view.
+func (srv *service) Tags(ctx context.Context, query domain.ListQuery) ([]domain.TagCount, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := srv.sharedList(ctx, query.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if !shared {
+		query.OwnerID = ownerID
+	}
+
+	tags, err := normalizeTags(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+	query.Tags = tags
+
+	counts, err := srv.entryRepository.Tags(query)
+	if err != nil {
+		return nil, domain.Categorise("counting tags in repository failed", err)
+	}
+
+	if counts == nil {
+		counts = []domain.TagCount{}
+	}
+	return counts, nil
+}
@@ -0,0 +1,151 @@
+package entrySrv
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/mocks/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "should lower case, trim, dedupe and sort tags",
+			input:    []string{" @Work ", "#Release-2.3", "@work", "errands"},
+			expected: []string{"#release-2.3", "@work", "errands"},
+		},
+		{
+			name:     "should replace inner whitespace with a dash",
+			input:    []string{"Weekly   Review"},
+			expected: []string{"weekly-review"},
+		},
+		{
+			name:     "should return nil when no tags given",
+			input:    []string{},
+			expected: nil,
+		},
+		{
+			name:  "should return error when tag is empty",
+			input: []string{"  "},
+			err:   true,
+		},
+		{
+			name:  "should return error when tag is only a prefix",
+			input: []string{"@"},
+			err:   true,
+		},
+		{
+			name:  "should return error when tag has more than one prefix",
+			input: []string{"@#work"},
+			err:   true,
+		},
+		{
+			name:  "should return error when tag contains invalid characters",
+			input: []string{"work!"},
+			err:   true,
+		},
+		{
+			name:  "should return error when tag is too long",
+			input: []string{strings.Repeat("a", domain.MaxTagLength+1)},
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := normalizeTags(test.input)
+			if test.err {
+				assert.ErrorIs(t, err, domain.ErrValidation)
+				return
+			}
+			assert.NoError(t, err)
+			assert.EqualValues(t, test.expected, actual)
+		})
+	}
+}
+
+func TestService_AddTags(t *testing.T) {
+	mockEntryRepository := &mocks.EntryRepository{}
+	mockEntryRepository.
+		On("Get", "id").
+		Return(func(string) *domain.Entry {
+			return &domain.Entry{ID: "id", OwnerID: "alice", Title: "Title", Tags: []string{"@home"}}
+		}, nil)
+	mockEntryRepository.
+		On("Get", "missing").
+		Return(&domain.Entry{}, domain.NotFound("entry not found"))
+	mockEntryRepository.
+		On("Update", "id", mock.Anything).
+		Return(nil)
+
+	service := New(mockEntryRepository)
+
+	t.Run("should add normalised tags to the entry", func(t *testing.T) {
+		entry, err := service.AddTags(alice, "id", []string{"@Work", "@home"})
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{"@home", "@work"}, entry.Tags)
+	})
+
+	t.Run("should return error when a tag is invalid", func(t *testing.T) {
+		_, err := service.AddTags(alice, "id", []string{"!"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("should return not found error when entry is missing", func(t *testing.T) {
+		_, err := service.AddTags(alice, "missing", []string{"@work"})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestService_RemoveTags(t *testing.T) {
+	mockEntryRepository := &mocks.EntryRepository{}
+	mockEntryRepository.
+		On("Get", "id").
+		Return(func(string) *domain.Entry {
+			return &domain.Entry{ID: "id", OwnerID: "alice", Title: "Title", Tags: []string{"@home", "@work"}}
+		}, nil)
+	mockEntryRepository.
+		On("Update", "id", mock.Anything).
+		Return(nil)
+
+	service := New(mockEntryRepository)
+
+	t.Run("should remove the given tags, matching them after normalisation", func(t *testing.T) {
+		entry, err := service.RemoveTags(alice, "id", []string{" @WORK"})
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{"@home"}, entry.Tags)
+	})
+
+	t.Run("should leave tags untouched when the entry does not carry them", func(t *testing.T) {
+		entry, err := service.RemoveTags(alice, "id", []string{"@elsewhere"})
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{"@home", "@work"}, entry.Tags)
+	})
+}
+
+func TestService_Tags(t *testing.T) {
+	mockEntryRepository := &mocks.EntryRepository{}
+	mockEntryRepository.
+		On("Tags", domain.ListQuery{OwnerID: "alice", Tags: []string{"@work"}}).
+		Return([]domain.TagCount{{Tag: "@work", Count: 2}}, nil)
+	mockEntryRepository.
+		On("Tags", domain.ListQuery{OwnerID: "alice"}).
+		Return(nil, nil)
+
+	service := New(mockEntryRepository)
+
+	counts, err := service.Tags(alice, domain.ListQuery{Tags: []string{"@Work"}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []domain.TagCount{{Tag: "@work", Count: 2}}, counts)
+
+	counts, err = service.Tags(alice, domain.ListQuery{})
+	assert.NoError(t, err)
+	assert.NotNil(t, counts)
+}
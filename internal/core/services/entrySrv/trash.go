@@ -0,0 +1,324 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"sort"
+	"time"
+)
+
+// Trash returns the entries in the trash that the user the context acts for owns or deleted,
+// most recently deleted first.
+func (srv *service) Trash(ctx context.Context) ([]*domain.TrashedEntry, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if srv.trashRepository == nil {
+		return []*domain.TrashedEntry{}, nil
+	}
+
+	entries, err := srv.trashRepository.List()
+	if err != nil {
+		return nil, domain.Categorise("listing entries from trash failed", err)
+	}
+
+	visible := make([]*domain.TrashedEntry, 0, len(entries))
+	for _, entry := range entries {
+		if inTrashOf(entry, userID) {
+			visible = append(visible, entry)
+		}
+	}
+	sort.Slice(visible, func(i, j int) bool {
+		if !visible[i].DeletedAt.Equal(visible[j].DeletedAt) {
+			return visible[i].DeletedAt.After(visible[j].DeletedAt)
+		}
+		return visible[i].ID < visible[j].ID
+	})
+	return visible, nil
+}
+
+// Restore moves the entry with the given UUID out of the trash, along with the subtasks deleted
+// with it, and returns it. The entry is placed back below its parent, or at the top level of its
+// list when the parent no longer exists, and outside of any list when the list no longer exists
+// either. The user the context acts for must be an editor of the parent or list the entry is
+// placed in.
+func (srv *service) Restore(ctx context.Context, id string) (*domain.Entry, error) {
+	trashed, err := srv.trashed(ctx, id)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+	entry := trashed.Entry
+
+	parent, err := srv.restoredParent(entry.ParentID)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+	if parent == nil {
+		entry.ParentID = ""
+		if entry.ListID, err = srv.restoredList(entry.ListID); err != nil {
+			return &domain.Entry{}, err
+		}
+	} else {
+		entry.ListID = parent.ListID
+	}
+	if err := srv.checkParent(ctx, parent); err != nil {
+		return &domain.Entry{}, err
+	}
+	if err := srv.placeInList(ctx, entry, parent, ""); err != nil {
+		return &domain.Entry{}, err
+	}
+	if err := srv.checkCompletion(entry, parent); err != nil {
+		return &domain.Entry{}, err
+	}
+
+	now := srv.now.Timestamp()
+	if err := srv.atomic(ctx, func(repo ports.EntryRepository) error {
+		for _, restored := range append([]*domain.Entry{entry}, trashed.Subtasks...) {
+			restored.ListID = entry.ListID
+			restored.UpdatedAt = now
+			restored.Version++
+			if err := repo.Save(restored); err != nil {
+				return domain.Categorise("saving restored entry to repository failed", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return &domain.Entry{}, err
+	}
+	if err := srv.trashRepository.Delete(id); err != nil {
+		return &domain.Entry{}, domain.Categorise("deleting entry from trash failed", err)
+	}
+
+	if err := srv.rollUp(ctx, entry.ParentID); err != nil {
+		return &domain.Entry{}, err
+	}
+	return entry, nil
+}
+
+// Purge permanently removes the entry with the given UUID, along with the subtasks deleted with
+// it, from the trash.
+func (srv *service) Purge(ctx context.Context, id string) error {
+	if _, err := srv.trashed(ctx, id); err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := srv.trashRepository.Delete(id); err != nil {
+		return domain.Categorise("deleting entry from trash failed", err)
+	}
+	return nil
+}
+
+// EmptyTrash permanently removes every entry returned by Trash.
+func (srv *service) EmptyTrash(ctx context.Context) error {
+	entries, err := srv.Trash(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := srv.trashRepository.Delete(entry.ID); err != nil {
+			return domain.Categorise("deleting entry from trash failed", err)
+		}
+	}
+	return nil
+}
+
+// PurgeTrash permanently removes the entries of every user that were moved to the trash longer
+// than olderThan ago, and returns how many it removed.
+func (srv *service) PurgeTrash(ctx context.Context, olderThan time.Duration) (int, error) {
+	if srv.trashRepository == nil {
+		return 0, nil
+	}
+
+	entries, err := srv.trashRepository.List()
+	if err != nil {
+		return 0, domain.Categorise("listing entries from trash failed", err)
+	}
+
+	cutoff := srv.now.Timestamp().Add(-olderThan)
+	purged := 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		if !entry.DeletedAt.Before(cutoff) {
+			continue
+		}
+		if err := srv.trashRepository.Delete(entry.ID); err != nil {
+			return purged, domain.Categorise("deleting entry from trash failed", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// copyToTrash saves the entry with the given UUID to the trash, along with all of its subtasks,
+// ahead of their deletion, which must then be checked by checkTrashed. A copy of the entry
+// already in the trash is stale, since the entry was restored since, and is replaced.
+func (srv *service) copyToTrash(ctx context.Context, id string) (*domain.TrashedEntry, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := srv.subtree(srv.entryRepository, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := srv.trashRepository.Delete(id); err != nil {
+		return nil, domain.Categorise("deleting stale entry from trash failed", err)
+	}
+	trashed := &domain.TrashedEntry{Entry: entries[0], Subtasks: entries[1:], DeletedAt: srv.now.Timestamp(), DeletedBy: userID}
+	if err := srv.trashRepository.Save(trashed); err != nil {
+		return nil, domain.Categorise("saving entry to trash failed", err)
+	}
+	return trashed, nil
+}
+
+// discardTrashed removes the copies made by copyToTrash of the entries that failed to be deleted
+// and are still stored, as they are stale. Without transactions, some entries of a copy may have
+// been deleted before the failure all the same: their copies are kept, each entry deleted without
+// its parent becoming a trashed entry of its own, along with the subtasks deleted with it.
+func (srv *service) discardTrashed(trashed []*domain.TrashedEntry) {
+	for _, copied := range trashed {
+		entries := append([]*domain.Entry{copied.Entry}, copied.Subtasks...)
+		deleted := map[string]bool{}
+		for _, entry := range entries {
+			// Entries that cannot be retrieved are assumed deleted, so that no copy is lost.
+			if _, err := srv.entryRepository.Get(entry.ID); err != nil {
+				deleted[entry.ID] = true
+			}
+		}
+		if len(deleted) == len(entries) {
+			continue
+		}
+
+		_ = srv.trashRepository.Delete(copied.ID)
+		var kept []*domain.TrashedEntry
+		roots := map[string]*domain.TrashedEntry{}
+		for _, entry := range entries {
+			if !deleted[entry.ID] {
+				continue
+			}
+			if root, ok := roots[entry.ParentID]; ok {
+				root.Subtasks = append(root.Subtasks, entry)
+				roots[entry.ID] = root
+				continue
+			}
+			root := &domain.TrashedEntry{Entry: entry, DeletedAt: copied.DeletedAt, DeletedBy: copied.DeletedBy}
+			roots[entry.ID] = root
+			kept = append(kept, root)
+		}
+		for _, root := range kept {
+			_ = srv.trashRepository.Save(root)
+		}
+	}
+}
+
+// checkTrashed verifies, within the transaction deleting the entries copied to the trash by
+// copyToTrash, that none of them changed since they were copied.
+func (srv *service) checkTrashed(repo ports.EntryRepository, trashed *domain.TrashedEntry) error {
+	entries, err := srv.subtree(repo, trashed.ID)
+	if err != nil {
+		return err
+	}
+
+	versions := map[string]int64{trashed.ID: trashed.Version}
+	for _, subtask := range trashed.Subtasks {
+		versions[subtask.ID] = subtask.Version
+	}
+	changed := len(entries) != len(versions)
+	for _, entry := range entries {
+		if version, ok := versions[entry.ID]; !ok || version != entry.Version {
+			changed = true
+		}
+	}
+	if changed {
+		return domain.Conflict("entry changed while being moved to the trash; try again")
+	}
+	return nil
+}
+
+// subtree returns the entry with the given UUID followed by all of its subtasks, parents before
+// their subtasks.
+func (srv *service) subtree(repo ports.EntryRepository, id string) ([]*domain.Entry, error) {
+	entry, err := repo.Get(id)
+	if err != nil {
+		return nil, domain.Categorise("retrieving entry from repository failed", err)
+	}
+
+	entries := []*domain.Entry{entry}
+	for i := 0; i < len(entries); i++ {
+		children, err := srv.children(repo, entries[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, children...)
+	}
+	return entries, nil
+}
+
+// trashed returns the entry with the given UUID from the trash, provided the user the context
+// acts for owns or deleted it. Other entries are not found.
+func (srv *service) trashed(ctx context.Context, id string) (*domain.TrashedEntry, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.TrashedEntry{}, err
+	}
+	if srv.trashRepository == nil {
+		return &domain.TrashedEntry{}, domain.NotFound("entry not found in trash")
+	}
+
+	trashed, err := srv.trashRepository.Get(id)
+	if err != nil {
+		return &domain.TrashedEntry{}, domain.Categorise("retrieving entry from trash failed", err)
+	}
+	if !inTrashOf(trashed, userID) {
+		return &domain.TrashedEntry{}, domain.NotFound("entry not found in trash")
+	}
+	return trashed, nil
+}
+
+// restoredParent returns the parent with the given UUID of an entry being restored, or nil when
+// the entry has no parent or its parent no longer exists.
+func (srv *service) restoredParent(parentID string) (*domain.Entry, error) {
+	if parentID == "" {
+		return nil, nil
+	}
+
+	if _, err := srv.entryRepository.Get(parentID); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, domain.Categorise("retrieving parent entry from repository failed", err)
+	}
+	return srv.checkPlacement("", parentID)
+}
+
+// restoredList returns listID when the list of a top-level entry being restored still exists, and
+// an empty string otherwise.
+func (srv *service) restoredList(listID string) (string, error) {
+	if listID == "" || srv.listRepository == nil {
+		return listID, nil
+	}
+
+	if _, err := srv.listRepository.Get(listID); err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", domain.Categorise("retrieving list from repository failed", err)
+	}
+	return listID, nil
+}
+
+// inTrashOf reports whether the trashed entry shows in the trash of the user with userID, who
+// must own or have deleted it.
+func inTrashOf(entry *domain.TrashedEntry, userID string) bool {
+	return entry.OwnerID == userID || entry.DeletedBy == userID
+}
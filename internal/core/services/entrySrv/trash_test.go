@@ -0,0 +1,258 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/services/accessSrv"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/grantRepo"
+	"github.com/Nikym/go-todo/internal/repositories/listRepo"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/Nikym/go-todo/internal/repositories/trashRepo"
+	"github.com/Nikym/go-todo/internal/repositories/userRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_Trash(t *testing.T) {
+	bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+	carol := domain.WithIdentity(context.Background(), domain.Identity{UserID: "carol", Username: "carol"})
+
+	// setUp returns a service keeping a trash, with a clock the test can move, over the sprint
+	// list of alice, which carol may edit.
+	setUp := func(t *testing.T) (*service, *time.Time, *domain.List) {
+		users := userRepo.NewMemKVS()
+		for _, username := range []string{"alice", "bob", "carol"} {
+			require.NoError(t, users.Save(&domain.User{ID: username, Username: username}))
+		}
+		lists := listRepo.NewMemKVS()
+		sprint := domain.NewList("Sprint", "")
+		sprint.OwnerID = "alice"
+		require.NoError(t, lists.Save(sprint))
+
+		entries := entryRepo.NewMemKVS()
+		access := accessSrv.New(grantRepo.NewMemKVS(), entries, lists, users)
+		_, err := access.Share(alice, domain.ResourceList, sprint.ID, domain.GrantInput{Username: "carol", Role: domain.RoleEditor})
+		require.NoError(t, err)
+
+		now := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+		srv := New(entries, WithLists(lists), WithAccess(access), WithTrash(trashRepo.NewMemKVS()), WithClock(func() time.Time { return now }))
+		return srv, &now, sprint
+	}
+
+	t.Run("should move deleted entries and their subtasks to the trash", func(t *testing.T) {
+		srv, _, sprint := setUp(t)
+		release, err := srv.Create(alice, domain.EntryInput{Title: "Release", ListID: sprint.ID})
+		require.NoError(t, err)
+		notes, err := srv.Create(alice, domain.EntryInput{Title: "Write notes", ParentID: release.ID})
+		require.NoError(t, err)
+
+		require.NoError(t, srv.Delete(carol, release.ID, domain.DeleteOptions{Cascade: true}))
+		_, err = srv.Get(alice, notes.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		for _, ctx := range []context.Context{alice, carol} {
+			trash, err := srv.Trash(ctx)
+			require.NoError(t, err)
+			if assert.Len(t, trash, 1) {
+				assert.EqualValues(t, release.ID, trash[0].ID)
+				assert.EqualValues(t, "carol", trash[0].DeletedBy)
+				assert.EqualValues(t, time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC), trash[0].DeletedAt)
+				if assert.Len(t, trash[0].Subtasks, 1) {
+					assert.EqualValues(t, notes.ID, trash[0].Subtasks[0].ID)
+				}
+			}
+		}
+
+		trash, err := srv.Trash(bob)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("should restore entries with their subtasks", func(t *testing.T) {
+		srv, _, sprint := setUp(t)
+		release, err := srv.Create(alice, domain.EntryInput{Title: "Release", ListID: sprint.ID})
+		require.NoError(t, err)
+		notes, err := srv.Create(alice, domain.EntryInput{Title: "Write notes", ParentID: release.ID})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, release.ID, domain.DeleteOptions{Cascade: true}))
+
+		restored, err := srv.Restore(alice, release.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, sprint.ID, restored.ListID)
+		assert.EqualValues(t, 2, restored.Version)
+
+		children, err := srv.Children(alice, release.ID)
+		require.NoError(t, err)
+		if assert.Len(t, children, 1) {
+			assert.EqualValues(t, notes.ID, children[0].ID)
+		}
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("should restore subtasks below their parent or at the top level", func(t *testing.T) {
+		srv, _, sprint := setUp(t)
+		release, err := srv.Create(alice, domain.EntryInput{Title: "Release", ListID: sprint.ID})
+		require.NoError(t, err)
+		notes, err := srv.Create(alice, domain.EntryInput{Title: "Write notes", ParentID: release.ID})
+		require.NoError(t, err)
+		tag, err := srv.Create(alice, domain.EntryInput{Title: "Tag release", ParentID: release.ID})
+		require.NoError(t, err)
+
+		require.NoError(t, srv.Delete(alice, notes.ID, domain.DeleteOptions{}))
+		restored, err := srv.Restore(alice, notes.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, release.ID, restored.ParentID)
+
+		require.NoError(t, srv.Delete(alice, tag.ID, domain.DeleteOptions{}))
+		require.NoError(t, srv.Delete(alice, release.ID, domain.DeleteOptions{Cascade: true, Permanent: true}))
+		restored, err = srv.Restore(alice, tag.ID)
+		require.NoError(t, err)
+		assert.Empty(t, restored.ParentID)
+		assert.EqualValues(t, sprint.ID, restored.ListID)
+	})
+
+	t.Run("should restore entries outside of lists that no longer exist", func(t *testing.T) {
+		srv, _, sprint := setUp(t)
+		release, err := srv.Create(alice, domain.EntryInput{Title: "Release", ListID: sprint.ID})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, release.ID, domain.DeleteOptions{}))
+		require.NoError(t, srv.listRepository.Delete(sprint.ID))
+
+		restored, err := srv.Restore(alice, release.ID)
+		require.NoError(t, err)
+		assert.Empty(t, restored.ListID)
+	})
+
+	t.Run("should delete entries right away when asked to", func(t *testing.T) {
+		srv, _, _ := setUp(t)
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Scratch"})
+		require.NoError(t, err)
+
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{Permanent: true}))
+		_, err = srv.Restore(alice, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should keep entries with subtasks out of the trash without cascade", func(t *testing.T) {
+		srv, _, _ := setUp(t)
+		entries := chain(t, srv, 2)
+
+		assert.ErrorIs(t, srv.Delete(alice, entries[0].ID, domain.DeleteOptions{}), domain.ErrConflict)
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("should hide the trash of other users", func(t *testing.T) {
+		srv, _, _ := setUp(t)
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Private"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{}))
+
+		_, err = srv.Restore(bob, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		require.NoError(t, srv.Purge(bob, entry.ID))
+
+		_, err = srv.Restore(alice, entry.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should purge entries for good", func(t *testing.T) {
+		srv, _, _ := setUp(t)
+		for _, title := range []string{"First", "Second", "Third"} {
+			entry, err := srv.Create(alice, domain.EntryInput{Title: title})
+			require.NoError(t, err)
+			require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{}))
+		}
+
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		require.Len(t, trash, 3)
+		require.NoError(t, srv.Purge(alice, trash[0].ID))
+		_, err = srv.Restore(alice, trash[0].ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		require.NoError(t, srv.EmptyTrash(alice))
+		trash, err = srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("should purge the entries kept in the trash for too long", func(t *testing.T) {
+		srv, now, _ := setUp(t)
+		old, err := srv.Create(alice, domain.EntryInput{Title: "Old"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, old.ID, domain.DeleteOptions{}))
+		*now = now.Add(48 * time.Hour)
+		recent, err := srv.Create(bob, domain.EntryInput{Title: "Recent"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(bob, recent.ID, domain.DeleteOptions{}))
+
+		*now = now.Add(time.Hour)
+		purged, err := srv.PurgeTrash(context.Background(), 24*time.Hour)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, purged)
+
+		_, err = srv.Restore(alice, old.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = srv.Restore(bob, recent.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should move entries to a trash kept in the same database", func(t *testing.T) {
+		db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		srv := New(entryRepo.NewSQLite(db), WithTrash(trashRepo.NewSQLite(db)))
+		entries := chain(t, srv, 2)
+
+		require.NoError(t, srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true}))
+		restored, err := srv.Restore(alice, entries[0].ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, entries[0].Title, restored.Title)
+	})
+
+	t.Run("should keep the subtasks deleted before a cascade failed in the trash", func(t *testing.T) {
+		repo := &failingRepository{EntryRepository: entryRepo.NewMemKVS()}
+		srv := New(repo, WithTrash(trashRepo.NewMemKVS()))
+		entries := chain(t, srv, 3)
+
+		// Subtasks are deleted before their parents, so only the top entry is left.
+		repo.failing = entries[0].ID
+		err := srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true})
+		assert.ErrorIs(t, err, domain.ErrInternal)
+
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		assert.EqualValues(t, entries[1].ID, trash[0].ID)
+		if assert.Len(t, trash[0].Subtasks, 1) {
+			assert.EqualValues(t, entries[2].ID, trash[0].Subtasks[0].ID)
+		}
+
+		restored, err := srv.Restore(alice, entries[1].ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, entries[0].ID, restored.ParentID)
+		_, err = srv.Get(alice, entries[2].ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return an empty trash when the service keeps none", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS())
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Scratch"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{}))
+
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+		_, err = srv.Restore(alice, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
@@ -0,0 +1,357 @@
+package entrySrv
+
+import (
+	"context"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"sync"
+	"time"
+)
+
+// Undo reverts the most recent creation, update or deletion made by the user the context acts for
+// that was not undone yet, and returns it. Entries removed by the operation are taken out of the
+// trash again. The operation can be redone until the user makes another one. Operations that can
+// no longer be undone, since the entries they changed have been changed again, are dropped from
+// the journal with a conflict.
+func (srv *service) Undo(ctx context.Context) (*domain.Operation, error) {
+	return srv.replay(ctx, "undo")
+}
+
+// Redo makes the operation most recently undone by Undo again, and returns it.
+func (srv *service) Redo(ctx context.Context) (*domain.Operation, error) {
+	return srv.replay(ctx, "redo")
+}
+
+// replay reverts the last operation on the undo or redo stack of the user the context acts for,
+// named by verb, and pushes the operation reverting it onto the other stack.
+func (srv *service) replay(ctx context.Context, verb string) (*domain.Operation, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.Operation{}, err
+	}
+	if srv.journal == nil {
+		return &domain.Operation{}, domain.Conflict("nothing to " + verb)
+	}
+
+	stacks := srv.journal.stacks(userID)
+	stacks.mu.Lock()
+	defer stacks.mu.Unlock()
+
+	from, to := &stacks.undo, &stacks.redo
+	if verb == "redo" {
+		from, to = to, from
+	}
+	if len(*from) == 0 {
+		return &domain.Operation{}, domain.Conflict("nothing to " + verb)
+	}
+	op := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+
+	inverse, err := srv.revert(ctx, op, verb)
+	if err != nil {
+		// Only failures of the service itself leave a chance of succeeding later on.
+		if errors.Is(err, domain.ErrInternal) {
+			*from = append(*from, op)
+		}
+		return &domain.Operation{}, err
+	}
+	*to = srv.journal.push(*to, inverse)
+
+	described := &domain.Operation{Kind: inverse.kind, EntryID: inverse.entryID, At: inverse.at, Entries: []*domain.Entry{}}
+	for _, s := range collapse(inverse.steps) {
+		if s.after != nil {
+			described.Entries = append(described.Entries, s.after)
+		}
+	}
+	return described, nil
+}
+
+// revert changes every entry changed by op back to its state before op, in a single
+// transaction, and returns the operation doing so. Reverting fails with a conflict when any field
+// of the entries changed since op.
+func (srv *service) revert(ctx context.Context, op *operation, verb string) (*operation, error) {
+	steps := collapse(op.steps)
+	if err := srv.authorizeOperation(ctx, op, steps); err != nil {
+		return nil, err
+	}
+
+	trashed, err := srv.trashRemoved(ctx, op, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	inverse := &operation{kind: op.kind, entryID: op.entryID, at: op.at, trashed: op.trashed}
+	now := srv.now.Timestamp()
+	if err := srv.atomic(context.WithValue(ctx, operationKey{}, inverse), func(repo ports.EntryRepository) error {
+		for i := len(steps) - 1; i >= 0; i-- {
+			if err := revertStep(repo, steps[i], now, verb); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		srv.discardTrashed(trashed)
+		return nil, err
+	}
+
+	if op.trashed && srv.trashRepository != nil {
+		for _, s := range steps {
+			if s.after != nil {
+				continue
+			}
+			if err := srv.trashRepository.Delete(s.before.ID); err != nil {
+				return nil, domain.Categorise("deleting entry from trash failed", err)
+			}
+		}
+	}
+	return inverse, nil
+}
+
+// authorizeOperation checks that the user the context acts for is an editor of the entry op was
+// made to, as it is now or, when it no longer exists, as it was last seen by op.
+func (srv *service) authorizeOperation(ctx context.Context, op *operation, steps []step) error {
+	entry, err := srv.entryRepository.Get(op.entryID)
+	if err != nil {
+		if !isNotFound(err) {
+			return domain.Categorise("retrieving entry from repository failed", err)
+		}
+		for _, s := range steps {
+			if s.before != nil && s.before.ID == op.entryID {
+				entry = s.before
+			}
+			if s.after != nil && s.after.ID == op.entryID {
+				entry = s.after
+			}
+		}
+	}
+	return srv.authorize(ctx, entry, domain.RoleEditor)
+}
+
+// trashRemoved moves the entries that reverting op removes to the trash, ahead of their removal,
+// provided op moved the entries it removed to the trash as well.
+func (srv *service) trashRemoved(ctx context.Context, op *operation, steps []step) ([]*domain.TrashedEntry, error) {
+	if !op.trashed || srv.trashRepository == nil {
+		return nil, nil
+	}
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := map[string]*domain.Entry{}
+	for _, s := range steps {
+		if s.before == nil {
+			removed[s.after.ID] = s.after
+		}
+	}
+	subtasks := map[string][]*domain.Entry{}
+	for _, s := range steps {
+		if s.before == nil && removed[s.after.ParentID] != nil {
+			subtasks[s.after.ParentID] = append(subtasks[s.after.ParentID], s.after)
+		}
+	}
+
+	var trashed []*domain.TrashedEntry
+	for _, s := range steps {
+		if s.before != nil || removed[s.after.ParentID] != nil {
+			continue
+		}
+		tree := []*domain.Entry{s.after}
+		for i := 0; i < len(tree); i++ {
+			tree = append(tree, subtasks[tree[i].ID]...)
+		}
+
+		if err := srv.trashRepository.Delete(s.after.ID); err != nil {
+			return trashed, domain.Categorise("deleting stale entry from trash failed", err)
+		}
+		entry := &domain.TrashedEntry{Entry: tree[0], Subtasks: tree[1:], DeletedAt: srv.now.Timestamp(), DeletedBy: userID}
+		if err := srv.trashRepository.Save(entry); err != nil {
+			return trashed, domain.Categorise("saving entry to trash failed", err)
+		}
+		trashed = append(trashed, entry)
+	}
+	return trashed, nil
+}
+
+// revertStep changes the entry changed by s back to its state before s, provided its fields are
+// still as they were after s.
+func revertStep(repo ports.EntryRepository, s step, now time.Time, verb string) error {
+	id := s.id()
+	current, err := repo.Get(id)
+	if err != nil && !isNotFound(err) {
+		return domain.Categorise("retrieving entry from repository failed", err)
+	}
+	if err != nil {
+		current = nil
+	}
+	// Undoing and redoing other operations changes versions, so entries are compared by content.
+	changes, err := domain.Diff(current, s.after)
+	if err != nil {
+		return err
+	}
+	if (current == nil) != (s.after == nil) || len(changes) > 0 {
+		return domain.Conflict("entry " + id + " changed since the operation was made; it can no longer be " + verb + "ne")
+	}
+
+	reverted, err := copyEntry(s.before)
+	if err != nil {
+		return err
+	}
+	switch {
+	case s.before == nil:
+		if err := repo.Delete(id); err != nil {
+			return domain.Categorise("deleting entry from repository failed", err)
+		}
+	case s.after == nil:
+		reverted.UpdatedAt = now
+		reverted.Version++
+		if err := repo.Save(reverted); err != nil {
+			return domain.Categorise("saving entry to repository failed", err)
+		}
+	default:
+		reverted.UpdatedAt = now
+		reverted.Version = current.Version
+		if err := repo.Update(id, reverted); err != nil {
+			return domain.Categorise("updating entry in repository failed", err)
+		}
+	}
+	return nil
+}
+
+// journaled runs fn as an operation of the given kind made to the entry with the given UUID, and
+// records it in the journal of the user the context acts for once fn made any change. Operations
+// made while running another one are part of the other one.
+func (srv *service) journaled(ctx context.Context, kind domain.OperationKind, id string, fn func(ctx context.Context) error) error {
+	if srv.journal == nil || operationFrom(ctx) != nil {
+		return fn(ctx)
+	}
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	op := &operation{kind: kind, entryID: id, at: srv.now.Timestamp()}
+	err = fn(context.WithValue(ctx, operationKey{}, op))
+	if len(op.steps) > 0 {
+		// The changes made before any failure were stored, and can be undone as well.
+		if op.entryID == "" {
+			op.entryID = op.steps[0].id()
+		}
+		srv.journal.record(userID, op)
+	}
+	return err
+}
+
+// step is a change made to an entry, from its state before to its state after the change. The
+// state before is nil for created entries, and the state after for removed ones.
+type step struct {
+	before *domain.Entry
+	after  *domain.Entry
+}
+
+// id returns the UUID of the entry changed by s.
+func (s step) id() string {
+	if s.after != nil {
+		return s.after.ID
+	}
+	return s.before.ID
+}
+
+// collapse combines the steps changing the same entry into a single one, from the state of the
+// entry before the first to its state after the last, in the order of their last change. Entries
+// both created and removed by the steps are left out.
+func collapse(steps []step) []step {
+	index := map[string]int{}
+	var collapsed []step
+	for _, s := range steps {
+		i, ok := index[s.id()]
+		if !ok {
+			index[s.id()] = len(collapsed)
+			collapsed = append(collapsed, s)
+			continue
+		}
+		s.before = collapsed[i].before
+		collapsed[i].before, collapsed[i].after = nil, nil
+		index[s.id()] = len(collapsed)
+		collapsed = append(collapsed, s)
+	}
+
+	result := make([]step, 0, len(collapsed))
+	for _, s := range collapsed {
+		if s.before != nil || s.after != nil {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// operation is a creation, update or deletion made to the entry with entryID, recorded as the
+// steps it took. Trashed tells whether the entries it removed were moved to the trash.
+type operation struct {
+	kind    domain.OperationKind
+	entryID string
+	at      time.Time
+	trashed bool
+	steps   []step
+}
+
+type operationKey struct{}
+
+// operationFrom returns the operation being journaled by the context, if any.
+func operationFrom(ctx context.Context) *operation {
+	op, _ := ctx.Value(operationKey{}).(*operation)
+	return op
+}
+
+// journal keeps the operations of every user that can be undone or redone, up to depth of each.
+type journal struct {
+	depth int
+	mu    sync.Mutex
+	users map[string]*operationStacks
+}
+
+// operationStacks holds the operations of a single user, most recent last. Its lock is held while
+// any of them is undone or redone.
+type operationStacks struct {
+	mu   sync.Mutex
+	undo []*operation
+	redo []*operation
+}
+
+func newJournal(depth int) *journal {
+	return &journal{depth: depth, users: map[string]*operationStacks{}}
+}
+
+// stacks returns the operations of the user with userID.
+func (j *journal) stacks(userID string) *operationStacks {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stacks, ok := j.users[userID]
+	if !ok {
+		stacks = &operationStacks{}
+		j.users[userID] = stacks
+	}
+	return stacks
+}
+
+// record adds a new operation of the user with userID, which can no longer redo the operations
+// undone before.
+func (j *journal) record(userID string, op *operation) {
+	stacks := j.stacks(userID)
+	stacks.mu.Lock()
+	defer stacks.mu.Unlock()
+
+	stacks.undo = j.push(stacks.undo, op)
+	stacks.redo = nil
+}
+
+// push appends op to ops, dropping the oldest operations beyond the depth of the journal.
+func (j *journal) push(ops []*operation, op *operation) []*operation {
+	ops = append(ops, op)
+	if len(ops) > j.depth {
+		ops = append([]*operation(nil), ops[len(ops)-j.depth:]...)
+	}
+	return ops
+}
@@ -0,0 +1,294 @@
+package entrySrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/revisionRepo"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/Nikym/go-todo/internal/repositories/trashRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestService_Undo(t *testing.T) {
+	bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+
+	setUp := func() *service {
+		return New(entryRepo.NewMemKVS(), WithTrash(trashRepo.NewMemKVS()), WithUndo(10))
+	}
+
+	t.Run("should undo and redo an update", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		entry.Done = true
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+
+		op, err := srv.Undo(alice)
+		require.NoError(t, err)
+		assert.EqualValues(t, domain.OperationUpdate, op.Kind)
+		assert.EqualValues(t, entry.ID, op.EntryID)
+		require.Len(t, op.Entries, 1)
+		assert.False(t, op.Entries[0].Done)
+		assert.Nil(t, op.Entries[0].CompletedAt)
+		assert.EqualValues(t, 3, op.Entries[0].Version)
+
+		op, err = srv.Redo(alice)
+		require.NoError(t, err)
+		assert.EqualValues(t, domain.OperationUpdate, op.Kind)
+
+		stored, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.Done)
+		assert.NotNil(t, stored.CompletedAt)
+		assert.EqualValues(t, 4, stored.Version)
+	})
+
+	t.Run("should undo and redo a creation", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+
+		op, err := srv.Undo(alice)
+		require.NoError(t, err)
+		assert.EqualValues(t, domain.OperationCreate, op.Kind)
+		assert.EqualValues(t, entry.ID, op.EntryID)
+		assert.Empty(t, op.Entries)
+		_, err = srv.Get(alice, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = srv.Redo(alice)
+		require.NoError(t, err)
+		stored, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Release", stored.Title)
+	})
+
+	t.Run("should take undone deletions out of the trash and put redone ones back", func(t *testing.T) {
+		srv := setUp()
+		entries := chain(t, srv, 3)
+		require.NoError(t, srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true}))
+
+		op, err := srv.Undo(alice)
+		require.NoError(t, err)
+		assert.EqualValues(t, domain.OperationDelete, op.Kind)
+		assert.Len(t, op.Entries, 3)
+		children, err := srv.Children(alice, entries[1].ID)
+		require.NoError(t, err)
+		assert.Len(t, children, 1)
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+
+		_, err = srv.Redo(alice)
+		require.NoError(t, err)
+		_, err = srv.Get(alice, entries[2].ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		trash, err = srv.Trash(alice)
+		require.NoError(t, err)
+		if assert.Len(t, trash, 1) {
+			assert.EqualValues(t, entries[0].ID, trash[0].ID)
+			assert.Len(t, trash[0].Subtasks, 2)
+		}
+	})
+
+	t.Run("should not move entries to the trash when redoing a permanent deletion", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{Permanent: true}))
+
+		_, err = srv.Undo(alice)
+		require.NoError(t, err)
+		_, err = srv.Redo(alice)
+		require.NoError(t, err)
+
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("should revert the changes rolled up to parents along with an update", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS(), WithCompletionRollup(RollupAutomatic), WithUndo(10))
+		entries := chain(t, srv, 2)
+		entries[1].Done = true
+		require.NoError(t, srv.Update(alice, entries[1].ID, entries[1]))
+		parent, err := srv.Get(alice, entries[0].ID)
+		require.NoError(t, err)
+		require.True(t, parent.Done)
+
+		op, err := srv.Undo(alice)
+		require.NoError(t, err)
+		assert.Len(t, op.Entries, 2)
+		parent, err = srv.Get(alice, entries[0].ID)
+		require.NoError(t, err)
+		assert.False(t, parent.Done)
+	})
+
+	t.Run("should undo operations in reverse order up to the depth of the journal", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS(), WithUndo(2))
+		var ids []string
+		for _, title := range []string{"First", "Second", "Third"} {
+			entry, err := srv.Create(alice, domain.EntryInput{Title: title})
+			require.NoError(t, err)
+			ids = append(ids, entry.ID)
+		}
+
+		op, err := srv.Undo(alice)
+		require.NoError(t, err)
+		assert.EqualValues(t, ids[2], op.EntryID)
+		op, err = srv.Undo(alice)
+		require.NoError(t, err)
+		assert.EqualValues(t, ids[1], op.EntryID)
+		_, err = srv.Undo(alice)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		_, err = srv.Get(alice, ids[0])
+		assert.NoError(t, err)
+	})
+
+	t.Run("should undo a sequence of different operations to the same entry", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		entry.Done = true
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{}))
+
+		for _, kind := range []domain.OperationKind{domain.OperationDelete, domain.OperationUpdate, domain.OperationCreate} {
+			op, err := srv.Undo(alice)
+			require.NoError(t, err)
+			assert.EqualValues(t, kind, op.Kind)
+		}
+		_, err = srv.Get(alice, entry.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		for _, kind := range []domain.OperationKind{domain.OperationCreate, domain.OperationUpdate} {
+			op, err := srv.Redo(alice)
+			require.NoError(t, err)
+			assert.EqualValues(t, kind, op.Kind)
+		}
+		stored, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.Done)
+	})
+
+	t.Run("should forget undone operations once another one is made", func(t *testing.T) {
+		srv := setUp()
+		_, err := srv.Create(alice, domain.EntryInput{Title: "First"})
+		require.NoError(t, err)
+		_, err = srv.Undo(alice)
+		require.NoError(t, err)
+		_, err = srv.Create(alice, domain.EntryInput{Title: "Second"})
+		require.NoError(t, err)
+
+		_, err = srv.Redo(alice)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("should keep the operations of every user apart", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+
+		_, err = srv.Undo(bob)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		_, err = srv.Get(alice, entry.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should drop operations whose entries changed since with a conflict", func(t *testing.T) {
+		srv := setUp()
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		entry.Title = "Ship release"
+		require.NoError(t, srv.Update(alice, entry.ID, entry))
+		entry.Title = "Ship release today"
+		require.NoError(t, srv.entryRepository.Update(entry.ID, entry))
+
+		_, err = srv.Undo(alice)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		stored, err := srv.Get(alice, entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Ship release today", stored.Title)
+
+		_, err = srv.Redo(alice)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("should not journal failed operations that changed nothing", func(t *testing.T) {
+		srv := setUp()
+		_, err := srv.Create(alice, domain.EntryInput{Title: "No"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+
+		_, err = srv.Undo(alice)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("should report nothing to undo when the journal is disabled", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS())
+		_, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+
+		_, err = srv.Undo(alice)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("should record undone operations in the history", func(t *testing.T) {
+		srv := New(entryRepo.NewMemKVS(), WithTrash(trashRepo.NewMemKVS()), WithHistory(revisionRepo.NewMemKVS()), WithUndo(10))
+		entry, err := srv.Create(alice, domain.EntryInput{Title: "Release"})
+		require.NoError(t, err)
+		require.NoError(t, srv.Delete(alice, entry.ID, domain.DeleteOptions{}))
+		_, err = srv.Undo(alice)
+		require.NoError(t, err)
+
+		history, err := srv.History(alice, entry.ID)
+		require.NoError(t, err)
+		if assert.Len(t, history, 3) {
+			assert.EqualValues(t, domain.RevisionRestored, history[2].Action)
+		}
+	})
+
+	t.Run("should undo the writes stored before a failure without transactions", func(t *testing.T) {
+		repo := &failingRepository{EntryRepository: entryRepo.NewMemKVS()}
+		srv := New(repo, WithTrash(trashRepo.NewMemKVS()), WithUndo(10))
+		entries := chain(t, srv, 3)
+		repo.failing = entries[0].ID
+
+		err := srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true})
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		_, err = srv.Get(alice, entries[2].ID)
+		require.ErrorIs(t, err, domain.ErrNotFound)
+
+		op, err := srv.Undo(alice)
+		require.NoError(t, err)
+		assert.EqualValues(t, domain.OperationDelete, op.Kind)
+		for _, entry := range entries {
+			_, err := srv.Get(alice, entry.ID)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("should undo deletions with a transactional repository", func(t *testing.T) {
+		db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		srv := New(entryRepo.NewSQLite(db), WithTrash(trashRepo.NewSQLite(db)), WithHistory(revisionRepo.NewSQLite(db)), WithUndo(10))
+		entries := chain(t, srv, 2)
+
+		require.NoError(t, srv.Delete(alice, entries[0].ID, domain.DeleteOptions{Cascade: true}))
+		_, err = srv.Undo(alice)
+		require.NoError(t, err)
+		_, err = srv.Get(alice, entries[1].ID)
+		assert.NoError(t, err)
+
+		_, err = srv.Redo(alice)
+		require.NoError(t, err)
+		trash, err := srv.Trash(alice)
+		require.NoError(t, err)
+		assert.Len(t, trash, 1)
+	})
+}
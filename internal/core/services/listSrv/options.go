@@ -0,0 +1,24 @@
+package listSrv
+
+import (
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"time"
+)
+
+// Option configures optional behaviour of the list service.
+type Option func(srv *service)
+
+// WithClock makes the service read the current time from now instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(srv *service) {
+		srv.now = now
+	}
+}
+
+// WithAccess makes the service consult the given access service on the role of users, letting
+// them reach the lists shared with them. Without it, users can only reach their own lists.
+func WithAccess(access ports.AccessService) Option {
+	return func(srv *service) {
+		srv.access = access
+	}
+}
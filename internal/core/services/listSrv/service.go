@@ -0,0 +1,283 @@
+package listSrv
+
+import (
+	"context"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxNameLength is the maximum number of characters in the name of a list.
+const MaxNameLength = 100
+
+type service struct {
+	listRepository ports.ListRepository
+	entryService   ports.EntryService
+	access         ports.AccessService
+	now            domain.Clock
+}
+
+// New returns a pointer to a new list service object, which manages the entries of its lists
+// through the given entry service.
+func New(repository ports.ListRepository, entryService ports.EntryService, opts ...Option) *service {
+	srv := &service{
+		listRepository: repository,
+		entryService:   entryService,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv
+}
+
+// Get returns the domain.List object with the given UUID. Lists the user the context acts for
+// may not view are not found.
+func (srv *service) Get(ctx context.Context, id string) (*domain.List, error) {
+	return srv.authorize(ctx, id, domain.RoleViewer)
+}
+
+// Create makes a new domain.List object from the given input and saves it to the repository.
+// The list is owned by the user the context acts for, whose list names are unique, ignoring case.
+func (srv *service) Create(ctx context.Context, input domain.ListInput) (*domain.List, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.List{}, err
+	}
+
+	list := domain.NewList(strings.TrimSpace(input.Name), input.Description)
+	list.OwnerID = ownerID
+	if err := srv.validate(list); err != nil {
+		return &domain.List{}, err
+	}
+
+	now := srv.now.Timestamp()
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	if err := srv.listRepository.Save(list); err != nil {
+		return &domain.List{}, domain.Categorise("saving list to repository failed", err)
+	}
+
+	return list, nil
+}
+
+// Update the list with the given UUID to the values of the specified domain.List object. Only
+// editors may update a list, and its owner cannot be changed.
+func (srv *service) Update(ctx context.Context, id string, list *domain.List) error {
+	existing, err := srv.authorize(ctx, id, domain.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	list.ID = id
+	list.OwnerID = existing.OwnerID
+	list.Name = strings.TrimSpace(list.Name)
+	if err := srv.validate(list); err != nil {
+		return err
+	}
+
+	list.CreatedAt = existing.CreatedAt
+	list.UpdatedAt = srv.now.Timestamp()
+	if err := srv.listRepository.Update(id, list); err != nil {
+		return domain.Categorise("updating list in repository failed", err)
+	}
+
+	return nil
+}
+
+// Delete removes the list with the given UUID. A list that still has entries is only removed
+// when the options either cascade the deletion to its entries or name a list to move them to.
+// Only the owner of a list may delete it. Lists that do not exist, or that the user the context
+// acts for may not view, are not found.
+func (srv *service) Delete(ctx context.Context, id string, opts domain.ListDeleteOptions) error {
+	if opts.Cascade && opts.ReassignTo != "" {
+		return domain.NewValidationError("reassign_to", "cannot be combined with cascade")
+	}
+	if opts.ReassignTo == id {
+		return domain.NewValidationError("reassign_to", "must name another list")
+	}
+
+	if _, err := srv.authorize(ctx, id, domain.RoleOwner); err != nil {
+		return err
+	}
+	if opts.ReassignTo != "" {
+		if _, err := srv.Get(ctx, opts.ReassignTo); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return domain.NewValidationError("reassign_to", "list does not exist")
+			}
+			return err
+		}
+	}
+
+	// The entries are taken out of the list first, so that a failure leaves the list in place.
+	if err := srv.entryService.EmptyList(ctx, id, opts); err != nil {
+		return err
+	}
+
+	if err := srv.listRepository.Delete(id); err != nil {
+		return domain.Categorise("deleting list from repository failed", err)
+	}
+
+	return nil
+}
+
+// List returns every list of the user the context acts for and every list shared with them,
+// ordered by name.
+func (srv *service) List(ctx context.Context) ([]*domain.List, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := srv.owned(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := srv.shared(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lists = append(lists, shared...)
+
+	sort.Slice(lists, func(i, j int) bool {
+		a, b := strings.ToLower(lists[i].Name), strings.ToLower(lists[j].Name)
+		if a != b {
+			return a < b
+		}
+		return lists[i].ID < lists[j].ID
+	})
+	return lists, nil
+}
+
+// Entries returns a page of the entries of the list with the given UUID matching the query.
+func (srv *service) Entries(ctx context.Context, id string, query domain.ListQuery) (*domain.EntryPage, error) {
+	if _, err := srv.Get(ctx, id); err != nil {
+		return &domain.EntryPage{}, err
+	}
+
+	query.ListID = &id
+	return srv.entryService.List(ctx, query)
+}
+
+// Move places the entry with the given UUID, along with its subtasks, in the list with listID,
+// or in no list when listID is empty. Subtasks cannot be moved on their own.
+func (srv *service) Move(ctx context.Context, entryID, listID string) (*domain.Entry, error) {
+	if listID != "" {
+		if _, err := srv.Get(ctx, listID); err != nil {
+			return &domain.Entry{}, err
+		}
+	}
+
+	entry, err := srv.entryService.Get(ctx, entryID)
+	if err != nil {
+		return &domain.Entry{}, err
+	}
+	if entry.ParentID != "" {
+		return &domain.Entry{}, domain.NewValidationError("entry_id", "subtasks move together with their parent")
+	}
+
+	entry.ListID = listID
+	if err := srv.entryService.Update(ctx, entryID, entry); err != nil {
+		return &domain.Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// validate checks the user editable fields of a list, and that its name is not used by another
+// list of its owner.
+func (srv *service) validate(list *domain.List) error {
+	if list.Name == "" {
+		return domain.NewValidationError("name", "cannot be empty")
+	}
+	if len([]rune(list.Name)) > MaxNameLength {
+		return domain.NewValidationError("name", "must consist of 100 characters or fewer")
+	}
+
+	lists, err := srv.owned(list.OwnerID)
+	if err != nil {
+		return err
+	}
+	for _, other := range lists {
+		if other.ID != list.ID && strings.EqualFold(other.Name, list.Name) {
+			return domain.Conflict("a list with the same name already exists")
+		}
+	}
+	return nil
+}
+
+// owned returns every list of the user with ownerID, in no particular order.
+func (srv *service) owned(ownerID string) ([]*domain.List, error) {
+	lists, err := srv.listRepository.List()
+	if err != nil {
+		return nil, domain.Categorise("listing lists from repository failed", err)
+	}
+
+	owned := lists[:0]
+	for _, list := range lists {
+		if list.OwnerID == ownerID {
+			owned = append(owned, list)
+		}
+	}
+	return owned, nil
+}
+
+// shared returns every list shared with the user the context acts for, in no particular order.
+func (srv *service) shared(ctx context.Context) ([]*domain.List, error) {
+	if srv.access == nil {
+		return nil, nil
+	}
+
+	grants, err := srv.access.Shared(ctx, domain.ResourceList)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]*domain.List, 0, len(grants))
+	for _, grant := range grants {
+		list, err := srv.listRepository.Get(grant.ResourceID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return nil, domain.Categorise("retrieving list from repository failed", err)
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+// authorize returns the list with the given UUID, provided the user the context acts for has at
+// least the required role on it. Lists the user has no access to are not found.
+func (srv *service) authorize(ctx context.Context, id string, required domain.Role) (*domain.List, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.List{}, err
+	}
+
+	list, err := srv.listRepository.Get(id)
+	if err != nil {
+		return &domain.List{}, domain.Categorise("retrieving list from repository failed", err)
+	}
+
+	role := domain.Role("")
+	switch {
+	case srv.access != nil:
+		if role, err = srv.access.ListRole(ctx, list); err != nil {
+			return &domain.List{}, err
+		}
+	case list.OwnerID == ownerID:
+		role = domain.RoleOwner
+	}
+	if role == "" {
+		return &domain.List{}, domain.NotFound("list not found in repository")
+	}
+	if !role.Includes(required) {
+		return &domain.List{}, domain.Forbidden("requires the " + string(required) + " role on the list")
+	}
+
+	return list, nil
+}
@@ -0,0 +1,226 @@
+package listSrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/mocks/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+// alice is the context of the user owning the lists used by the tests.
+var alice = domain.WithIdentity(context.Background(), domain.Identity{UserID: "alice", Username: "alice"})
+
+func TestService_Create(t *testing.T) {
+	tests := []struct {
+		name  string
+		input domain.ListInput
+		kind  error
+	}{
+		{
+			name:  "should create a list when name is valid",
+			input: domain.ListInput{Name: "  Groceries ", Description: "Weekly shop"},
+		},
+		{
+			name:  "should create a list when name is only taken by a list of another user",
+			input: domain.ListInput{Name: "Ops"},
+		},
+		{
+			name:  "should return validation error when name is empty",
+			input: domain.ListInput{Name: "   "},
+			kind:  domain.ErrValidation,
+		},
+		{
+			name:  "should return conflict when name is taken by another list",
+			input: domain.ListInput{Name: "SPRINT"},
+			kind:  domain.ErrConflict,
+		},
+	}
+
+	now := time.Date(2021, 3, 5, 8, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockListRepository := &mocks.ListRepository{}
+			mockListRepository.On("List").Return([]*domain.List{
+				{ID: "sprint", OwnerID: "alice", Name: "Sprint"},
+				{ID: "ops", OwnerID: "bob", Name: "Ops"},
+			}, nil)
+			mockListRepository.On("Save", mock.Anything).Return(nil)
+
+			service := New(mockListRepository, &mocks.EntryService{}, WithClock(func() time.Time { return now }))
+
+			list, err := service.Create(alice, test.input)
+			if test.kind != nil {
+				assert.ErrorIs(t, err, test.kind)
+				mockListRepository.AssertNotCalled(t, "Save", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.EqualValues(t, strings.TrimSpace(test.input.Name), list.Name)
+			assert.EqualValues(t, "alice", list.OwnerID)
+			assert.EqualValues(t, now, list.CreatedAt)
+			assert.NotEmpty(t, list.ID)
+		})
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		opts    domain.ListDeleteOptions
+		emptied error
+		kind    error
+	}{
+		{
+			name: "should delete a list without entries",
+		},
+		{
+			name: "should return not found when the list does not exist",
+			id:   "missing",
+			kind: domain.ErrNotFound,
+		},
+		{
+			name:    "should return conflict when list has entries and no option is given",
+			emptied: domain.Conflict("list still has entries"),
+			kind:    domain.ErrConflict,
+		},
+		{
+			name: "should delete the entries of the list when cascading",
+			opts: domain.ListDeleteOptions{Cascade: true},
+		},
+		{
+			name: "should move the entries of the list when reassigning",
+			opts: domain.ListDeleteOptions{ReassignTo: "backlog"},
+		},
+		{
+			name:    "should keep the list when taking its entries out fails",
+			opts:    domain.ListDeleteOptions{Cascade: true},
+			emptied: domain.Internal("deleting entry from repository failed", nil),
+			kind:    domain.ErrInternal,
+		},
+		{
+			name: "should return validation error when reassigning to a missing list",
+			opts: domain.ListDeleteOptions{ReassignTo: "missing"},
+			kind: domain.ErrValidation,
+		},
+		{
+			name: "should return validation error when cascading and reassigning",
+			opts: domain.ListDeleteOptions{Cascade: true, ReassignTo: "backlog"},
+			kind: domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockListRepository := &mocks.ListRepository{}
+			mockListRepository.On("Get", "sprint").Return(&domain.List{ID: "sprint", OwnerID: "alice", Name: "Sprint"}, nil)
+			mockListRepository.On("Get", "backlog").Return(&domain.List{ID: "backlog", OwnerID: "alice", Name: "Backlog"}, nil)
+			mockListRepository.On("Get", "missing").Return(&domain.List{}, domain.NotFound("list not found"))
+			mockListRepository.On("Delete", "sprint").Return(nil)
+
+			mockEntryService := &mocks.EntryService{}
+			mockEntryService.On("EmptyList", mock.Anything, "sprint", test.opts).Return(test.emptied)
+
+			service := New(mockListRepository, mockEntryService)
+
+			id := test.id
+			if id == "" {
+				id = "sprint"
+			}
+			err := service.Delete(alice, id, test.opts)
+			if test.kind != nil {
+				assert.ErrorIs(t, err, test.kind)
+				mockListRepository.AssertNotCalled(t, "Delete", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			mockListRepository.AssertCalled(t, "Delete", "sprint")
+			mockEntryService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_Move(t *testing.T) {
+	mockListRepository := &mocks.ListRepository{}
+	mockListRepository.On("Get", "sprint").Return(&domain.List{ID: "sprint", OwnerID: "alice", Name: "Sprint"}, nil)
+	mockListRepository.On("Get", "missing").Return(&domain.List{}, domain.NotFound("list not found"))
+
+	mockEntryService := &mocks.EntryService{}
+	mockEntryService.On("Get", mock.Anything, "a").Return(&domain.Entry{ID: "a", Title: "Plan"}, nil)
+	mockEntryService.On("Get", mock.Anything, "b").Return(&domain.Entry{ID: "b", Title: "Step", ParentID: "a"}, nil)
+	mockEntryService.On("Update", mock.Anything, "a", mock.Anything).Return(nil)
+
+	service := New(mockListRepository, mockEntryService)
+
+	t.Run("should move an entry to the given list", func(t *testing.T) {
+		entry, err := service.Move(alice, "a", "sprint")
+		assert.NoError(t, err)
+		assert.EqualValues(t, "sprint", entry.ListID)
+	})
+
+	t.Run("should return not found when the list does not exist", func(t *testing.T) {
+		_, err := service.Move(alice, "a", "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should return validation error when moving a subtask", func(t *testing.T) {
+		_, err := service.Move(alice, "b", "sprint")
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestService_List(t *testing.T) {
+	mockListRepository := &mocks.ListRepository{}
+	mockListRepository.On("List").Return([]*domain.List{
+		{ID: "1", OwnerID: "alice", Name: "sprint"},
+		{ID: "2", OwnerID: "alice", Name: "Groceries"},
+		{ID: "3", OwnerID: "alice", Name: "Ops"},
+		{ID: "4", OwnerID: "bob", Name: "Bob's list"},
+	}, nil)
+
+	service := New(mockListRepository, &mocks.EntryService{})
+
+	lists, err := service.List(alice)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Groceries", lists[0].Name)
+	assert.EqualValues(t, "Ops", lists[1].Name)
+	assert.EqualValues(t, "sprint", lists[2].Name)
+	assert.Len(t, lists, 3)
+}
+
+func TestService_Shared(t *testing.T) {
+	bob := domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+	sprint := &domain.List{ID: "1", OwnerID: "alice", Name: "sprint"}
+	mockListRepository := &mocks.ListRepository{}
+	mockListRepository.On("List").Return([]*domain.List{
+		sprint,
+		{ID: "4", OwnerID: "bob", Name: "Bob's list"},
+	}, nil)
+	mockListRepository.On("Get", "1").Return(sprint, nil)
+	mockListRepository.On("Get", "missing").Return(&domain.List{}, domain.NotFound("list not found"))
+	mockAccessService := &mocks.AccessService{}
+	mockAccessService.On("ListRole", bob, sprint).Return(domain.RoleEditor, nil)
+	mockAccessService.On("Shared", bob, domain.ResourceList).Return([]*domain.Grant{
+		{Kind: domain.ResourceList, ResourceID: "1", UserID: "bob", Role: domain.RoleEditor},
+		{Kind: domain.ResourceList, ResourceID: "missing", UserID: "bob", Role: domain.RoleViewer},
+	}, nil)
+
+	service := New(mockListRepository, &mocks.EntryService{}, WithAccess(mockAccessService))
+
+	lists, err := service.List(bob)
+	require.NoError(t, err)
+	assert.EqualValues(t, []string{"Bob's list", "sprint"}, []string{lists[0].Name, lists[1].Name})
+	assert.Len(t, lists, 2)
+
+	list, err := service.Get(bob, "1")
+	require.NoError(t, err)
+	assert.EqualValues(t, sprint, list)
+
+	err = service.Delete(bob, "1", domain.ListDeleteOptions{Cascade: true})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
@@ -0,0 +1,92 @@
+package userSrv
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// DefaultLoginTTL is how long a verified username and password are remembered, so that the
+// requests authenticating with them in the meantime do not each pay for a bcrypt comparison.
+const DefaultLoginTTL = time.Minute
+
+// maxLogins bounds the number of verified logins remembered at once.
+const maxLogins = 10000
+
+// logins remembers verified usernames and passwords under a keyed hash, along with the password
+// hash they were verified against. It is safe for concurrent use.
+type logins struct {
+	mu       sync.Mutex
+	key      []byte
+	ttl      time.Duration
+	verified map[[sha256.Size]byte]login
+}
+
+// login is a remembered verification of a password against the password hash of a user.
+type login struct {
+	passwordHash []byte
+	expires      time.Time
+}
+
+func newLogins(ttl time.Duration) *logins {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return &logins{key: key, ttl: ttl, verified: map[[sha256.Size]byte]login{}}
+}
+
+// remembered reports whether password was verified against passwordHash for the user with the
+// given ID less than the TTL before now.
+func (l *logins) remembered(userID, password string, passwordHash []byte, now time.Time) bool {
+	if l.ttl <= 0 {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := l.sum(userID, password)
+	remembered, ok := l.verified[key]
+	if !ok {
+		return false
+	}
+	if !now.Before(remembered.expires) || !bytes.Equal(remembered.passwordHash, passwordHash) {
+		delete(l.verified, key)
+		return false
+	}
+	return true
+}
+
+// remember records that password was verified against passwordHash for the user with the given
+// ID at now.
+func (l *logins) remember(userID, password string, passwordHash []byte, now time.Time) {
+	if l.ttl <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.verified) >= maxLogins {
+		for key, remembered := range l.verified {
+			if !now.Before(remembered.expires) || len(l.verified) >= maxLogins {
+				delete(l.verified, key)
+			}
+		}
+	}
+	l.verified[l.sum(userID, password)] = login{passwordHash: passwordHash, expires: now.Add(l.ttl)}
+}
+
+// sum returns the keyed hash under which the password of the user with the given ID is
+// remembered, which reveals nothing about the password without the key.
+func (l *logins) sum(userID, password string) [sha256.Size]byte {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(userID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(password))
+	var sum [sha256.Size]byte
+	copy(sum[:], mac.Sum(nil))
+	return sum
+}
@@ -0,0 +1,29 @@
+package userSrv
+
+import "time"
+
+// Option configures optional behaviour of the user service.
+type Option func(srv *service)
+
+// WithClock makes the service read the current time from now instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(srv *service) {
+		srv.now = now
+	}
+}
+
+// WithHashCost sets the bcrypt cost used to hash passwords. Lower costs make hashing faster and
+// passwords easier to brute force; they are meant for tests.
+func WithHashCost(cost int) Option {
+	return func(srv *service) {
+		srv.hashCost = cost
+	}
+}
+
+// WithLoginTTL sets how long verified usernames and passwords are remembered, DefaultLoginTTL by
+// default. A TTL of zero compares every password with its hash.
+func WithLoginTTL(ttl time.Duration) Option {
+	return func(srv *service) {
+		srv.logins = newLogins(ttl)
+	}
+}
@@ -0,0 +1,222 @@
+package userSrv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
+)
+
+const (
+	// MinPasswordLength is the minimum number of characters in a password.
+	MinPasswordLength = 8
+	// MaxPasswordLength is the maximum number of bytes in a password, beyond which bcrypt
+	// ignores the input.
+	MaxPasswordLength = 72
+	// MaxTokenNameLength is the maximum number of characters in the name of an API token.
+	MaxTokenNameLength = 100
+	// TokenPrefix starts the secret of every API token, making leaked tokens easy to recognise.
+	TokenPrefix = "todo_"
+)
+
+type service struct {
+	userRepository ports.UserRepository
+	now            domain.Clock
+	hashCost       int
+	logins         *logins
+	compare        func(hash, password []byte) error
+}
+
+// New returns a pointer to a new user service object.
+func New(repository ports.UserRepository, opts ...Option) *service {
+	srv := &service{
+		userRepository: repository,
+		now:            time.Now,
+		hashCost:       bcrypt.DefaultCost,
+		logins:         newLogins(DefaultLoginTTL),
+		compare:        bcrypt.CompareHashAndPassword,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv
+}
+
+// Register creates a new user from the given input. Usernames are stored in lower case and are
+// unique.
+func (srv *service) Register(input domain.UserInput) (*domain.User, error) {
+	username := strings.ToLower(strings.TrimSpace(input.Username))
+	if err := validateUsername(username); err != nil {
+		return &domain.User{}, err
+	}
+	if len([]rune(input.Password)) < MinPasswordLength {
+		return &domain.User{}, domain.NewValidationError("password", "must consist of 8 characters or more")
+	}
+	if len(input.Password) > MaxPasswordLength {
+		return &domain.User{}, domain.NewValidationError("password", "must consist of 72 bytes or fewer")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), srv.hashCost)
+	if err != nil {
+		return &domain.User{}, domain.Internal("hashing password failed", err)
+	}
+
+	user := domain.NewUser(username, hash)
+	user.CreatedAt = srv.now.Timestamp()
+	if err := srv.userRepository.Save(user); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return &domain.User{}, domain.Conflict("username is already taken")
+		}
+		return &domain.User{}, domain.Categorise("saving user to repository failed", err)
+	}
+
+	return user, nil
+}
+
+// Login returns the identity of the user with the given username and password. Passwords verified
+// recently are not compared with their hash again, as bcrypt makes comparing them slow on purpose.
+func (srv *service) Login(username, password string) (domain.Identity, error) {
+	user, err := srv.userRepository.GetByUsername(strings.TrimSpace(username))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Identity{}, domain.Unauthenticated("invalid username or password")
+		}
+		return domain.Identity{}, domain.Categorise("retrieving user from repository failed", err)
+	}
+
+	now := srv.now()
+	if !srv.logins.remembered(user.ID, password, user.PasswordHash, now) {
+		if err := srv.compare(user.PasswordHash, []byte(password)); err != nil {
+			return domain.Identity{}, domain.Unauthenticated("invalid username or password")
+		}
+		srv.logins.remember(user.ID, password, user.PasswordHash, now)
+	}
+
+	return domain.Identity{UserID: user.ID, Username: user.Username}, nil
+}
+
+// Authenticate returns the identity of the user owning the API token with the given secret.
+func (srv *service) Authenticate(secret string) (domain.Identity, error) {
+	if !strings.HasPrefix(secret, TokenPrefix) {
+		return domain.Identity{}, domain.Unauthenticated("invalid API token")
+	}
+
+	token, err := srv.userRepository.GetToken(hashToken(secret))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Identity{}, domain.Unauthenticated("invalid API token")
+		}
+		return domain.Identity{}, domain.Categorise("retrieving token from repository failed", err)
+	}
+
+	user, err := srv.userRepository.Get(token.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Identity{}, domain.Unauthenticated("invalid API token")
+		}
+		return domain.Identity{}, domain.Categorise("retrieving user from repository failed", err)
+	}
+
+	return domain.Identity{UserID: user.ID, Username: user.Username}, nil
+}
+
+// Me returns the user the context acts for.
+func (srv *service) Me(ctx context.Context) (*domain.User, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.User{}, err
+	}
+
+	user, err := srv.userRepository.Get(userID)
+	if err != nil {
+		return &domain.User{}, domain.Categorise("retrieving user from repository failed", err)
+	}
+	return user, nil
+}
+
+// CreateToken creates a new API token with the given name for the user the context acts for.
+// It returns the token along with its secret, which is not stored and cannot be retrieved later.
+func (srv *service) CreateToken(ctx context.Context, name string) (*domain.Token, string, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.Token{}, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if len([]rune(name)) > MaxTokenNameLength {
+		return &domain.Token{}, "", domain.NewValidationError("name", "must consist of 100 characters or fewer")
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return &domain.Token{}, "", domain.Internal("generating token failed", err)
+	}
+	secret := TokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	token := domain.NewToken(userID, name, hashToken(secret))
+	token.CreatedAt = srv.now.Timestamp()
+	if err := srv.userRepository.SaveToken(token); err != nil {
+		return &domain.Token{}, "", domain.Categorise("saving token to repository failed", err)
+	}
+
+	return token, secret, nil
+}
+
+// Tokens returns every API token of the user the context acts for.
+func (srv *service) Tokens(ctx context.Context) ([]*domain.Token, error) {
+	userID, err := domain.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := srv.userRepository.Tokens(userID)
+	if err != nil {
+		return nil, domain.Categorise("listing tokens from repository failed", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken deletes the API token with the given ID, if it belongs to the user the context
+// acts for.
+func (srv *service) RevokeToken(ctx context.Context, id string) error {
+	tokens, err := srv.Tokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.ID == id {
+			if err := srv.userRepository.DeleteToken(id); err != nil {
+				return domain.Categorise("deleting token from repository failed", err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateUsername checks that a lower case username consists of 3 to 32 letters, digits,
+// dots, dashes or underscores.
+func validateUsername(username string) error {
+	if len(username) < 3 || len(username) > 32 {
+		return domain.NewValidationError("username", "must consist of 3 to 32 characters")
+	}
+	for _, r := range username {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+			return domain.NewValidationError("username", "may only contain letters, digits, dots, dashes and underscores")
+		}
+	}
+	return nil
+}
+
+// hashToken returns the hash under which the token with the given secret is stored.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,223 @@
+package userSrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/userRepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newService(t *testing.T) *service {
+	srv := New(userRepo.NewMemKVS(), WithHashCost(bcrypt.MinCost))
+	_, err := srv.Register(domain.UserInput{Username: "alice", Password: "correct horse"})
+	require.NoError(t, err)
+	return srv
+}
+
+func TestService_Register(t *testing.T) {
+	tests := []struct {
+		name  string
+		input domain.UserInput
+		kind  error
+	}{
+		{
+			name:  "should register a user with a lower case username",
+			input: domain.UserInput{Username: " Bob.Smith ", Password: "battery staple"},
+		},
+		{
+			name:  "should return conflict when username is taken ignoring case",
+			input: domain.UserInput{Username: "ALICE", Password: "battery staple"},
+			kind:  domain.ErrConflict,
+		},
+		{
+			name:  "should return validation error when username is too short",
+			input: domain.UserInput{Username: "bo", Password: "battery staple"},
+			kind:  domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when username contains spaces",
+			input: domain.UserInput{Username: "bob smith", Password: "battery staple"},
+			kind:  domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when password is too short",
+			input: domain.UserInput{Username: "bob", Password: "short"},
+			kind:  domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when password is too long",
+			input: domain.UserInput{Username: "bob", Password: strings.Repeat("a", 73)},
+			kind:  domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newService(t)
+
+			user, err := srv.Register(test.input)
+			if test.kind != nil {
+				assert.ErrorIs(t, err, test.kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualValues(t, "bob.smith", user.Username)
+			assert.NotEqualValues(t, test.input.Password, string(user.PasswordHash))
+		})
+	}
+}
+
+func TestService_Login(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		kind     error
+	}{
+		{
+			name:     "should log in with username ignoring case and correct password",
+			username: "Alice",
+			password: "correct horse",
+		},
+		{
+			name:     "should return unauthenticated when password is wrong",
+			username: "alice",
+			password: "wrong horse",
+			kind:     domain.ErrUnauthenticated,
+		},
+		{
+			name:     "should return unauthenticated when user does not exist",
+			username: "mallory",
+			password: "correct horse",
+			kind:     domain.ErrUnauthenticated,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newService(t)
+
+			identity, err := srv.Login(test.username, test.password)
+			if test.kind != nil {
+				assert.ErrorIs(t, err, test.kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualValues(t, "alice", identity.Username)
+			assert.NotEmpty(t, identity.UserID)
+		})
+	}
+}
+
+func TestService_RememberedLogins(t *testing.T) {
+	// setUp returns a service whose clock is read from now and the number of passwords it
+	// compared with their hash so far.
+	setUp := func(t *testing.T, now *time.Time) (*service, *int) {
+		repo := userRepo.NewMemKVS()
+		srv := New(repo, WithHashCost(bcrypt.MinCost), WithClock(func() time.Time { return *now }))
+		_, err := srv.Register(domain.UserInput{Username: "alice", Password: "correct horse"})
+		require.NoError(t, err)
+		compared := 0
+		srv.compare = func(hash, password []byte) error {
+			compared++
+			return bcrypt.CompareHashAndPassword(hash, password)
+		}
+		return srv, &compared
+	}
+
+	t.Run("should not compare a password verified recently again", func(t *testing.T) {
+		now := time.Now()
+		srv, compared := setUp(t, &now)
+		for i := 0; i < 3; i++ {
+			_, err := srv.Login("alice", "correct horse")
+			require.NoError(t, err)
+		}
+		assert.EqualValues(t, 1, *compared)
+
+		now = now.Add(DefaultLoginTTL)
+		_, err := srv.Login("alice", "correct horse")
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, *compared)
+	})
+
+	t.Run("should compare every wrong password", func(t *testing.T) {
+		now := time.Now()
+		srv, compared := setUp(t, &now)
+		_, err := srv.Login("alice", "correct horse")
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			_, err := srv.Login("alice", "wrong horse")
+			assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+		}
+		assert.EqualValues(t, 3, *compared)
+	})
+
+	t.Run("should compare every password without a TTL", func(t *testing.T) {
+		now := time.Now()
+		srv, compared := setUp(t, &now)
+		WithLoginTTL(0)(srv)
+		for i := 0; i < 2; i++ {
+			_, err := srv.Login("alice", "correct horse")
+			require.NoError(t, err)
+		}
+		assert.EqualValues(t, 2, *compared)
+	})
+}
+
+func TestService_Tokens(t *testing.T) {
+	srv := newService(t)
+	identity, err := srv.Login("alice", "correct horse")
+	require.NoError(t, err)
+	ctx := domain.WithIdentity(context.Background(), identity)
+
+	token, secret, err := srv.CreateToken(ctx, "laptop")
+	require.NoError(t, err)
+
+	t.Run("should authenticate with the secret of a token", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(secret, TokenPrefix))
+		assert.NotContains(t, token.Hash, secret)
+
+		authenticated, err := srv.Authenticate(secret)
+		require.NoError(t, err)
+		assert.EqualValues(t, identity, authenticated)
+	})
+
+	t.Run("should return unauthenticated for an unknown secret", func(t *testing.T) {
+		_, err := srv.Authenticate(TokenPrefix + "unknown")
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+	})
+
+	t.Run("should list the tokens of the user", func(t *testing.T) {
+		tokens, err := srv.Tokens(ctx)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.EqualValues(t, "laptop", tokens[0].Name)
+	})
+
+	t.Run("should not revoke the tokens of another user", func(t *testing.T) {
+		bob, err := srv.Register(domain.UserInput{Username: "bob", Password: "battery staple"})
+		require.NoError(t, err)
+		other := domain.WithIdentity(context.Background(), domain.Identity{UserID: bob.ID, Username: bob.Username})
+
+		require.NoError(t, srv.RevokeToken(other, token.ID))
+		_, err = srv.Authenticate(secret)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should no longer authenticate with a revoked token", func(t *testing.T) {
+		require.NoError(t, srv.RevokeToken(ctx, token.ID))
+
+		_, err := srv.Authenticate(secret)
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+	})
+
+	t.Run("should return unauthenticated when context carries no identity", func(t *testing.T) {
+		_, _, err := srv.CreateToken(context.Background(), "laptop")
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+	})
+}
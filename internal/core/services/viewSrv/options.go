@@ -0,0 +1,14 @@
+package viewSrv
+
+import "time"
+
+// Option configures optional behaviour of the view service.
+type Option func(srv *service)
+
+// WithClock makes the service read the current time from now instead of time.Now. Relative
+// times in the queries of views are resolved against it.
+func WithClock(now func() time.Time) Option {
+	return func(srv *service) {
+		srv.now = now
+	}
+}
@@ -0,0 +1,199 @@
+package viewSrv
+
+import (
+	"context"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxNameLength is the maximum number of characters in the name of a view.
+	MaxNameLength = 64
+	// MaxQueryLength is the maximum number of characters in the query of a view.
+	MaxQueryLength = 1000
+)
+
+// namePattern matches the names of views, which appear in URLs.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+type service struct {
+	viewRepository ports.ViewRepository
+	entryService   ports.EntryService
+	now            domain.Clock
+}
+
+// New returns a pointer to a new view service object, which lists the entries of views through
+// the given entry service.
+func New(repository ports.ViewRepository, entryService ports.EntryService, opts ...Option) *service {
+	srv := &service{
+		viewRepository: repository,
+		entryService:   entryService,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv
+}
+
+// Get returns the view of the user the context acts for with the given name, ignoring case.
+func (srv *service) Get(ctx context.Context, name string) (*domain.View, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.View{}, err
+	}
+
+	views, err := srv.owned(ownerID)
+	if err != nil {
+		return &domain.View{}, err
+	}
+	for _, view := range views {
+		if strings.EqualFold(view.Name, name) {
+			return view, nil
+		}
+	}
+	return &domain.View{}, domain.NotFound("view not found in repository")
+}
+
+// Create saves a new view of the user the context acts for from the given input. The names of
+// the views of a user are unique, ignoring case.
+func (srv *service) Create(ctx context.Context, input domain.ViewInput) (*domain.View, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return &domain.View{}, err
+	}
+
+	view := domain.NewView(strings.TrimSpace(input.Name), strings.TrimSpace(input.Query))
+	view.OwnerID = ownerID
+	if err := srv.validate(view); err != nil {
+		return &domain.View{}, err
+	}
+
+	now := srv.now.Timestamp()
+	view.CreatedAt = now
+	view.UpdatedAt = now
+	if err := srv.viewRepository.Save(view); err != nil {
+		return &domain.View{}, domain.Categorise("saving view to repository failed", err)
+	}
+
+	return view, nil
+}
+
+// Update renames the view with the given name and replaces its query with the values of the
+// input. An empty name in the input keeps the name of the view.
+func (srv *service) Update(ctx context.Context, name string, input domain.ViewInput) (*domain.View, error) {
+	existing, err := srv.Get(ctx, name)
+	if err != nil {
+		return &domain.View{}, err
+	}
+
+	view := *existing
+	if input.Name = strings.TrimSpace(input.Name); input.Name != "" {
+		view.Name = input.Name
+	}
+	view.Query = strings.TrimSpace(input.Query)
+	if err := srv.validate(&view); err != nil {
+		return &domain.View{}, err
+	}
+
+	view.UpdatedAt = srv.now.Timestamp()
+	if err := srv.viewRepository.Update(view.ID, &view); err != nil {
+		return &domain.View{}, domain.Categorise("updating view in repository failed", err)
+	}
+
+	return &view, nil
+}
+
+// Delete removes the view with the given name. Deleting a missing view is not an error.
+func (srv *service) Delete(ctx context.Context, name string) error {
+	view, err := srv.Get(ctx, name)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if err := srv.viewRepository.Delete(view.ID); err != nil {
+		return domain.Categorise("deleting view from repository failed", err)
+	}
+	return nil
+}
+
+// List returns every view of the user the context acts for, ordered by name.
+func (srv *service) List(ctx context.Context) ([]*domain.View, error) {
+	ownerID, err := domain.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := srv.owned(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return strings.ToLower(views[i].Name) < strings.ToLower(views[j].Name)
+	})
+	return views, nil
+}
+
+// Entries returns a page of the entries matching both the query of the view with the given name,
+// evaluated at the current time, and the given listing query.
+func (srv *service) Entries(ctx context.Context, name string, query domain.ListQuery) (*domain.EntryPage, error) {
+	view, err := srv.Get(ctx, name)
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+
+	filter, err := domain.ParseFilter(view.Query, srv.now())
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+	query.Filter = filter
+	return srv.entryService.List(ctx, query)
+}
+
+// validate checks the user editable fields of a view, and that its name is not used by another
+// view of its owner.
+func (srv *service) validate(view *domain.View) error {
+	if view.Name == "" {
+		return domain.NewValidationError("name", "cannot be empty")
+	}
+	if len(view.Name) > MaxNameLength {
+		return domain.NewValidationError("name", "must consist of 64 characters or fewer")
+	}
+	if !namePattern.MatchString(view.Name) {
+		return domain.NewValidationError("name", "must consist of letters, digits, '-' and '_' and start with a letter or digit")
+	}
+	if len([]rune(view.Query)) > MaxQueryLength {
+		return domain.NewValidationError("query", "must consist of 1000 characters or fewer")
+	}
+	if _, err := domain.ParseFilter(view.Query, srv.now()); err != nil {
+		return err
+	}
+
+	views, err := srv.owned(view.OwnerID)
+	if err != nil {
+		return err
+	}
+	for _, other := range views {
+		if other.ID != view.ID && strings.EqualFold(other.Name, view.Name) {
+			return domain.Conflict("a view with the same name already exists")
+		}
+	}
+	return nil
+}
+
+// owned returns every view of the user with ownerID, in no particular order.
+func (srv *service) owned(ownerID string) ([]*domain.View, error) {
+	views, err := srv.viewRepository.List(ownerID)
+	if err != nil {
+		return nil, domain.Categorise("listing views from repository failed", err)
+	}
+	return views, nil
+}
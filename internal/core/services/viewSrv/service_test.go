@@ -0,0 +1,190 @@
+package viewSrv
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/services/entrySrv"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo"
+	"github.com/Nikym/go-todo/internal/repositories/viewRepo"
+	"github.com/Nikym/go-todo/mocks/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// alice and bob are the contexts of the users owning the views used by the tests.
+var (
+	alice = domain.WithIdentity(context.Background(), domain.Identity{UserID: "alice", Username: "alice"})
+	bob   = domain.WithIdentity(context.Background(), domain.Identity{UserID: "bob", Username: "bob"})
+)
+
+func TestService_Create(t *testing.T) {
+	tests := []struct {
+		name  string
+		input domain.ViewInput
+		kind  error
+	}{
+		{
+			name:  "should create a view when name and query are valid",
+			input: domain.ViewInput{Name: " this-week ", Query: "done:false due<+1w"},
+		},
+		{
+			name:  "should return validation error when name is empty",
+			input: domain.ViewInput{Name: " ", Query: "done:false"},
+			kind:  domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when name cannot appear in a URL",
+			input: domain.ViewInput{Name: "this week", Query: "done:false"},
+			kind:  domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when query is malformed",
+			input: domain.ViewInput{Name: "broken", Query: "done:false AND (tag:work"},
+			kind:  domain.ErrValidation,
+		},
+		{
+			name:  "should return conflict when name is taken by another view",
+			input: domain.ViewInput{Name: "URGENT", Query: "priority:high"},
+			kind:  domain.ErrConflict,
+		},
+	}
+
+	now := time.Date(2021, 3, 5, 8, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := New(viewRepo.NewMemKVS(), &mocks.EntryService{}, WithClock(func() time.Time { return now }))
+			_, err := service.Create(alice, domain.ViewInput{Name: "urgent", Query: "priority:high"})
+			require.NoError(t, err)
+
+			view, err := service.Create(alice, test.input)
+			if test.kind != nil {
+				assert.ErrorIs(t, err, test.kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualValues(t, "this-week", view.Name)
+			assert.EqualValues(t, "done:false due<+1w", view.Query)
+			assert.EqualValues(t, "alice", view.OwnerID)
+			assert.EqualValues(t, now, view.CreatedAt)
+		})
+	}
+}
+
+func TestService_Views(t *testing.T) {
+	service := New(viewRepo.NewMemKVS(), &mocks.EntryService{})
+	for _, name := range []string{"week", "Urgent"} {
+		_, err := service.Create(alice, domain.ViewInput{Name: name, Query: "done:false"})
+		require.NoError(t, err)
+	}
+	_, err := service.Create(bob, domain.ViewInput{Name: "week", Query: "tag:home"})
+	require.NoError(t, err)
+
+	t.Run("should list the views of the user by name", func(t *testing.T) {
+		views, err := service.List(alice)
+		require.NoError(t, err)
+		require.Len(t, views, 2)
+		assert.EqualValues(t, "Urgent", views[0].Name)
+		assert.EqualValues(t, "week", views[1].Name)
+	})
+
+	t.Run("should get views by name, ignoring case", func(t *testing.T) {
+		view, err := service.Get(alice, "URGENT")
+		require.NoError(t, err)
+		assert.EqualValues(t, "Urgent", view.Name)
+
+		view, err = service.Get(bob, "week")
+		require.NoError(t, err)
+		assert.EqualValues(t, "tag:home", view.Query)
+	})
+
+	t.Run("should rename a view and replace its query", func(t *testing.T) {
+		view, err := service.Update(alice, "week", domain.ViewInput{Name: "next-week", Query: "due<+2w"})
+		require.NoError(t, err)
+		assert.EqualValues(t, "next-week", view.Name)
+
+		_, err = service.Get(alice, "week")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		view, err = service.Get(alice, "next-week")
+		require.NoError(t, err)
+		assert.EqualValues(t, "due<+2w", view.Query)
+	})
+
+	t.Run("should return conflict when renaming a view to a taken name", func(t *testing.T) {
+		_, err := service.Update(alice, "next-week", domain.ViewInput{Name: "urgent", Query: "done:false"})
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("should delete views of the user only", func(t *testing.T) {
+		require.NoError(t, service.Delete(alice, "urgent"))
+		require.NoError(t, service.Delete(alice, "urgent"))
+
+		_, err := service.Get(alice, "urgent")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = service.Get(bob, "week")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when context has no identity", func(t *testing.T) {
+		_, err := service.List(context.Background())
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+	})
+}
+
+func TestService_Entries(t *testing.T) {
+	now := time.Date(2021, 3, 5, 8, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	t.Run("should list entries through the entry service with the filter of the view", func(t *testing.T) {
+		mockEntryService := &mocks.EntryService{}
+		mockEntryService.
+			On("List", alice, mock.MatchedBy(func(query domain.ListQuery) bool {
+				return query.Filter != nil && query.Filter.String() == "tag:work" && query.Limit == 5
+			})).
+			Return(&domain.EntryPage{Entries: []*domain.Entry{{ID: "id"}}}, nil)
+
+		service := New(viewRepo.NewMemKVS(), mockEntryService, clock)
+		_, err := service.Create(alice, domain.ViewInput{Name: "work", Query: "tag:work"})
+		require.NoError(t, err)
+
+		page, err := service.Entries(alice, "work", domain.ListQuery{Limit: 5})
+		require.NoError(t, err)
+		assert.Len(t, page.Entries, 1)
+	})
+
+	t.Run("should evaluate the view against the entries of the user", func(t *testing.T) {
+		entries := entrySrv.New(entryRepo.NewMemKVS(), entrySrv.WithClock(func() time.Time { return now }))
+		soon, later := now.Add(48*time.Hour), now.Add(240*time.Hour)
+		for _, input := range []domain.EntryInput{
+			{Title: "Write report", Tags: []string{"work"}, DueAt: &soon},
+			{Title: "Plan quarter", Tags: []string{"work"}, DueAt: &later},
+			{Title: "Fix outage", Priority: domain.PriorityHigh},
+			{Title: "Water plants", DueAt: &soon},
+		} {
+			_, err := entries.Create(alice, input)
+			require.NoError(t, err)
+		}
+		_, err := entries.Create(bob, domain.EntryInput{Title: "Fix the roof", Priority: domain.PriorityHigh})
+		require.NoError(t, err)
+
+		service := New(viewRepo.NewMemKVS(), entries, clock)
+		_, err = service.Create(alice, domain.ViewInput{Name: "focus", Query: "done:false AND tag:work AND due<+3d OR priority:high"})
+		require.NoError(t, err)
+
+		page, err := service.Entries(alice, "focus", domain.ListQuery{SortBy: domain.SortByTitle})
+		require.NoError(t, err)
+		titles := []string{}
+		for _, entry := range page.Entries {
+			titles = append(titles, entry.Title)
+		}
+		assert.EqualValues(t, []string{"Fix outage", "Write report"}, titles)
+	})
+
+	t.Run("should return not found when the view is missing", func(t *testing.T) {
+		service := New(viewRepo.NewMemKVS(), &mocks.EntryService{}, clock)
+		_, err := service.Entries(alice, "missing", domain.ListQuery{})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
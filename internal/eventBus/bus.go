@@ -0,0 +1,185 @@
+package eventBus
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBufferSize is the number of events an asynchronous subscriber buffers when its
+// subscription does not give a size.
+const DefaultBufferSize = 100
+
+// bus is an in-process event bus. Asynchronous subscribers each handle their events in a goroutine
+// of their own, so that a slow subscriber only holds up the others when publishing blocks on it.
+type bus struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+	workers     sync.WaitGroup
+	dropped     atomic.Int64
+}
+
+// subscriber is a subscription to a bus. Synchronous subscribers have no queue.
+type subscriber struct {
+	handler  ports.EventHandler
+	queue    chan delivery
+	overflow domain.OverflowPolicy
+	done     chan struct{}
+	once     sync.Once
+}
+
+// delivery is an event waiting in the queue of an asynchronous subscriber, along with the context
+// it was published with.
+type delivery struct {
+	ctx   context.Context
+	event *domain.Event
+}
+
+// New returns a pointer to a new event bus without subscribers. The bus must be closed once it is
+// no longer used, to let asynchronous subscribers handle the events they buffered.
+func New() *bus {
+	return &bus{}
+}
+
+// Publish delivers events to every current subscriber, in order. It returns once synchronous
+// subscribers handled them and asynchronous ones buffered them, waiting for room in their buffer
+// unless they drop events on overflow. Events an asynchronous subscriber has no room for by the
+// time ctx is done, or that reach it once it stopped, are dropped.
+func (b *bus) Publish(ctx context.Context, events ...*domain.Event) {
+	b.mu.RLock()
+	subscribers := append([]*subscriber(nil), b.subscribers...)
+	b.mu.RUnlock()
+
+	for _, event := range events {
+		for _, sub := range subscribers {
+			if sub.queue == nil {
+				sub.handler(ctx, event)
+				continue
+			}
+			if !sub.enqueue(ctx, delivery{ctx: context.WithoutCancel(ctx), event: event}) {
+				b.dropped.Add(1)
+			}
+		}
+	}
+}
+
+// Subscribe makes handler handle every event published from now on, before Publish returns.
+func (b *bus) Subscribe(handler ports.EventHandler) (unsubscribe func()) {
+	return b.subscribe(&subscriber{handler: handler, done: make(chan struct{})})
+}
+
+// SubscribeAsync makes handler handle every event published from now on in the background, from
+// a buffer configured by subscription. Buffer sizes below 1 default to DefaultBufferSize, and
+// overflow policies other than domain.OverflowDrop block.
+func (b *bus) SubscribeAsync(handler ports.EventHandler, subscription domain.Subscription) (unsubscribe func()) {
+	size := subscription.Buffer
+	if size < 1 {
+		size = DefaultBufferSize
+	}
+	sub := &subscriber{
+		handler:  handler,
+		queue:    make(chan delivery, size),
+		overflow: subscription.Overflow,
+		done:     make(chan struct{}),
+	}
+
+	b.workers.Add(1)
+	go func() {
+		defer b.workers.Done()
+		sub.work()
+	}()
+	return b.subscribe(sub)
+}
+
+// Dropped returns the number of events dropped so far for lack of room in the buffer of an
+// asynchronous subscriber, or because the subscriber stopped while they were published.
+func (b *bus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Close unsubscribes every subscriber and waits for the asynchronous ones to handle the events
+// they buffered.
+func (b *bus) Close() {
+	b.mu.Lock()
+	subscribers := b.subscribers
+	b.subscribers = nil
+	b.mu.Unlock()
+
+	for _, sub := range subscribers {
+		sub.stop()
+	}
+	b.workers.Wait()
+}
+
+// subscribe adds sub to the subscribers and returns the function removing it.
+func (b *bus) subscribe(sub *subscriber) func() {
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, sub)
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		for i, other := range b.subscribers {
+			if other == sub {
+				b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+				break
+			}
+		}
+		b.mu.Unlock()
+		sub.stop()
+	}
+}
+
+// enqueue adds d to the queue of the subscriber as its overflow policy allows, and reports
+// whether it did. Nothing is added once the subscriber stopped, so d is lost then as well.
+func (s *subscriber) enqueue(ctx context.Context, d delivery) bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+
+	if s.overflow == domain.OverflowDrop {
+		select {
+		case s.queue <- d:
+			return true
+		default:
+			return false
+		}
+	}
+	select {
+	case s.queue <- d:
+		return true
+	case <-s.done:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// work handles the events queued for the subscriber until it stops, and then those left in the
+// queue.
+func (s *subscriber) work() {
+	for {
+		select {
+		case d := <-s.queue:
+			s.handler(d.ctx, d.event)
+		case <-s.done:
+			for {
+				select {
+				case d := <-s.queue:
+					s.handler(d.ctx, d.event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// stop stops deliveries to the subscriber. It can be called more than once.
+func (s *subscriber) stop() {
+	s.once.Do(func() { close(s.done) })
+}
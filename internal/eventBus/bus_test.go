@@ -0,0 +1,209 @@
+package eventBus
+
+import (
+	"context"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorded is an event handler recording the events it handles.
+type recorded struct {
+	mu     sync.Mutex
+	events []*domain.Event
+}
+
+func (r *recorded) handle(_ context.Context, event *domain.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorded) ids() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := []string{}
+	for _, event := range r.events {
+		ids = append(ids, event.EntryID)
+	}
+	return ids
+}
+
+func events(ids ...string) []*domain.Event {
+	events := []*domain.Event{}
+	for _, id := range ids {
+		events = append(events, &domain.Event{Type: domain.EventCreated, EntryID: id})
+	}
+	return events
+}
+
+func TestBus_Subscribe(t *testing.T) {
+	t.Run("should deliver events to synchronous subscribers before returning", func(t *testing.T) {
+		b := New()
+		defer b.Close()
+		first, second := &recorded{}, &recorded{}
+		b.Subscribe(first.handle)
+		b.Subscribe(second.handle)
+
+		b.Publish(context.Background(), events("a", "b")...)
+
+		assert.EqualValues(t, []string{"a", "b"}, first.ids())
+		assert.EqualValues(t, []string{"a", "b"}, second.ids())
+	})
+
+	t.Run("should stop delivering events once unsubscribed", func(t *testing.T) {
+		b := New()
+		defer b.Close()
+		sub := &recorded{}
+		unsubscribe := b.Subscribe(sub.handle)
+
+		b.Publish(context.Background(), events("a")...)
+		unsubscribe()
+		unsubscribe()
+		b.Publish(context.Background(), events("b")...)
+
+		assert.EqualValues(t, []string{"a"}, sub.ids())
+	})
+}
+
+func TestBus_SubscribeAsync(t *testing.T) {
+	t.Run("should deliver events to asynchronous subscribers in order", func(t *testing.T) {
+		b := New()
+		sub := &recorded{}
+		b.SubscribeAsync(sub.handle, domain.Subscription{Buffer: 1})
+
+		b.Publish(context.Background(), events("a", "b", "c")...)
+		b.Close()
+
+		assert.EqualValues(t, []string{"a", "b", "c"}, sub.ids())
+		assert.Zero(t, b.Dropped())
+	})
+
+	t.Run("should hand asynchronous subscribers the context without its cancellation", func(t *testing.T) {
+		b := New()
+		var handled error
+		b.SubscribeAsync(func(ctx context.Context, _ *domain.Event) {
+			handled = ctx.Err()
+		}, domain.Subscription{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		b.Publish(ctx, events("a")...)
+		cancel()
+		b.Close()
+
+		assert.NoError(t, handled)
+	})
+
+	t.Run("should drop events once the buffer is full when the subscriber drops them", func(t *testing.T) {
+		b := New()
+		release := make(chan struct{})
+		sub := &recorded{}
+		b.SubscribeAsync(func(ctx context.Context, event *domain.Event) {
+			<-release
+			sub.handle(ctx, event)
+		}, domain.Subscription{Buffer: 1, Overflow: domain.OverflowDrop})
+
+		b.Publish(context.Background(), events("a")...)
+		// Wait for the subscriber to take the first event, leaving room for a single other.
+		assert.Eventually(t, func() bool { return len(b.subscribers[0].queue) == 0 }, time.Second, time.Millisecond)
+		b.Publish(context.Background(), events("b", "c")...)
+		close(release)
+		b.Close()
+
+		assert.EqualValues(t, []string{"a", "b"}, sub.ids())
+		assert.EqualValues(t, 1, b.Dropped())
+	})
+
+	t.Run("should wait for room in the buffer when the subscriber blocks", func(t *testing.T) {
+		b := New()
+		release := make(chan struct{})
+		sub := &recorded{}
+		b.SubscribeAsync(func(ctx context.Context, event *domain.Event) {
+			<-release
+			sub.handle(ctx, event)
+		}, domain.Subscription{Buffer: 1, Overflow: domain.OverflowBlock})
+
+		published := make(chan struct{})
+		go func() {
+			b.Publish(context.Background(), events("a", "b", "c")...)
+			close(published)
+		}()
+		select {
+		case <-published:
+			t.Fatal("publishing returned before the subscriber caught up")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(release)
+		<-published
+		b.Close()
+
+		assert.EqualValues(t, []string{"a", "b", "c"}, sub.ids())
+		assert.Zero(t, b.Dropped())
+	})
+
+	t.Run("should drop the event once the context is done when the subscriber blocks", func(t *testing.T) {
+		b := New()
+		release := make(chan struct{})
+		sub := &recorded{}
+		b.SubscribeAsync(func(ctx context.Context, event *domain.Event) {
+			<-release
+			sub.handle(ctx, event)
+		}, domain.Subscription{Buffer: 1})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		b.Publish(ctx, events("a", "b", "c")...)
+		close(release)
+		b.Close()
+
+		assert.EqualValues(t, []string{"a", "b"}, sub.ids())
+		assert.EqualValues(t, 1, b.Dropped())
+	})
+
+	t.Run("should drop the event once the subscriber stops while waiting for room", func(t *testing.T) {
+		b := New()
+		taken, release := make(chan struct{}, 3), make(chan struct{})
+		sub := &recorded{}
+		b.SubscribeAsync(func(ctx context.Context, event *domain.Event) {
+			taken <- struct{}{}
+			<-release
+			sub.handle(ctx, event)
+		}, domain.Subscription{Buffer: 1, Overflow: domain.OverflowBlock})
+
+		published := make(chan struct{})
+		go func() {
+			b.Publish(context.Background(), events("a", "b", "c")...)
+			close(published)
+		}()
+		// Wait for the subscriber to take the first event and the second to fill the buffer.
+		<-taken
+		assert.Eventually(t, func() bool { return len(b.subscribers[0].queue) == 1 }, time.Second, time.Millisecond)
+		b.subscribers[0].stop()
+		<-published
+		close(release)
+		b.Close()
+
+		assert.EqualValues(t, []string{"a", "b"}, sub.ids())
+		assert.EqualValues(t, 1, b.Dropped())
+	})
+
+	t.Run("should handle the buffered events once unsubscribed", func(t *testing.T) {
+		b := New()
+		release := make(chan struct{})
+		sub := &recorded{}
+		unsubscribe := b.SubscribeAsync(func(ctx context.Context, event *domain.Event) {
+			<-release
+			sub.handle(ctx, event)
+		}, domain.Subscription{Buffer: 2})
+
+		b.Publish(context.Background(), events("a", "b")...)
+		unsubscribe()
+		b.Publish(context.Background(), events("c")...)
+		close(release)
+		b.Close()
+
+		assert.EqualValues(t, []string{"a", "b"}, sub.ids())
+	})
+}
@@ -0,0 +1,89 @@
+package accessHandler
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/handlers/httpCommon"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+type HTTPAccessHandler struct {
+	AccessService ports.AccessService
+}
+
+// NewHTTPAccessHandler returns a pointer to the HTTP adapter for the ports.AccessService interface.
+func NewHTTPAccessHandler(accessService ports.AccessService) *HTTPAccessHandler {
+	return &HTTPAccessHandler{
+		AccessService: accessService,
+	}
+}
+
+// Collaborators returns a handler listing the collaborators of the resource of the given kind
+// with the UUID specified within the URL.
+func (h *HTTPAccessHandler) Collaborators(kind domain.ResourceKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		vars := mux.Vars(r)
+		id := vars["id"]
+
+		grants, err := h.AccessService.Collaborators(r.Context(), kind, id)
+		if err != nil {
+			httpCommon.SendErrorResponse(w, "failed to list collaborators of "+string(kind), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(grants); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Share returns a handler inviting the user named within body as a collaborator on the resource
+// of the given kind with the UUID specified within the URL, in the role given within body.
+func (h *HTTPAccessHandler) Share(kind domain.ResourceKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		vars := mux.Vars(r)
+		id := vars["id"]
+
+		var details domain.GrantInput
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+			return
+		}
+
+		grant, err := h.AccessService.Share(r.Context(), kind, id, details)
+		if err != nil {
+			httpCommon.SendErrorResponse(w, "failed to share "+string(kind), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(grant); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Revoke returns a handler removing the collaborator with the user ID specified within the URL
+// from the resource of the given kind with the UUID specified within the URL.
+func (h *HTTPAccessHandler) Revoke(kind domain.ResourceKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+		vars := mux.Vars(r)
+		id, userID := vars["id"], vars["user_id"]
+
+		if err := h.AccessService.Revoke(r.Context(), kind, id, userID); err != nil {
+			httpCommon.SendErrorResponse(w, "failed to revoke access to "+string(kind), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
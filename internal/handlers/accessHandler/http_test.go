@@ -0,0 +1,168 @@
+package accessHandler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	mocks "github.com/Nikym/go-todo/mocks/core/ports"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setUp() (*mocks.AccessService, *HTTPAccessHandler) {
+	mockService := &mocks.AccessService{}
+	httpAccessHandler := NewHTTPAccessHandler(mockService)
+	return mockService, httpAccessHandler
+}
+
+func TestHTTPAccessHandler_Collaborators(t *testing.T) {
+	mockService, httpAccessHandler := setUp()
+	mockService.
+		On("Collaborators", mock.Anything, domain.ResourceList, "sprint").
+		Return([]*domain.Grant{{Kind: domain.ResourceList, ResourceID: "sprint", UserID: "bob-id", Username: "bob", Role: domain.RoleViewer}}, nil)
+	mockService.
+		On("Collaborators", mock.Anything, domain.ResourceList, "missing").
+		Return(nil, domain.NotFound("list not found in repository"))
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{
+			name:   "should return OK when collaborators are listed",
+			id:     "sprint",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Not Found when list does not exist",
+			id:     "missing",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", fmt.Sprintf("/api/list/%s/collaborators", test.id), nil)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/list/{id}/collaborators", httpAccessHandler.Collaborators(domain.ResourceList))
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
+
+func TestHTTPAccessHandler_Share(t *testing.T) {
+	mockService, httpAccessHandler := setUp()
+	mockService.
+		On("Share", mock.Anything, domain.ResourceEntry, "a", domain.GrantInput{Username: "bob", Role: domain.RoleEditor}).
+		Return(&domain.Grant{Kind: domain.ResourceEntry, ResourceID: "a", UserID: "bob-id", Username: "bob", Role: domain.RoleEditor}, nil)
+	mockService.
+		On("Share", mock.Anything, domain.ResourceEntry, "a", domain.GrantInput{Username: "bob", Role: "admin"}).
+		Return(&domain.Grant{}, domain.NewValidationError("role", "must be one of viewer, editor or owner"))
+	mockService.
+		On("Share", mock.Anything, domain.ResourceEntry, "b", domain.GrantInput{Username: "bob", Role: domain.RoleEditor}).
+		Return(&domain.Grant{}, domain.Forbidden("requires the owner role on the entry"))
+	mockService.
+		On("Share", mock.Anything, domain.ResourceEntry, "invalid", domain.GrantInput{Username: "bob", Role: domain.RoleEditor}).
+		Return(&domain.Grant{}, errors.New("invalid"))
+
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{
+			name:   "should return OK when entry is shared",
+			id:     "a",
+			body:   `{"username": "bob", "role": "editor"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Bad Request when role is unknown",
+			id:     "a",
+			body:   `{"username": "bob", "role": "admin"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should return Forbidden when user does not own the entry",
+			id:     "b",
+			body:   `{"username": "bob", "role": "editor"}`,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "should return Bad Request when body is not json",
+			id:     "a",
+			body:   `bob`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should return Internal Server Error when sharing fails unexpectedly",
+			id:     "invalid",
+			body:   `{"username": "bob", "role": "editor"}`,
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", fmt.Sprintf("/api/entry/%s/collaborators", test.id), strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/entry/{id}/collaborators", httpAccessHandler.Share(domain.ResourceEntry))
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
+
+func TestHTTPAccessHandler_Revoke(t *testing.T) {
+	mockService, httpAccessHandler := setUp()
+	mockService.
+		On("Revoke", mock.Anything, domain.ResourceList, "sprint", "bob-id").
+		Return(nil)
+	mockService.
+		On("Revoke", mock.Anything, domain.ResourceList, "sprint", "carol-id").
+		Return(domain.Forbidden("requires the owner role on the list"))
+
+	tests := []struct {
+		name   string
+		userID string
+		status int
+	}{
+		{
+			name:   "should return OK when access is revoked",
+			userID: "bob-id",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Forbidden when user does not own the list",
+			userID: "carol-id",
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/api/list/sprint/collaborators/%s", test.userID), nil)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/list/{id}/collaborators/{user_id}", httpAccessHandler.Revoke(domain.ResourceList))
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
@@ -2,19 +2,52 @@ package entryHandler
 
 import (
 	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
 	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/handlers/httpCommon"
 	"github.com/gorilla/mux"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-type response struct {
-	Message string `json:"message"`
-	Error   string `json:"error"`
-}
-
 type createJSON struct {
 	Title       string
 	Description string
+	Priority    domain.Priority    `json:"priority"`
+	Tags        []string           `json:"tags"`
+	DueAt       *time.Time         `json:"due_at"`
+	ParentID    string             `json:"parent_id"`
+	ListID      string             `json:"list_id"`
+	Recurrence  *domain.Recurrence `json:"recurrence"`
+	Quick       bool               `json:"quick"`
+}
+
+// input returns the entry input described by the body.
+func (details createJSON) input() domain.EntryInput {
+	return domain.EntryInput{
+		Title:       details.Title,
+		Description: details.Description,
+		Priority:    details.Priority,
+		Tags:        details.Tags,
+		DueAt:       details.DueAt,
+		ParentID:    details.ParentID,
+		ListID:      details.ListID,
+		Recurrence:  details.Recurrence,
+		Quick:       details.Quick,
+	}
+}
+
+type tagsJSON struct {
+	Tags []string `json:"tags"`
+}
+
+type listResponse struct {
+	Entries    []*domain.Entry `json:"entries"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Next       string          `json:"next,omitempty"`
 }
 
 type HTTPEntryHandler struct {
@@ -28,16 +61,25 @@ func NewHTTPEntryHandler(entryService ports.EntryService) *HTTPEntryHandler {
 	}
 }
 
-// Get handles retrieval of a to-do entry through HTTP with a specified UUID within the URL.
+// Get handles retrieval of a to-do entry through HTTP with a specified UUID within the URL. The
+// response carries the version of the entry as its ETag, and is Not Modified when that version
+// is named by the If-None-Match header.
 func (h *HTTPEntryHandler) Get(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	entry, err := h.EntryService.Get(id)
+	entry, err := h.EntryService.Get(r.Context(), id)
 	if err != nil {
-		sendErrorResponse(w, "failed to retrieve entry with given ID", err)
+		httpCommon.SendErrorResponse(w, "failed to retrieve entry with given ID", err)
+		return
+	}
+
+	etag := httpCommon.ETag(entry.Version)
+	w.Header().Set("ETag", etag)
+	if match := r.Header.Get("If-None-Match"); match != "" && httpCommon.MatchETag(match, etag, true) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -47,75 +89,496 @@ func (h *HTTPEntryHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Create handles the creation of a new to-do entry through HTTP with given Title and Description within body.
+// Create handles the creation of a new to-do entry through HTTP with given Title and Description
+// within body. When quick is true, the title is parsed for the other fields of the entry, like
+// "Call dentist tomorrow 3pm #health !high".
 func (h *HTTPEntryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
-	var details *createJSON
+	var details createJSON
 	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
-		sendErrorResponse(w, "failed to decode json body", err)
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
 		return
 	}
 
-	newEntry, err := h.EntryService.Create(details.Title, details.Description)
+	newEntry, err := h.EntryService.Create(r.Context(), details.input())
 	if err != nil {
-		sendErrorResponse(w, "failed to create to-do entry", err)
+		httpCommon.SendErrorResponse(w, "failed to create to-do entry", err)
 		return
 	}
 
+	w.Header().Set("ETag", httpCommon.ETag(newEntry.Version))
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(*newEntry); err != nil {
 		panic(err)
 	}
 }
 
-// Delete removes an entry with a given ID through HTTP.
+// Parse handles a dry run of the creation of a to-do entry in quick mode, responding with the
+// fields parsed from the title given in the body without creating the entry.
+func (h *HTTPEntryHandler) Parse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	var details createJSON
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	parsed, err := h.EntryService.Parse(r.Context(), details.input())
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to parse to-do entry", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(parsed); err != nil {
+		panic(err)
+	}
+}
+
+// Delete removes an entry with a given ID through HTTP, moving it to the trash unless the
+// permanent query parameter is true. Entries with subtasks are only removed, together with their
+// subtasks, when the cascade query parameter is true. When the If-Match header is given, the
+// entry is only removed while its ETag matches.
 func (h *HTTPEntryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.EntryService.Delete(id)
-	if err != nil {
-		sendErrorResponse(w, "failed to delete entry with given id", err)
+	var opts domain.DeleteOptions
+	var err error
+	if opts.Cascade, err = boolParam(r, "cascade"); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to parse query parameters", err)
+		return
+	}
+	if opts.Permanent, err = boolParam(r, "permanent"); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to parse query parameters", err)
+		return
+	}
+
+	if match := r.Header.Get("If-Match"); match != "" {
+		entry, err := h.current(r, id, match)
+		if err != nil {
+			httpCommon.SendErrorResponse(w, "failed to delete entry with given id", err)
+			return
+		}
+		opts.Version = entry.Version
+	}
+
+	if err := h.EntryService.Delete(r.Context(), id, opts); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to delete entry with given id", err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-// Update updates the entry specified by the ID with the new values given in the body.
+// boolParam returns the value of the boolean query parameter with the given name, false when it
+// is missing.
+func boolParam(r *http.Request, name string) (bool, error) {
+	val := r.URL.Query().Get(name)
+	if val == "" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, domain.NewValidationError(name, "must be either true or false")
+	}
+	return parsed, nil
+}
+
+// Update updates the entry specified by the ID with the new values given in the body. A body of
+// type application/json is decoded onto the entry, while bodies of type
+// application/merge-patch+json (RFC 7396) and application/json-patch+json (RFC 6902) are applied
+// to its JSON representation, which allows clearing fields and rejects unknown and immutable
+// ones. The update is rejected when the entry has changed since the version named by the
+// If-Match header, or by the version within the body when the header is missing. The response
+// carries the ETag of the updated entry.
 func (h *HTTPEntryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	entry, err := h.EntryService.Get(id)
+	mediaType, err := bodyType(r)
 	if err != nil {
-		sendErrorResponse(w, "failed to find entry with given id", err)
+		w.Header().Set("Accept-Patch", strings.Join(patchTypes, ", "))
+		httpCommon.SendErrorResponse(w, "failed to decode body", err)
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(entry); err != nil {
-		sendErrorResponse(w, "failed to decode json body", err)
+	entry, err := h.current(r, id, r.Header.Get("If-Match"))
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to find entry with given id", err)
 		return
 	}
+	version := entry.Version
+
+	if mediaType == jsonType {
+		if err := json.NewDecoder(r.Body).Decode(entry); err != nil {
+			httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+			return
+		}
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			httpCommon.SendErrorResponse(w, "failed to read body", httpCommon.BodyError(err))
+			return
+		}
+		if entry, err = patch(entry, mediaType, body); err != nil {
+			httpCommon.SendErrorResponse(w, "failed to patch entry", err)
+			return
+		}
+	}
 
 	entry.ID = id
-	if err := h.EntryService.Update(id, entry); err != nil {
-		sendErrorResponse(w, "failed to update entry", err)
+	if r.Header.Get("If-Match") != "" {
+		entry.Version = version
+	}
+	if err := h.EntryService.Update(r.Context(), id, entry); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to update entry", err)
+		return
+	}
+
+	w.Header().Set("ETag", httpCommon.ETag(entry.Version))
+	w.WriteHeader(http.StatusOK)
+}
+
+// current returns the entry with the given UUID, provided its ETag matches the value of the
+// If-Match header given by match, if any.
+func (h *HTTPEntryHandler) current(r *http.Request, id, match string) (*domain.Entry, error) {
+	entry, err := h.EntryService.Get(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if match != "" && !httpCommon.MatchETag(match, httpCommon.ETag(entry.Version), false) {
+		return nil, domain.PreconditionFailed("entry has changed since the version given by If-Match")
+	}
+	return entry, nil
+}
+
+// Children handles retrieval of the direct subtasks of the entry with the ID specified in the URL.
+func (h *HTTPEntryHandler) Children(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	children, err := h.EntryService.Children(r.Context(), id)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to retrieve subtasks of entry", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(children); err != nil {
+		panic(err)
+	}
+}
+
+// Tree handles retrieval of the entry with the ID specified in the URL together with all of its
+// subtasks, nested under the children field of their parent.
+func (h *HTTPEntryHandler) Tree(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	tree, err := h.EntryService.Tree(r.Context(), id)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to retrieve entry tree", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(tree); err != nil {
+		panic(err)
+	}
+}
+
+// History handles retrieval of the revisions of the entry with the ID specified in the URL, oldest
+// first, each naming who changed which fields of the entry and when.
+func (h *HTTPEntryHandler) History(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	revisions, err := h.EntryService.History(r.Context(), vars["id"])
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to retrieve history of entry with given id", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		panic(err)
+	}
+}
+
+// Revert handles reverting the entry with the ID specified in the URL to the revision with the
+// number specified in the URL, responding with the reverted entry and its ETag. When the If-Match
+// header is given, the entry is only reverted while its ETag matches.
+func (h *HTTPEntryHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	number, err := strconv.Atoi(vars["number"])
+	if err != nil || number < 1 {
+		httpCommon.SendErrorResponse(w, "failed to parse revision number", domain.NewValidationError("number", "must be a positive integer"))
+		return
+	}
+
+	var version int64
+	if match := r.Header.Get("If-Match"); match != "" {
+		entry, err := h.current(r, vars["id"], match)
+		if err != nil {
+			httpCommon.SendErrorResponse(w, "failed to revert entry with given id", err)
+			return
+		}
+		version = entry.Version
+	}
+
+	entry, err := h.EntryService.Revert(r.Context(), vars["id"], number, version)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to revert entry with given id", err)
 		return
 	}
 
+	w.Header().Set("ETag", httpCommon.ETag(entry.Version))
 	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		panic(err)
+	}
 }
 
-func sendErrorResponse(w http.ResponseWriter, message string, err error) {
-	w.WriteHeader(http.StatusInternalServerError)
-	if err := json.NewEncoder(w).Encode(
-		response{Message: message, Error: err.Error()},
-	); err != nil {
+// List handles retrieval of a page of to-do entries through HTTP. Entries can be filtered with the
+// done, parent, list, title, tag, priority, due_before and due_after query parameters, ordered with
+// sort and order, and paged through with limit and cursor. An empty parent lists top-level entries
+// only, and an empty list the entries that belong to no list.
+func (h *HTTPEntryHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	query, err := httpCommon.ParseListQuery(r)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to parse query parameters", err)
+		return
+	}
+
+	page, err := h.EntryService.List(r.Context(), query)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to list entries", err)
+		return
+	}
+
+	res := listResponse{Entries: page.Entries, NextCursor: page.NextCursor}
+	if page.NextCursor != "" {
+		next := *r.URL
+		values := next.Query()
+		values.Set("cursor", page.NextCursor)
+		next.RawQuery = values.Encode()
+		res.Next = next.RequestURI()
+		w.Header().Set("Link", "<"+res.Next+">; rel=\"next\"")
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		panic(err)
+	}
+}
+
+// AddTags handles adding the tags given in the body to the entry with the ID specified in the URL.
+func (h *HTTPEntryHandler) AddTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var details tagsJSON
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	entry, err := h.EntryService.AddTags(r.Context(), id, details.Tags)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to add tags to entry", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		panic(err)
+	}
+}
+
+// RemoveTag handles removing the tag specified in the URL from the entry with the ID specified in
+// the URL. Tags starting with '#' have to be percent-encoded.
+func (h *HTTPEntryHandler) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	entry, err := h.EntryService.RemoveTags(r.Context(), id, []string{vars["tag"]})
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to remove tag from entry", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		panic(err)
+	}
+}
+
+// Tags handles retrieval of every tag in use, with the number of entries carrying it. The same
+// filters as List can be used to only count the tags of some entries, e.g. done=false.
+func (h *HTTPEntryHandler) Tags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	query, err := httpCommon.ParseListQuery(r)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to parse query parameters", err)
+		return
+	}
+
+	tags, err := h.EntryService.Tags(r.Context(), query)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to list tags", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(tags); err != nil {
+		panic(err)
+	}
+}
+
+// Search handles full-text search of to-do entries through HTTP for the words given in the q query
+// parameter. Results are ordered by relevance, limited by limit, and carry the title and an
+// excerpt of the description of the entries with the matching words wrapped in <mark> elements.
+func (h *HTTPEntryHandler) Search(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	query := domain.SearchQuery{Text: r.URL.Query().Get("q")}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			httpCommon.SendErrorResponse(w, "failed to parse query parameters", domain.NewValidationError("limit", "must be a number"))
+			return
+		}
+		query.Limit = parsed
+	}
+
+	results, err := h.EntryService.Search(r.Context(), query)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to search entries", err)
+		return
+	}
+
+	// The highlights are escaped already, so the marks are written as they are.
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	w.WriteHeader(http.StatusOK)
+	if err := encoder.Encode(results); err != nil {
+		panic(err)
+	}
+}
+
+// Trash handles retrieval of the entries in the trash of the user, most recently deleted first,
+// each with the subtasks deleted along with it.
+func (h *HTTPEntryHandler) Trash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	entries, err := h.EntryService.Trash(r.Context())
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to list trash", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		panic(err)
+	}
+}
+
+// Restore handles moving the entry with the ID specified in the URL out of the trash, responding
+// with the restored entry and its ETag.
+func (h *HTTPEntryHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	entry, err := h.EntryService.Restore(r.Context(), vars["id"])
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to restore entry with given id", err)
+		return
+	}
+
+	w.Header().Set("ETag", httpCommon.ETag(entry.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		panic(err)
+	}
+}
+
+// Purge handles the permanent removal of the entry with the ID specified in the URL from the
+// trash.
+func (h *HTTPEntryHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	if err := h.EntryService.Purge(r.Context(), vars["id"]); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to purge entry with given id", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// EmptyTrash handles the permanent removal of every entry in the trash of the user.
+func (h *HTTPEntryHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	if err := h.EntryService.EmptyTrash(r.Context()); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to empty trash", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Undo handles undoing the most recent creation, update or deletion of entries made by the user,
+// responding with the undone operation.
+func (h *HTTPEntryHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	op, err := h.EntryService.Undo(r.Context())
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to undo operation", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(op); err != nil {
+		panic(err)
+	}
+}
+
+// Redo handles redoing the operation most recently undone by the user, responding with the
+// redone operation.
+func (h *HTTPEntryHandler) Redo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	op, err := h.EntryService.Redo(r.Context())
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to redo operation", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(op); err != nil {
 		panic(err)
 	}
 }
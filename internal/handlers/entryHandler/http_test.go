@@ -1,6 +1,7 @@
 package entryHandler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,10 +9,12 @@ import (
 	mocks "github.com/Nikym/go-todo/mocks/core/ports"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func setUp() (*mocks.EntryService, *HTTPEntryHandler) {
@@ -23,7 +26,7 @@ func setUp() (*mocks.EntryService, *HTTPEntryHandler) {
 func TestHTTPEntryHandler_Get(t *testing.T) {
 	mockService, httpEntryHandler := setUp()
 	mockService.
-		On("Get", "1d126f09-4daf-447e-aaab-74765d8aefa2").
+		On("Get", mock.Anything, "1d126f09-4daf-447e-aaab-74765d8aefa2").
 		Return(&domain.Entry{
 			ID:          "1d126f09-4daf-447e-aaab-74765d8aefa2",
 			Title:       "Test Title",
@@ -31,8 +34,11 @@ func TestHTTPEntryHandler_Get(t *testing.T) {
 			Done:        false,
 		}, nil)
 	mockService.
-		On("Get", "invalid").
+		On("Get", mock.Anything, "invalid").
 		Return(&domain.Entry{}, errors.New("invalid"))
+	mockService.
+		On("Get", mock.Anything, "missing").
+		Return(&domain.Entry{}, domain.NotFound("entry not found"))
 
 	tests := []struct {
 		name   string
@@ -45,7 +51,12 @@ func TestHTTPEntryHandler_Get(t *testing.T) {
 			status: http.StatusOK,
 		},
 		{
-			name:   "should return Internal Server Error when given entry ID not present",
+			name:   "should return Not Found when given entry ID not present",
+			id:     "missing",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "should return Internal Server Error when retrieval fails unexpectedly",
 			id:     "invalid",
 			status: http.StatusInternalServerError,
 		},
@@ -69,32 +80,80 @@ func TestHTTPEntryHandler_Get(t *testing.T) {
 func TestHTTPEntryHandler_Delete(t *testing.T) {
 	mockService, httpEntryHandler := setUp()
 	mockService.
-		On("Delete", "1d126f09-4daf-447e-aaab-74765d8aefa2").
+		On("Delete", mock.Anything, "1d126f09-4daf-447e-aaab-74765d8aefa2", domain.DeleteOptions{}).
 		Return(nil)
 	mockService.
-		On("Delete", "invalid").
+		On("Delete", mock.Anything, "invalid", domain.DeleteOptions{}).
 		Return(errors.New("invalid"))
+	mockService.
+		On("Delete", mock.Anything, "parent", domain.DeleteOptions{}).
+		Return(domain.Conflict("entry has subtasks"))
+	mockService.
+		On("Delete", mock.Anything, "missing", domain.DeleteOptions{}).
+		Return(domain.NotFound("entry not found in repository"))
+	mockService.
+		On("Delete", mock.Anything, "parent", domain.DeleteOptions{Cascade: true}).
+		Return(nil)
+	mockService.
+		On("Delete", mock.Anything, "scratch", domain.DeleteOptions{Permanent: true}).
+		Return(nil)
 
 	tests := []struct {
 		name    string
 		id      string
+		query   string
 		success bool
+		status  int
 	}{
 		{
 			name:    "should return OK when given valid entry id",
 			id:      "1d126f09-4daf-447e-aaab-74765d8aefa2",
 			success: true,
 		},
+		{
+			name:   "should return not found when given an unknown entry id",
+			id:     "missing",
+			status: http.StatusNotFound,
+		},
 		{
 			name:    "should not be successful when given invalid entry id",
 			id:      "invalid",
 			success: false,
 		},
+		{
+			name:    "should not be successful when entry has subtasks and cascade is not requested",
+			id:      "parent",
+			success: false,
+		},
+		{
+			name:    "should return OK when entry has subtasks and cascade is requested",
+			id:      "parent",
+			query:   "?cascade=true",
+			success: true,
+		},
+		{
+			name:    "should not be successful when cascade is not a boolean",
+			id:      "parent",
+			query:   "?cascade=maybe",
+			success: false,
+		},
+		{
+			name:    "should return OK when a permanent deletion is requested",
+			id:      "scratch",
+			query:   "?permanent=true",
+			success: true,
+		},
+		{
+			name:    "should not be successful when permanent is not a boolean",
+			id:      "scratch",
+			query:   "?permanent=forever",
+			success: false,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			path := fmt.Sprintf("/api/entry/%s", test.id)
+			path := fmt.Sprintf("/api/entry/%s%s", test.id, test.query)
 			req := httptest.NewRequest("DELETE", path, nil)
 			rr := httptest.NewRecorder()
 
@@ -103,6 +162,9 @@ func TestHTTPEntryHandler_Delete(t *testing.T) {
 			router.ServeHTTP(rr, req)
 
 			assert.Equal(t, test.success, rr.Code == http.StatusOK)
+			if test.status != 0 {
+				assert.Equal(t, test.status, rr.Code)
+			}
 		})
 	}
 }
@@ -110,7 +172,7 @@ func TestHTTPEntryHandler_Delete(t *testing.T) {
 func TestHTTPEntryHandler_Create(t *testing.T) {
 	mockService, httpEntryHandler := setUp()
 	mockService.
-		On("Create", "Test Title", "Test Description").
+		On("Create", mock.Anything, domain.EntryInput{Title: "Test Title", Description: "Test Description"}).
 		Return(&domain.Entry{
 			ID:          "1d126f09-4daf-447e-aaab-74765d8aefa2",
 			Title:       "Test Title",
@@ -118,7 +180,7 @@ func TestHTTPEntryHandler_Create(t *testing.T) {
 			Done:        false,
 		}, nil)
 	mockService.
-		On("Create", "Invalid", "Invalid").
+		On("Create", mock.Anything, domain.EntryInput{Title: "Invalid", Description: "Invalid"}).
 		Return(&domain.Entry{}, errors.New("invalid"))
 
 	tests := []struct {
@@ -171,6 +233,31 @@ func TestHTTPEntryHandler_Create(t *testing.T) {
 	}
 }
 
+func TestHTTPEntryHandler_CreatePlanningFields(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	due := time.Date(2021, 3, 4, 17, 30, 0, 0, time.UTC)
+	input := domain.EntryInput{Title: "Test Title", Priority: domain.PriorityHigh, DueAt: &due}
+	mockService.
+		On("Create", mock.Anything, mock.MatchedBy(func(i domain.EntryInput) bool {
+			return i.Title == input.Title && i.Priority == input.Priority && i.DueAt.Equal(due)
+		})).
+		Return(&domain.Entry{ID: "id", Title: "Test Title", Priority: domain.PriorityHigh, DueAt: &due}, nil)
+
+	payload := `{"title": "Test Title", "priority": "high", "due_at": "2021-03-04T17:30:00Z"}`
+	req := httptest.NewRequest("POST", "/api/entry", strings.NewReader(payload))
+	rr := httptest.NewRecorder()
+	httpEntryHandler.Create(rr, req)
+
+	assert.EqualValues(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"priority":"high"`)
+
+	req = httptest.NewRequest("POST", "/api/entry", strings.NewReader(`{"title": "Test Title", "priority": "urgent"}`))
+	rr = httptest.NewRecorder()
+	httpEntryHandler.Create(rr, req)
+
+	assert.EqualValues(t, http.StatusBadRequest, rr.Code)
+}
+
 func TestHTTPEntryHandler_Update(t *testing.T) {
 	mockService, httpEntryHandler := setUp()
 	testEntry := &domain.Entry{
@@ -186,13 +273,13 @@ func TestHTTPEntryHandler_Update(t *testing.T) {
 		Done:        false,
 	}
 	mockService.
-		On("Get", "1d126f09-4daf-447e-aaab-74765d8aefa2").
+		On("Get", mock.Anything, "1d126f09-4daf-447e-aaab-74765d8aefa2").
 		Return(testEntry, nil)
 	mockService.
-		On("Get", "invalid").
+		On("Get", mock.Anything, "invalid").
 		Return(&domain.Entry{}, errors.New("invalid"))
 	mockService.
-		On("Update", "1d126f09-4daf-447e-aaab-74765d8aefa2", testUpdateEntry).
+		On("Update", mock.Anything, "1d126f09-4daf-447e-aaab-74765d8aefa2", testUpdateEntry).
 		Return(nil)
 
 	tests := []struct {
@@ -233,3 +320,675 @@ func TestHTTPEntryHandler_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPEntryHandler_Versions(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	mockService.
+		On("Get", mock.Anything, "a").
+		Return(func(_ context.Context, _ string) *domain.Entry {
+			return &domain.Entry{ID: "a", Version: 3, Title: "Test Title"}
+		}, nil)
+	mockService.
+		On("Update", mock.Anything, "a", mock.MatchedBy(func(entry *domain.Entry) bool { return entry.Version == 3 })).
+		Run(func(args mock.Arguments) { args.Get(2).(*domain.Entry).Version = 4 }).
+		Return(nil)
+	mockService.
+		On("Update", mock.Anything, "a", mock.MatchedBy(func(entry *domain.Entry) bool { return entry.Version != 3 })).
+		Return(domain.PreconditionFailed("entry has changed since version 2"))
+	mockService.
+		On("Delete", mock.Anything, "a", domain.DeleteOptions{Version: 3}).
+		Return(nil)
+
+	tests := []struct {
+		name   string
+		method string
+		header string
+		value  string
+		body   string
+		status int
+		etag   string
+	}{
+		{
+			name:   "should return the version as ETag",
+			method: "GET",
+			status: http.StatusOK,
+			etag:   `"3"`,
+		},
+		{
+			name:   "should return Not Modified when If-None-Match names the current version",
+			method: "GET",
+			header: "If-None-Match",
+			value:  `W/"3"`,
+			status: http.StatusNotModified,
+			etag:   `"3"`,
+		},
+		{
+			name:   "should return OK when If-None-Match names another version",
+			method: "GET",
+			header: "If-None-Match",
+			value:  `"2"`,
+			status: http.StatusOK,
+			etag:   `"3"`,
+		},
+		{
+			name:   "should return the new ETag when If-Match names the current version",
+			method: "PATCH",
+			header: "If-Match",
+			value:  `"3"`,
+			body:   `{"title": "Test Title 2", "version": 1}`,
+			status: http.StatusOK,
+			etag:   `"4"`,
+		},
+		{
+			name:   "should return Precondition Failed when If-Match names another version",
+			method: "PATCH",
+			header: "If-Match",
+			value:  `"2"`,
+			body:   `{"title": "Test Title 2"}`,
+			status: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "should return Precondition Failed when body names another version",
+			method: "PATCH",
+			body:   `{"title": "Test Title 2", "version": 2}`,
+			status: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "should delete the current version when If-Match names it",
+			method: "DELETE",
+			header: "If-Match",
+			value:  `"3"`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Precondition Failed when deleting another version",
+			method: "DELETE",
+			header: "If-Match",
+			value:  `"2", "4"`,
+			status: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/api/entry/a", strings.NewReader(test.body))
+			if test.header != "" {
+				req.Header.Set(test.header, test.value)
+			}
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/entry/{id}", httpEntryHandler.Get).Methods("GET")
+			router.HandleFunc("/api/entry/{id}", httpEntryHandler.Update).Methods("PATCH")
+			router.HandleFunc("/api/entry/{id}", httpEntryHandler.Delete).Methods("DELETE")
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+			assert.EqualValues(t, test.etag, rr.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHTTPEntryHandler_List(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	done := true
+	mockService.
+		On("List", mock.Anything, domain.ListQuery{Done: &done, SortBy: domain.SortByTitle, Limit: 1}).
+		Return(&domain.EntryPage{
+			Entries:    []*domain.Entry{{ID: "1d126f09-4daf-447e-aaab-74765d8aefa2", Title: "Test Title", Done: true}},
+			NextCursor: "next",
+		}, nil)
+	mockService.
+		On("List", mock.Anything, domain.ListQuery{TitleContains: "invalid"}).
+		Return(&domain.EntryPage{}, errors.New("invalid"))
+
+	tests := []struct {
+		name     string
+		query    string
+		status   int
+		expected string
+	}{
+		{
+			name:     "should return OK with a next link when another page is available",
+			query:    "?done=true&sort=title&limit=1",
+			status:   http.StatusOK,
+			expected: "/api/entry?cursor=next&done=true&limit=1&sort=title",
+		},
+		{
+			name:   "should return Bad Request when done is not a boolean",
+			query:  "?done=maybe",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should return Bad Request when priority is unknown",
+			query:  "?priority=urgent",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should return Bad Request when due date is not a timestamp",
+			query:  "?due_before=tomorrow",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should not be successful when the service fails",
+			query:  "?title=invalid",
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/entry"+test.query, nil)
+			rr := httptest.NewRecorder()
+			httpEntryHandler.List(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+			if test.status == http.StatusOK {
+				var res listResponse
+				if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+					panic(err)
+				}
+				assert.Len(t, res.Entries, 1)
+				assert.EqualValues(t, "next", res.NextCursor)
+				assert.EqualValues(t, test.expected, res.Next)
+			}
+		})
+	}
+}
+
+func TestHTTPEntryHandler_AddTags(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	mockService.
+		On("AddTags", mock.Anything, "1d126f09-4daf-447e-aaab-74765d8aefa2", []string{"@work"}).
+		Return(&domain.Entry{ID: "1d126f09-4daf-447e-aaab-74765d8aefa2", Tags: []string{"@work"}}, nil)
+	mockService.
+		On("AddTags", mock.Anything, "1d126f09-4daf-447e-aaab-74765d8aefa2", []string{"!"}).
+		Return(&domain.Entry{}, domain.NewValidationError("tags", "invalid"))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name:   "should return OK when valid tags given",
+			body:   `{"tags": ["@work"]}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Bad Request when tags are invalid",
+			body:   `{"tags": ["!"]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should return Bad Request when body is not json",
+			body:   `tags`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/entry/1d126f09-4daf-447e-aaab-74765d8aefa2/tags", strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/entry/{id}/tags", httpEntryHandler.AddTags)
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
+
+func TestHTTPEntryHandler_RemoveTag(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	mockService.
+		On("RemoveTags", mock.Anything, "1d126f09-4daf-447e-aaab-74765d8aefa2", []string{"#release-2.3"}).
+		Return(&domain.Entry{ID: "1d126f09-4daf-447e-aaab-74765d8aefa2"}, nil)
+
+	req := httptest.NewRequest("DELETE", "/api/entry/1d126f09-4daf-447e-aaab-74765d8aefa2/tags/%23release-2.3", nil)
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/entry/{id}/tags/{tag}", httpEntryHandler.RemoveTag)
+	router.ServeHTTP(rr, req)
+
+	assert.EqualValues(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHTTPEntryHandler_Tags(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	notDone := false
+	mockService.
+		On("Tags", mock.Anything, domain.ListQuery{Done: &notDone}).
+		Return([]domain.TagCount{{Tag: "@work", Count: 3}}, nil)
+
+	req := httptest.NewRequest("GET", "/api/tags?done=false", nil)
+	rr := httptest.NewRecorder()
+	httpEntryHandler.Tags(rr, req)
+
+	assert.EqualValues(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"tag": "@work", "count": 3}]`, rr.Body.String())
+}
+
+func TestHTTPEntryHandler_Tree(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	mockService.
+		On("Tree", mock.Anything, "parent").
+		Return(&domain.EntryNode{
+			Entry: &domain.Entry{ID: "parent", Title: "Parent"},
+			Children: []*domain.EntryNode{
+				{Entry: &domain.Entry{ID: "child", Title: "Child", ParentID: "parent"}, Children: []*domain.EntryNode{}},
+			},
+		}, nil)
+	mockService.
+		On("Tree", mock.Anything, "missing").
+		Return(&domain.EntryNode{}, domain.NotFound("entry not found"))
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{
+			name:   "should return OK with nested subtasks when entry exists",
+			id:     "parent",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Not Found when entry does not exist",
+			id:     "missing",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", fmt.Sprintf("/api/entry/%s/tree", test.id), nil)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/entry/{id}/tree", httpEntryHandler.Tree)
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+			if test.status == http.StatusOK {
+				var res struct {
+					ID       string `json:"id"`
+					Children []struct {
+						ID       string `json:"id"`
+						ParentID string `json:"parent_id"`
+					} `json:"children"`
+				}
+				if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+					panic(err)
+				}
+				assert.EqualValues(t, "parent", res.ID)
+				assert.Len(t, res.Children, 1)
+				assert.EqualValues(t, "parent", res.Children[0].ParentID)
+			}
+		})
+	}
+}
+
+func TestHTTPEntryHandler_Search(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	mockService.
+		On("Search", mock.Anything, domain.SearchQuery{Text: "release notes", Limit: 5}).
+		Return([]*domain.SearchResult{{
+			Entry: &domain.Entry{ID: "id", Title: "Write release notes"},
+			Score: 1.5,
+			Title: "Write <mark>release</mark> <mark>notes</mark>",
+		}}, nil)
+	mockService.
+		On("Search", mock.Anything, domain.SearchQuery{}).
+		Return(nil, domain.NewValidationError("q", "cannot be empty"))
+
+	tests := []struct {
+		name     string
+		target   string
+		code     int
+		contains string
+	}{
+		{
+			name:     "should return 200 with the highlighted results",
+			target:   "/api/entry/search?q=release+notes&limit=5",
+			code:     http.StatusOK,
+			contains: `"title":"Write <mark>release</mark> <mark>notes</mark>"`,
+		},
+		{
+			name:     "should return 400 when the query is empty",
+			target:   "/api/entry/search",
+			code:     http.StatusBadRequest,
+			contains: `"field":"q"`,
+		},
+		{
+			name:     "should return 400 when the limit is not a number",
+			target:   "/api/entry/search?q=notes&limit=ten",
+			code:     http.StatusBadRequest,
+			contains: `"field":"limit"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", test.target, nil)
+			rr := httptest.NewRecorder()
+			httpEntryHandler.Search(rr, req)
+
+			assert.EqualValues(t, test.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), test.contains)
+		})
+	}
+}
+
+func TestHTTPEntryHandler_Parse(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	due := time.Date(2021, 3, 11, 15, 0, 0, 0, time.UTC)
+	mockService.
+		On("Parse", mock.Anything, domain.EntryInput{Title: "Call dentist tomorrow 3pm #health !high"}).
+		Return(&domain.EntryInput{Title: "Call dentist", Priority: domain.PriorityHigh, Tags: []string{"#health"}, DueAt: &due}, nil)
+	mockService.
+		On("Parse", mock.Anything, domain.EntryInput{Title: "Buy milk +nowhere"}).
+		Return(nil, domain.NewValidationError("list", `no list named "nowhere"`))
+
+	tests := []struct {
+		name     string
+		payload  string
+		code     int
+		contains string
+	}{
+		{
+			name:     "should return 200 with the parsed entry",
+			payload:  `{"title": "Call dentist tomorrow 3pm #health !high"}`,
+			code:     http.StatusOK,
+			contains: `{"title":"Call dentist","description":"","priority":"high","tags":["#health"],"due_at":"2021-03-11T15:00:00Z"}`,
+		},
+		{
+			name:     "should return 400 when the list is unknown",
+			payload:  `{"title": "Buy milk +nowhere"}`,
+			code:     http.StatusBadRequest,
+			contains: `"field":"list"`,
+		},
+		{
+			name:     "should return 400 when the body is malformed",
+			payload:  `{"title": `,
+			code:     http.StatusBadRequest,
+			contains: `"error"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/entry/parse", strings.NewReader(test.payload))
+			rr := httptest.NewRecorder()
+			httpEntryHandler.Parse(rr, req)
+
+			assert.EqualValues(t, test.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), test.contains)
+		})
+	}
+}
+
+func TestHTTPEntryHandler_CreateQuick(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	mockService.
+		On("Create", mock.Anything, domain.EntryInput{Title: "Buy milk tomorrow", Quick: true}).
+		Return(&domain.Entry{ID: "id", Title: "Buy milk"}, nil)
+
+	req := httptest.NewRequest("POST", "/api/entry", strings.NewReader(`{"title": "Buy milk tomorrow", "quick": true}`))
+	rr := httptest.NewRecorder()
+	httpEntryHandler.Create(rr, req)
+
+	assert.EqualValues(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"title":"Buy milk"`)
+}
+
+func TestHTTPEntryHandler_Trash(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	deleted := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	mockService.
+		On("Trash", mock.Anything).
+		Return([]*domain.TrashedEntry{{
+			Entry:     &domain.Entry{ID: "release", Title: "Release"},
+			Subtasks:  []*domain.Entry{{ID: "notes", Title: "Write notes", ParentID: "release"}},
+			DeletedAt: deleted,
+			DeletedBy: "carol",
+		}}, nil)
+	mockService.
+		On("Restore", mock.Anything, "release").
+		Return(&domain.Entry{ID: "release", Version: 3, Title: "Release"}, nil)
+	mockService.
+		On("Restore", mock.Anything, "missing").
+		Return(nil, domain.NotFound("entry not found in trash"))
+	mockService.
+		On("Purge", mock.Anything, "release").
+		Return(nil)
+	mockService.
+		On("EmptyTrash", mock.Anything).
+		Return(nil)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/trash", httpEntryHandler.Trash).Methods("GET")
+	router.HandleFunc("/api/trash", httpEntryHandler.EmptyTrash).Methods("DELETE")
+	router.HandleFunc("/api/trash/{id}", httpEntryHandler.Purge).Methods("DELETE")
+	router.HandleFunc("/api/trash/{id}/restore", httpEntryHandler.Restore).Methods("POST")
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		code     int
+		contains string
+	}{
+		{
+			name:     "should return 200 with the trashed entries and their subtasks",
+			method:   "GET",
+			target:   "/api/trash",
+			code:     http.StatusOK,
+			contains: `"subtasks":[{"id":"notes"`,
+		},
+		{
+			name:     "should return 200 with the restored entry",
+			method:   "POST",
+			target:   "/api/trash/release/restore",
+			code:     http.StatusOK,
+			contains: `"version":3`,
+		},
+		{
+			name:     "should return 404 when restoring an entry missing from the trash",
+			method:   "POST",
+			target:   "/api/trash/missing/restore",
+			code:     http.StatusNotFound,
+			contains: `"error"`,
+		},
+		{
+			name:   "should return 200 when purging an entry",
+			method: "DELETE",
+			target: "/api/trash/release",
+			code:   http.StatusOK,
+		},
+		{
+			name:   "should return 200 when emptying the trash",
+			method: "DELETE",
+			target: "/api/trash",
+			code:   http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.target, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), test.contains)
+		})
+	}
+
+	req := httptest.NewRequest("POST", "/api/trash/release/restore", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.EqualValues(t, `"3"`, rr.Header().Get("ETag"))
+}
+
+func TestHTTPEntryHandler_History(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	at := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	mockService.
+		On("History", mock.Anything, "release").
+		Return([]*domain.Revision{{
+			EntryID: "release",
+			Number:  2,
+			Action:  domain.RevisionUpdated,
+			ActorID: "bob",
+			At:      at,
+			Changes: []domain.FieldChange{{Field: "title", From: json.RawMessage(`"Release"`), To: json.RawMessage(`"Ship release"`)}},
+		}}, nil)
+	mockService.
+		On("History", mock.Anything, "missing").
+		Return(nil, domain.NotFound("entry not found in repository"))
+	mockService.
+		On("Revert", mock.Anything, "release", 1, int64(0)).
+		Return(&domain.Entry{ID: "release", Version: 3, Title: "Release"}, nil)
+	mockService.
+		On("Revert", mock.Anything, "release", 1, int64(2)).
+		Return(&domain.Entry{ID: "release", Version: 3, Title: "Release"}, nil)
+	mockService.
+		On("Get", mock.Anything, "release").
+		Return(&domain.Entry{ID: "release", Version: 2, Title: "Ship release"}, nil)
+	mockService.
+		On("Revert", mock.Anything, "release", 2, int64(0)).
+		Return(nil, domain.NewValidationError("number", "revision records the deletion of the entry"))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/entry/{id}/history", httpEntryHandler.History).Methods("GET")
+	router.HandleFunc("/api/entry/{id}/history/{number}/revert", httpEntryHandler.Revert).Methods("POST")
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		match    string
+		code     int
+		contains string
+	}{
+		{
+			name:     "should return 200 with the revisions and their changes",
+			method:   "GET",
+			target:   "/api/entry/release/history",
+			code:     http.StatusOK,
+			contains: `"changes":[{"field":"title","from":"Release","to":"Ship release"}]`,
+		},
+		{
+			name:     "should return 404 when the entry is missing",
+			method:   "GET",
+			target:   "/api/entry/missing/history",
+			code:     http.StatusNotFound,
+			contains: `"error"`,
+		},
+		{
+			name:     "should return 200 with the reverted entry",
+			method:   "POST",
+			target:   "/api/entry/release/history/1/revert",
+			code:     http.StatusOK,
+			contains: `"version":3`,
+		},
+		{
+			name:     "should return 200 with the reverted entry when If-Match names the current version",
+			method:   "POST",
+			target:   "/api/entry/release/history/1/revert",
+			match:    `"2"`,
+			code:     http.StatusOK,
+			contains: `"version":3`,
+		},
+		{
+			name:     "should return 412 when If-Match names another version",
+			method:   "POST",
+			target:   "/api/entry/release/history/1/revert",
+			match:    `"1"`,
+			code:     http.StatusPreconditionFailed,
+			contains: `"error"`,
+		},
+		{
+			name:     "should return 400 when the revision cannot be reverted to",
+			method:   "POST",
+			target:   "/api/entry/release/history/2/revert",
+			code:     http.StatusBadRequest,
+			contains: `"field":"number"`,
+		},
+		{
+			name:     "should return 400 when the revision number is not a positive integer",
+			method:   "POST",
+			target:   "/api/entry/release/history/0/revert",
+			code:     http.StatusBadRequest,
+			contains: `"field":"number"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.target, nil)
+			if test.match != "" {
+				req.Header.Set("If-Match", test.match)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), test.contains)
+		})
+	}
+}
+
+func TestHTTPEntryHandler_Undo(t *testing.T) {
+	mockService, httpEntryHandler := setUp()
+	mockService.
+		On("Undo", mock.Anything).
+		Return(&domain.Operation{
+			Kind:    domain.OperationDelete,
+			EntryID: "release",
+			Entries: []*domain.Entry{{ID: "release", Version: 4, Title: "Release"}},
+		}, nil)
+	mockService.
+		On("Redo", mock.Anything).
+		Return(nil, domain.Conflict("nothing to redo"))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/undo", httpEntryHandler.Undo).Methods("POST")
+	router.HandleFunc("/api/redo", httpEntryHandler.Redo).Methods("POST")
+
+	tests := []struct {
+		name     string
+		target   string
+		code     int
+		contains string
+	}{
+		{
+			name:     "should return 200 with the undone operation",
+			target:   "/api/undo",
+			code:     http.StatusOK,
+			contains: `"kind":"delete","entry_id":"release"`,
+		},
+		{
+			name:     "should return 409 when there is nothing to redo",
+			target:   "/api/redo",
+			code:     http.StatusConflict,
+			contains: `nothing to redo`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", test.target, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), test.contains)
+		})
+	}
+}
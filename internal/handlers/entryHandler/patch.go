@@ -0,0 +1,85 @@
+package entryHandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/handlers/httpCommon"
+	"github.com/Nikym/go-todo/internal/handlers/jsonPatch"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// Media types accepted by Update.
+const (
+	jsonType       = "application/json"
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// patchTypes lists the patch formats advertised in the Accept-Patch header.
+var patchTypes = []string{mergePatchType, jsonPatchType}
+
+// immutableFields lists the fields of an entry that are maintained by the service and cannot be
+// changed by a patch.
+var immutableFields = []string{"id", "owner_id", "version", "occurrence", "created_at", "updated_at", "completed_at"}
+
+// bodyType returns the media type of the request body, treating a missing Content-Type as JSON.
+func bodyType(r *http.Request) (string, error) {
+	header := r.Header.Get("Content-Type")
+	if header == "" {
+		return jsonType, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return "", domain.NewValidationError("Content-Type", "is malformed")
+	}
+	switch mediaType {
+	case jsonType, mergePatchType, jsonPatchType:
+		return mediaType, nil
+	}
+	return "", fmt.Errorf("%w: %s", httpCommon.ErrUnsupportedMediaType, mediaType)
+}
+
+// patch applies a patch of the given media type to the JSON representation of the entry and
+// returns the patched entry. Patches changing immutable fields or adding unknown ones are rejected.
+func patch(entry *domain.Entry, mediaType string, body []byte) (*domain.Entry, error) {
+	doc, err := json.Marshal(entry)
+	if err != nil {
+		return nil, domain.Internal("encoding entry failed", err)
+	}
+
+	var patched []byte
+	if mediaType == mergePatchType {
+		patched, err = jsonPatch.Merge(doc, body)
+	} else {
+		patched, err = jsonPatch.Apply(doc, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(doc, &before); err != nil {
+		return nil, domain.Internal("decoding entry failed", err)
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, domain.NewValidationError("body", "must leave the entry a JSON object")
+	}
+	for _, field := range immutableFields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			return nil, domain.NewValidationError(field, "cannot be changed")
+		}
+	}
+
+	result := &domain.Entry{}
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(result); err != nil {
+		return nil, httpCommon.BodyError(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package entryHandler
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPEntryHandler_Patch(t *testing.T) {
+	due := time.Date(2021, 3, 4, 17, 30, 0, 0, time.UTC)
+	stored := func() *domain.Entry {
+		return &domain.Entry{
+			ID:       "a",
+			Version:  3,
+			OwnerID:  "alice",
+			Title:    "Release",
+			Priority: domain.PriorityHigh,
+			Tags:     []string{"work"},
+			DueAt:    &due,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    *domain.Entry
+		status      int
+	}{
+		{
+			name:        "should clear fields set to null by a merge patch",
+			contentType: "application/merge-patch+json",
+			body:        `{"title": "Ship release", "due_at": null, "tags": null}`,
+			expected:    &domain.Entry{ID: "a", Version: 3, OwnerID: "alice", Title: "Ship release", Priority: domain.PriorityHigh},
+			status:      http.StatusOK,
+		},
+		{
+			name:        "should apply the operations of a json patch",
+			contentType: "application/json-patch+json; charset=utf-8",
+			body: `[
+				{"op": "test", "path": "/version", "value": 3},
+				{"op": "add", "path": "/tags/-", "value": "release"},
+				{"op": "remove", "path": "/due_at"},
+				{"op": "replace", "path": "/done", "value": true}
+			]`,
+			expected: &domain.Entry{
+				ID: "a", Version: 3, OwnerID: "alice", Title: "Release", Priority: domain.PriorityHigh,
+				Tags: []string{"work", "release"}, Done: true,
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:        "should return Bad Request when a merge patch changes the id",
+			contentType: "application/merge-patch+json",
+			body:        `{"id": "b"}`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "should return Bad Request when a json patch removes the owner",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "remove", "path": "/owner_id"}]`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "should return Bad Request when a patch adds an unknown field",
+			contentType: "application/merge-patch+json",
+			body:        `{"colour": "red"}`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "should return Conflict when a json patch test fails",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "test", "path": "/version", "value": 2}]`,
+			status:      http.StatusConflict,
+		},
+		{
+			name:        "should return Unsupported Media Type for other formats",
+			contentType: "text/plain",
+			body:        `title=Ship release`,
+			status:      http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService, httpEntryHandler := setUp()
+			mockService.On("Get", mock.Anything, "a").Return(stored(), nil)
+			mockService.On("Update", mock.Anything, "a", mock.Anything).Return(nil)
+
+			req := httptest.NewRequest("PATCH", "/api/entry/a", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/entry/{id}", httpEntryHandler.Update)
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+			if test.expected != nil {
+				mockService.AssertCalled(t, "Update", mock.Anything, "a", test.expected)
+			} else {
+				mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Package httpCommon holds the helpers shared by the HTTP handlers: error responses and the
+// parsing of entry listing queries.
+package httpCommon
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Machine-readable error codes sent in the code field of error responses.
+const (
+	CodeNotFound        = "not_found"
+	CodeValidation      = "validation_failed"
+	CodeConflict        = "conflict"
+	CodeUnauthenticated = "unauthenticated"
+	CodeForbidden       = "forbidden"
+	CodePrecondition    = "precondition_failed"
+	CodeMediaType       = "unsupported_media_type"
+	CodeInternal        = "internal_error"
+)
+
+// ErrUnsupportedMediaType signals a request body in a format the endpoint does not accept.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// Response is the body of every error response.
+type Response struct {
+	Message string              `json:"message"`
+	Error   string              `json:"error"`
+	Code    string              `json:"code"`
+	Details []domain.FieldError `json:"details,omitempty"`
+}
+
+// ParseListQuery reads the filters, ordering and paging of an entry listing from the query
+// parameters of the request.
+func ParseListQuery(r *http.Request) (domain.ListQuery, error) {
+	values := r.URL.Query()
+	query := domain.ListQuery{
+		TitleContains: values.Get("title"),
+		Tags:          values["tag"],
+		SortBy:        domain.SortField(values.Get("sort")),
+		Order:         domain.SortOrder(values.Get("order")),
+		Cursor:        values.Get("cursor"),
+	}
+
+	if _, ok := values["parent"]; ok {
+		parent := values.Get("parent")
+		query.ParentID = &parent
+	}
+
+	if _, ok := values["list"]; ok {
+		list := values.Get("list")
+		query.ListID = &list
+	}
+
+	if done := values.Get("done"); done != "" {
+		parsed, err := strconv.ParseBool(done)
+		if err != nil {
+			return query, domain.NewValidationError("done", "must be either true or false")
+		}
+		query.Done = &parsed
+	}
+
+	if priority := values.Get("priority"); priority != "" {
+		parsed, err := domain.ParsePriority(priority)
+		if err != nil {
+			return query, err
+		}
+		query.Priority = &parsed
+	}
+
+	for param, target := range map[string]**time.Time{
+		"due_before": &query.DueBefore,
+		"due_after":  &query.DueAfter,
+	} {
+		if val := values.Get(param); val != "" {
+			parsed, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return query, domain.NewValidationError(param, "must be an RFC 3339 timestamp")
+			}
+			*target = &parsed
+		}
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			return query, domain.NewValidationError("limit", "must be a number")
+		}
+		query.Limit = parsed
+	}
+
+	return query, nil
+}
+
+// BodyError reports a request body that could not be decoded as a validation failure.
+func BodyError(err error) error {
+	return domain.NewValidationError("body", err.Error())
+}
+
+// ETag returns the entity tag identifying the given version of a resource.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// MatchETag reports whether the value of an If-Match or If-None-Match header is "*" or lists
+// etag. Weak entity tags only match when weak is set, as required for If-None-Match.
+func MatchETag(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorStatus maps an error to the HTTP status code and error code describing its category.
+func ErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, CodeNotFound
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusBadRequest, CodeValidation
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict, CodeConflict
+	case errors.Is(err, domain.ErrUnauthenticated):
+		return http.StatusUnauthorized, CodeUnauthenticated
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden, CodeForbidden
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, CodePrecondition
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType, CodeMediaType
+	default:
+		return http.StatusInternalServerError, CodeInternal
+	}
+}
+
+// SendErrorResponse writes an error response describing err, with a status code matching its
+// category and the field errors of validation failures.
+func SendErrorResponse(w http.ResponseWriter, message string, err error) {
+	status, code := ErrorStatus(err)
+	res := Response{Message: message, Error: err.Error(), Code: code}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		res.Details = validationErr.Fields
+	}
+
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		panic(err)
+	}
+}
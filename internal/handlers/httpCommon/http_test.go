@@ -0,0 +1,138 @@
+package httpCommon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendErrorResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		details []domain.FieldError
+	}{
+		{
+			name:   "should return Not Found for not found errors",
+			err:    domain.NotFound("entry not found"),
+			status: http.StatusNotFound,
+			code:   CodeNotFound,
+		},
+		{
+			name:    "should return Bad Request with field details for validation errors",
+			err:     domain.NewValidationError("title", "too short"),
+			status:  http.StatusBadRequest,
+			code:    CodeValidation,
+			details: []domain.FieldError{{Field: "title", Message: "too short"}},
+		},
+		{
+			name:   "should return Conflict for conflict errors",
+			err:    fmt.Errorf("saving: %w", domain.Conflict("already exists")),
+			status: http.StatusConflict,
+			code:   CodeConflict,
+		},
+		{
+			name:   "should return Unauthorized for unauthenticated errors",
+			err:    domain.Unauthenticated("authentication required"),
+			status: http.StatusUnauthorized,
+			code:   CodeUnauthenticated,
+		},
+		{
+			name:   "should return Forbidden for forbidden errors",
+			err:    domain.Forbidden("requires the editor role on the entry"),
+			status: http.StatusForbidden,
+			code:   CodeForbidden,
+		},
+		{
+			name:   "should return Precondition Failed for outdated versions",
+			err:    domain.PreconditionFailed("entry has changed since version 2"),
+			status: http.StatusPreconditionFailed,
+			code:   CodePrecondition,
+		},
+		{
+			name:   "should return Unsupported Media Type for bodies in unknown formats",
+			err:    ErrUnsupportedMediaType,
+			status: http.StatusUnsupportedMediaType,
+			code:   CodeMediaType,
+		},
+		{
+			name:   "should return Internal Server Error for internal errors",
+			err:    domain.Internal("saving failed", errors.New("disk full")),
+			status: http.StatusInternalServerError,
+			code:   CodeInternal,
+		},
+		{
+			name:   "should return Internal Server Error for uncategorised errors",
+			err:    errors.New("unknown"),
+			status: http.StatusInternalServerError,
+			code:   CodeInternal,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			SendErrorResponse(rr, "failed", test.err)
+
+			var res Response
+			if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+				panic(err)
+			}
+			assert.EqualValues(t, test.status, rr.Code)
+			assert.EqualValues(t, test.code, res.Code)
+			assert.EqualValues(t, test.details, res.Details)
+		})
+	}
+}
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		weak     bool
+		expected bool
+	}{
+		{
+			name:     "should match the same entity tag",
+			header:   `"3"`,
+			expected: true,
+		},
+		{
+			name:     "should match any entity tag when header is a wildcard",
+			header:   `*`,
+			expected: true,
+		},
+		{
+			name:     "should match an entity tag within a list",
+			header:   `"1", "3"`,
+			expected: true,
+		},
+		{
+			name:   "should not match another entity tag",
+			header: `"2"`,
+		},
+		{
+			name:   "should not match a weak entity tag with strong comparison",
+			header: `W/"3"`,
+		},
+		{
+			name:     "should match a weak entity tag with weak comparison",
+			header:   `W/"3"`,
+			weak:     true,
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualValues(t, test.expected, MatchETag(test.header, ETag(3), test.weak))
+		})
+	}
+}
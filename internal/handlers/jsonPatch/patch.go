@@ -0,0 +1,391 @@
+// Package jsonPatch applies JSON Merge Patches (RFC 7396) and JSON Patches (RFC 6902) to JSON
+// documents. Malformed patches are reported as validation errors, and patches that do not fit
+// the document, such as those removing a missing member or failing a test, as conflicts.
+package jsonPatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"strconv"
+	"strings"
+)
+
+// Operation is a single operation of a JSON Patch.
+type Operation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// Merge applies the JSON Merge Patch to the document and returns the patched document.
+func Merge(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, domain.Internal("decoding document failed", err)
+	}
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, domain.NewValidationError("body", "must be a JSON document")
+	}
+
+	return json.Marshal(merge(target, changes))
+}
+
+// Apply applies the operations of the JSON Patch to the document, in order, and returns the
+// patched document. Either every operation is applied or, when one of them fails, none is.
+func Apply(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, domain.Internal("decoding document failed", err)
+	}
+
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, domain.NewValidationError("body", "must be an array of JSON Patch operations")
+	}
+	for i, op := range ops {
+		if target, err = apply(target, op); err != nil {
+			return nil, describe(i, err)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func decode(doc []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// merge returns target with patch merged into it, following RFC 7396: objects are merged member
+// by member, null members are removed and every other value replaces the target.
+func merge(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	merged, ok := target.(map[string]interface{})
+	if !ok {
+		merged = map[string]interface{}{}
+	}
+
+	for name, value := range changes {
+		if value == nil {
+			delete(merged, name)
+			continue
+		}
+		merged[name] = merge(merged[name], value)
+	}
+	return merged
+}
+
+// apply returns doc with the operation applied.
+func apply(doc interface{}, op Operation) (interface{}, error) {
+	if op.Path == nil {
+		return nil, domain.NewValidationError("path", "is required")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, domain.NewValidationError("value", "is required")
+		}
+		value, err := decode(*op.Value)
+		if err != nil {
+			return nil, domain.NewValidationError("value", "must be a JSON value")
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if _, err := remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !equal(current, value) {
+				return nil, domain.Conflict("value at " + *op.Path + " differs from the tested value")
+			}
+			return doc, nil
+		}
+	case "remove":
+		if len(path) == 0 {
+			return nil, domain.NewValidationError("path", "cannot remove the whole document")
+		}
+		return remove(doc, path)
+	case "move", "copy":
+		if op.From == nil {
+			return nil, domain.NewValidationError("from", "is required")
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return add(doc, path, clone(value))
+		}
+		if isPrefix(from, path) {
+			if len(from) == len(path) {
+				return doc, nil
+			}
+			return nil, domain.NewValidationError("from", "cannot move a value into itself")
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default:
+		return nil, domain.NewValidationError("op", "must be one of add, remove, replace, move, copy or test")
+	}
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, domain.NewValidationError("path", "must be empty or start with a slash")
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for i, token := range tokens {
+		tokens[i] = unescape.Replace(token)
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the value at path.
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, missing(token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, missing(token)
+		}
+	}
+	return doc, nil
+}
+
+// add returns doc with value added at path: object members are set, and array elements are
+// inserted before the given index or appended for "-".
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			i := len(node)
+			if token != "-" {
+				var err error
+				if i, err = index(token, len(node)); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		default:
+			return nil, missing(token)
+		}
+	})
+}
+
+// remove returns doc without the value at path, which must exist.
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	return modify(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, missing(token)
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i:i], node[i+1:]...), nil
+		default:
+			return nil, missing(token)
+		}
+	})
+}
+
+// modify walks doc down to the container holding the last token of path, replaces it by the
+// result of fn and returns the modified document.
+func modify(doc interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	token := path[0]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, missing(token)
+		}
+		updated, err := modify(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = updated
+		return node, nil
+	case []interface{}:
+		i, err := index(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := modify(node[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = updated
+		return node, nil
+	default:
+		return nil, missing(token)
+	}
+}
+
+// index parses an array index token, which must not exceed max.
+func index(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.Trim(token, "0123456789") != "" {
+		return 0, domain.NewValidationError("path", "array index "+strconv.Quote(token)+" is not a number")
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i > max {
+		return 0, domain.Conflict("array index " + token + " is out of bounds")
+	}
+	return i, nil
+}
+
+// equal reports whether two decoded JSON values are equal, comparing numbers by value.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		other, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(other) {
+			return false
+		}
+		for name, value := range a {
+			if otherValue, ok := other[name]; !ok || !equal(value, otherValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		other, ok := b.([]interface{})
+		if !ok || len(a) != len(other) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], other[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		other, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := other.Float64()
+		return errA == nil && errB == nil && x == y
+	default:
+		return a == b
+	}
+}
+
+// clone returns a deep copy of a decoded JSON value.
+func clone(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for name, value := range v {
+			c[name] = clone(value)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, value := range v {
+			c[i] = clone(value)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+func missing(token string) error {
+	return domain.Conflict("member " + strconv.Quote(token) + " does not exist")
+}
+
+// describe prefixes the message of err with the position of the failing operation.
+func describe(i int, err error) error {
+	prefix := "operation " + strconv.Itoa(i) + ": "
+	if validationErr, ok := err.(*domain.ValidationError); ok {
+		fields := make([]domain.FieldError, len(validationErr.Fields))
+		for j, field := range validationErr.Fields {
+			fields[j] = domain.FieldError{Field: field.Field, Message: prefix + field.Message}
+		}
+		return &domain.ValidationError{Fields: fields}
+	}
+	if domainErr, ok := err.(*domain.Error); ok {
+		return &domain.Error{Kind: domainErr.Kind, Message: prefix + domainErr.Message, Err: domainErr.Err}
+	}
+	return err
+}
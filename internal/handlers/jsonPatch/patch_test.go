@@ -0,0 +1,180 @@
+package jsonPatch
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "should replace and add members",
+			doc:      `{"a": "b", "c": {"d": "e"}}`,
+			patch:    `{"a": "z", "c": {"f": "g"}}`,
+			expected: `{"a": "z", "c": {"d": "e", "f": "g"}}`,
+		},
+		{
+			name:     "should remove members set to null",
+			doc:      `{"a": "b", "c": {"d": "e", "f": "g"}}`,
+			patch:    `{"a": null, "c": {"f": null}}`,
+			expected: `{"c": {"d": "e"}}`,
+		},
+		{
+			name:     "should replace arrays as a whole",
+			doc:      `{"a": [{"b": "c"}]}`,
+			patch:    `{"a": [1]}`,
+			expected: `{"a": [1]}`,
+		},
+		{
+			name:     "should replace the document when patch is not an object",
+			doc:      `{"a": "b"}`,
+			patch:    `["c"]`,
+			expected: `["c"]`,
+		},
+		{
+			name:  "should return validation error when patch is not json",
+			doc:   `{"a": "b"}`,
+			patch: `a=b`,
+			err:   domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := Merge([]byte(test.doc), []byte(test.patch))
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(patched))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "should add an object member",
+			doc:      `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			expected: `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:     "should insert and append array elements",
+			doc:      `{"foo": ["bar", "baz"]}`,
+			patch:    `[{"op": "add", "path": "/foo/1", "value": "qux"}, {"op": "add", "path": "/foo/-", "value": "end"}]`,
+			expected: `{"foo": ["bar", "qux", "baz", "end"]}`,
+		},
+		{
+			name:     "should remove an array element",
+			doc:      `{"foo": ["bar", "qux", "baz"]}`,
+			patch:    `[{"op": "remove", "path": "/foo/1"}]`,
+			expected: `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:     "should replace a value",
+			doc:      `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			expected: `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:     "should move a value",
+			doc:      `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch:    `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			expected: `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:     "should copy a value",
+			doc:      `{"foo": ["a"]}`,
+			patch:    `[{"op": "copy", "from": "/foo", "path": "/bar"}, {"op": "add", "path": "/bar/-", "value": "b"}]`,
+			expected: `{"foo": ["a"], "bar": ["a", "b"]}`,
+		},
+		{
+			name:     "should unescape pointers",
+			doc:      `{"a/b": 1, "m~n": 2}`,
+			patch:    `[{"op": "remove", "path": "/a~1b"}, {"op": "replace", "path": "/m~0n", "value": 3}]`,
+			expected: `{"m~n": 3}`,
+		},
+		{
+			name:     "should pass a test comparing numbers by value",
+			doc:      `{"version": 3, "tags": ["a"]}`,
+			patch:    `[{"op": "test", "path": "/version", "value": 3.0}, {"op": "test", "path": "/tags", "value": ["a"]}]`,
+			expected: `{"version": 3, "tags": ["a"]}`,
+		},
+		{
+			name:  "should return conflict when a test fails",
+			doc:   `{"version": 3}`,
+			patch: `[{"op": "test", "path": "/version", "value": 2}]`,
+			err:   domain.ErrConflict,
+		},
+		{
+			name:  "should return conflict when removing a missing member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			err:   domain.ErrConflict,
+		},
+		{
+			name:  "should return conflict when adding below a missing member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			err:   domain.ErrConflict,
+		},
+		{
+			name:  "should return conflict when array index is out of bounds",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/2", "value": "qux"}]`,
+			err:   domain.ErrConflict,
+		},
+		{
+			name:  "should return validation error when operation is unknown",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "merge", "path": "/foo", "value": "baz"}]`,
+			err:   domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when value is missing",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz"}]`,
+			err:   domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when moving a value into itself",
+			doc:   `{"foo": {"bar": {}}}`,
+			patch: `[{"op": "move", "from": "/foo", "path": "/foo/bar/baz"}]`,
+			err:   domain.ErrValidation,
+		},
+		{
+			name:  "should return validation error when patch is not an array",
+			doc:   `{"foo": "bar"}`,
+			patch: `{"op": "remove", "path": "/foo"}`,
+			err:   domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := Apply([]byte(test.doc), []byte(test.patch))
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(patched))
+		})
+	}
+}
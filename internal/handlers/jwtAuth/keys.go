@@ -0,0 +1,205 @@
+package jwtAuth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// jwk is a single key of a JSON Web Key Set (RFC 7517). Only symmetric ("oct") keys for HS256
+// and RSA public keys for RS256 are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// key is a parsed verification key along with the algorithm it verifies.
+type key struct {
+	alg string
+	key interface{}
+}
+
+// KeySet holds the verification keys read from a JWKS file. The file is read again whenever it
+// changes, so keys can be rotated without restarting the server.
+type KeySet struct {
+	path string
+
+	mu      sync.RWMutex
+	keys    map[string]key
+	modTime time.Time
+	size    int64
+}
+
+// NewKeySet returns a pointer to the key set stored in the JWKS file at path.
+func NewKeySet(path string) (*KeySet, error) {
+	ks := &KeySet{path: path}
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Reload reads the JWKS file again if it changed since it was last read. The keys read before
+// are kept when the file cannot be read or holds an invalid key.
+func (ks *KeySet) Reload() error {
+	info, err := os.Stat(ks.path)
+	if err != nil {
+		return fmt.Errorf("reading key set: %w", err)
+	}
+
+	ks.mu.RLock()
+	unchanged := ks.keys != nil && info.ModTime().Equal(ks.modTime) && info.Size() == ks.size
+	ks.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	bytes, err := os.ReadFile(ks.path)
+	if err != nil {
+		return fmt.Errorf("reading key set: %w", err)
+	}
+	keys, err := parseKeySet(bytes)
+	if err != nil {
+		return fmt.Errorf("parsing key set %s: %w", ks.path, err)
+	}
+
+	ks.mu.Lock()
+	ks.keys, ks.modTime, ks.size = keys, info.ModTime(), info.Size()
+	ks.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the key set every interval until ctx is done, reporting failed reloads to onError.
+func (ks *KeySet) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ks.Reload(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// lookup returns the key with the given ID verifying alg. Tokens without a key ID may only be
+// verified when the set holds a single key for their algorithm.
+func (ks *KeySet) lookup(kid, alg string) (interface{}, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if kid != "" {
+		k, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		if k.alg != alg {
+			return nil, fmt.Errorf("key %q does not verify %s", kid, alg)
+		}
+		return k.key, nil
+	}
+
+	var found interface{}
+	for _, k := range ks.keys {
+		if k.alg != alg {
+			continue
+		}
+		if found != nil {
+			return nil, errors.New("token names no key and several keys match")
+		}
+		found = k.key
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no key verifies %s", alg)
+	}
+	return found, nil
+}
+
+func parseKeySet(bytes []byte) (map[string]key, error) {
+	set := jwks{}
+	if err := json.Unmarshal(bytes, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]key, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		parsed, err := parseKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		kid := k.Kid
+		if kid == "" {
+			kid = fmt.Sprintf("#%d", i)
+		}
+		if _, ok := keys[kid]; ok {
+			return nil, fmt.Errorf("key %d: duplicate key id %q", i, kid)
+		}
+		keys[kid] = parsed
+	}
+	return keys, nil
+}
+
+func parseKey(k jwk) (key, error) {
+	switch k.Kty {
+	case "oct":
+		if k.Alg != "" && k.Alg != "HS256" {
+			return key{}, fmt.Errorf("unsupported algorithm %q", k.Alg)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return key{}, fmt.Errorf("decoding k: %w", err)
+		}
+		if len(secret) < 32 {
+			return key{}, errors.New("HS256 keys must be at least 256 bits long")
+		}
+		return key{alg: "HS256", key: secret}, nil
+	case "RSA":
+		if k.Alg != "" && k.Alg != "RS256" {
+			return key{}, fmt.Errorf("unsupported algorithm %q", k.Alg)
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return key{}, fmt.Errorf("decoding n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return key{}, fmt.Errorf("decoding e: %w", err)
+		}
+		if len(n) < 256 {
+			return key{}, errors.New("RSA keys must be at least 2048 bits long")
+		}
+		if len(e) == 0 || len(e) > 4 {
+			return key{}, errors.New("invalid RSA exponent")
+		}
+		return key{alg: "RS256", key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	default:
+		return key{}, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
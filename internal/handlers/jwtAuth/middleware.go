@@ -0,0 +1,143 @@
+// Package jwtAuth authenticates requests carrying a JSON Web Token as bearer token, for callers
+// such as other services that cannot hold an API token of a user.
+package jwtAuth
+
+import (
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/handlers/httpCommon"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultLeeway is the clock skew allowed when checking the validity period of a token.
+const DefaultLeeway = 30 * time.Second
+
+// claims are the claims read from a token. The subject names the user the caller acts for.
+type claims struct {
+	jwt.RegisteredClaims
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// Verifier checks JWTs against the keys of a KeySet.
+type Verifier struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// NewVerifier returns a pointer to a verifier of the tokens signed by the keys of the given set.
+func NewVerifier(keys *KeySet, opts ...Option) *Verifier {
+	v := &Verifier{
+		keys:   keys,
+		leeway: DefaultLeeway,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Authenticate is a middleware authenticating requests whose bearer token is a JWT, signed with
+// HS256 or RS256 by a key of the set. The token must carry a subject and an expiry time. The
+// identity of the subject is added to the request context; other requests are passed on
+// untouched, for the authentication middleware of users to handle.
+func (v *Verifier) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := bearerJWT(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, err := v.Verify(raw)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="todo", error="invalid_token"`)
+			httpCommon.SendErrorResponse(w, "failed to authenticate request", err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.WithIdentity(r.Context(), identity)))
+	})
+}
+
+// Verify checks the signature and claims of a token, returning the identity of its subject.
+func (v *Verifier) Verify(raw string) (domain.Identity, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.leeway),
+		jwt.WithTimeFunc(v.now),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	c := claims{}
+	if _, err := jwt.ParseWithClaims(raw, &c, v.keyFunc, opts...); err != nil {
+		return domain.Identity{}, domain.Unauthenticated("invalid token: " + reason(err))
+	}
+	if c.Subject == "" {
+		return domain.Identity{}, domain.Unauthenticated("invalid token: subject is missing")
+	}
+
+	username := c.PreferredUsername
+	if username == "" {
+		username = c.Subject
+	}
+	return domain.Identity{UserID: c.Subject, Username: username}, nil
+}
+
+// keyFunc returns the key verifying the token. Unknown key IDs make the key set reload first,
+// so that tokens signed with a newly added key are accepted right away.
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	alg := token.Method.Alg()
+
+	key, err := v.keys.lookup(kid, alg)
+	if err != nil && kid != "" {
+		if reloadErr := v.keys.Reload(); reloadErr == nil {
+			key, err = v.keys.lookup(kid, alg)
+		}
+	}
+	return key, err
+}
+
+// bearerJWT returns the bearer token of the request if it has the compact form of a JWT.
+func bearerJWT(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, strings.Count(token, ".") == 2
+}
+
+// reason describes why a token was rejected without echoing any part of it.
+func reason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "token is expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return "token is not valid yet"
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return "expiry time is missing"
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return "issuer is not accepted"
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return "audience is not accepted"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return "signature cannot be verified"
+	default:
+		return "token is malformed"
+	}
+}
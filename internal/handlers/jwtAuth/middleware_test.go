@@ -0,0 +1,214 @@
+package jwtAuth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	now    = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	secret = []byte("0123456789abcdef0123456789abcdef")
+)
+
+// writeKeySet writes a JWKS file holding the given keys, moving its modification time forward
+// so that every write is noticed.
+func writeKeySet(t *testing.T, path string, keys ...jwk) {
+	bytes, err := json.Marshal(jwks{Keys: keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, bytes, 0600))
+
+	modTime := time.Now().Add(time.Duration(len(keys)) * time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func octKey(kid string, secret []byte) jwk {
+	return jwk{Kty: "oct", Kid: kid, Alg: "HS256", K: base64.RawURLEncoding.EncodeToString(secret)}
+}
+
+func rsaKey(kid string, key *rsa.PublicKey) jwk {
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestVerifier_Authenticate(t *testing.T) {
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeKeySet(t, path, octKey("hmac", secret), rsaKey("rsa", &rsaPrivate.PublicKey))
+	keys, err := NewKeySet(path)
+	require.NoError(t, err)
+
+	verifier := NewVerifier(keys, WithIssuer("billing"), WithAudience("todo"), WithClock(func() time.Time { return now }))
+	handler := verifier.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity, ok := domain.IdentityFrom(r.Context()); ok {
+			w.Header().Set("X-User", identity.UserID)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{"sub": "alice", "iss": "billing", "aud": "todo", "exp": now.Add(time.Hour).Unix()}
+	}
+	with := func(key string, val interface{}) jwt.MapClaims {
+		claims := valid()
+		if val == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = val
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+		user   string
+	}{
+		{
+			name:   "should pass on the subject of a valid HS256 token",
+			token:  sign(t, jwt.SigningMethodHS256, "hmac", secret, valid()),
+			status: http.StatusOK,
+			user:   "alice",
+		},
+		{
+			name:   "should pass on the subject of a valid RS256 token",
+			token:  sign(t, jwt.SigningMethodRS256, "rsa", rsaPrivate, valid()),
+			status: http.StatusOK,
+			user:   "alice",
+		},
+		{
+			name:   "should pass on requests without a JWT untouched",
+			token:  "todo_apitoken",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Unauthorized when token is expired",
+			token:  sign(t, jwt.SigningMethodHS256, "hmac", secret, with("exp", now.Add(-time.Hour).Unix())),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when token has no expiry time",
+			token:  sign(t, jwt.SigningMethodHS256, "hmac", secret, with("exp", nil)),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when token has no subject",
+			token:  sign(t, jwt.SigningMethodHS256, "hmac", secret, with("sub", nil)),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when issuer is not accepted",
+			token:  sign(t, jwt.SigningMethodHS256, "hmac", secret, with("iss", "mallory")),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when audience is not accepted",
+			token:  sign(t, jwt.SigningMethodHS256, "hmac", secret, with("aud", "billing")),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when signed with another secret",
+			token:  sign(t, jwt.SigningMethodHS256, "hmac", []byte("another secret, just as long as it"), valid()),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when algorithm does not match the key",
+			token:  sign(t, jwt.SigningMethodHS256, "rsa", secret, valid()),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when token is unsigned",
+			token:  sign(t, jwt.SigningMethodNone, "hmac", jwt.UnsafeAllowNoneSignatureType, valid()),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when key is unknown",
+			token:  sign(t, jwt.SigningMethodHS256, "retired", secret, valid()),
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/entry", nil)
+			req.Header.Set("Authorization", "Bearer "+test.token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+			assert.EqualValues(t, test.user, rr.Header().Get("X-User"))
+			if test.status == http.StatusUnauthorized {
+				assert.NotEmpty(t, rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestKeySet_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeKeySet(t, path, octKey("2021-01", secret))
+	keys, err := NewKeySet(path)
+	require.NoError(t, err)
+	verifier := NewVerifier(keys, WithClock(func() time.Time { return now }))
+
+	rotated := []byte("fedcba9876543210fedcba9876543210")
+	claims := jwt.MapClaims{"sub": "billing", "exp": now.Add(time.Hour).Unix()}
+
+	t.Run("should accept tokens signed with a key added to the file", func(t *testing.T) {
+		writeKeySet(t, path, octKey("2021-01", secret), octKey("2021-02", rotated))
+
+		identity, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, "2021-02", rotated, claims))
+		require.NoError(t, err)
+		assert.EqualValues(t, "billing", identity.UserID)
+	})
+
+	t.Run("should reject tokens signed with a key removed from the file", func(t *testing.T) {
+		writeKeySet(t, path, octKey("2021-02", rotated))
+		require.NoError(t, keys.Reload())
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, "2021-01", secret, claims))
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+	})
+
+	t.Run("should keep the previous keys when the file becomes invalid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+		assert.Error(t, keys.Reload())
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, "2021-02", rotated, claims))
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject keys too short for their algorithm", func(t *testing.T) {
+		writeKeySet(t, path, octKey("short", []byte("short")))
+		assert.Error(t, keys.Reload())
+	})
+}
@@ -0,0 +1,34 @@
+package jwtAuth
+
+import "time"
+
+// Option configures optional behaviour of the verifier.
+type Option func(v *Verifier)
+
+// WithIssuer only accepts tokens issued by the given issuer.
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience only accepts tokens meant for the given audience.
+func WithAudience(audience string) Option {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// WithLeeway sets the clock skew allowed when checking the validity period of a token.
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+// WithClock makes the verifier read the current time from now instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(v *Verifier) {
+		v.now = now
+	}
+}
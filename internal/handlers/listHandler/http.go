@@ -0,0 +1,188 @@
+package listHandler
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/handlers/httpCommon"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+type moveJSON struct {
+	EntryID string `json:"entry_id"`
+}
+
+type HTTPListHandler struct {
+	ListService ports.ListService
+}
+
+// NewHTTPListHandler returns a pointer to the HTTP adapter for the ports.ListService interface.
+func NewHTTPListHandler(listService ports.ListService) *HTTPListHandler {
+	return &HTTPListHandler{
+		ListService: listService,
+	}
+}
+
+// Get handles retrieval of a list through HTTP with a specified UUID within the URL.
+func (h *HTTPListHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	list, err := h.ListService.Get(r.Context(), id)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to retrieve list with given ID", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		panic(err)
+	}
+}
+
+// Create handles the creation of a new list through HTTP with given name and description within body.
+func (h *HTTPListHandler) Create(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	var details domain.ListInput
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	list, err := h.ListService.Create(r.Context(), details)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to create list", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		panic(err)
+	}
+}
+
+// Update updates the list specified by the ID with the new values given in the body.
+func (h *HTTPListHandler) Update(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	list, err := h.ListService.Get(r.Context(), id)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to find list with given id", err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(list); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	if err := h.ListService.Update(r.Context(), id, list); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to update list", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Delete removes a list with a given ID through HTTP. The entries of a list that still has some
+// are deleted along with it when the cascade query parameter is true, or moved to the list named
+// by the reassign_to query parameter.
+func (h *HTTPListHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	opts := domain.ListDeleteOptions{ReassignTo: r.URL.Query().Get("reassign_to")}
+	if cascade := r.URL.Query().Get("cascade"); cascade != "" {
+		parsed, err := strconv.ParseBool(cascade)
+		if err != nil {
+			httpCommon.SendErrorResponse(w, "failed to parse query parameters", domain.NewValidationError("cascade", "must be either true or false"))
+			return
+		}
+		opts.Cascade = parsed
+	}
+
+	if err := h.ListService.Delete(r.Context(), id, opts); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to delete list with given id", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// List handles retrieval of every list, ordered by name.
+func (h *HTTPListHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	lists, err := h.ListService.List(r.Context())
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to list lists", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(lists); err != nil {
+		panic(err)
+	}
+}
+
+// Entries handles retrieval of a page of the entries of the list with the ID specified in the URL,
+// accepting the same query parameters as the entry listing.
+func (h *HTTPListHandler) Entries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	query, err := httpCommon.ParseListQuery(r)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to parse query parameters", err)
+		return
+	}
+
+	page, err := h.ListService.Entries(r.Context(), id, query)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to list entries of list", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		panic(err)
+	}
+}
+
+// Move handles moving the entry given in the body, along with its subtasks, to the list with the
+// ID specified in the URL.
+func (h *HTTPListHandler) Move(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var details moveJSON
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	entry, err := h.ListService.Move(r.Context(), details.EntryID, id)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to move entry to list", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,176 @@
+package listHandler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	mocks "github.com/Nikym/go-todo/mocks/core/ports"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setUp() (*mocks.ListService, *HTTPListHandler) {
+	mockService := &mocks.ListService{}
+	httpListHandler := NewHTTPListHandler(mockService)
+	return mockService, httpListHandler
+}
+
+func TestHTTPListHandler_Create(t *testing.T) {
+	mockService, httpListHandler := setUp()
+	mockService.
+		On("Create", mock.Anything, domain.ListInput{Name: "Groceries"}).
+		Return(&domain.List{ID: "1d126f09-4daf-447e-aaab-74765d8aefa2", Name: "Groceries"}, nil)
+	mockService.
+		On("Create", mock.Anything, domain.ListInput{Name: "Sprint"}).
+		Return(&domain.List{}, domain.Conflict("a list with the same name already exists"))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name:   "should return OK when list is created",
+			body:   `{"name": "Groceries"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Conflict when name is taken",
+			body:   `{"name": "Sprint"}`,
+			status: http.StatusConflict,
+		},
+		{
+			name:   "should return Bad Request when body is not json",
+			body:   `name`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/list", strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+			httpListHandler.Create(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
+
+func TestHTTPListHandler_Delete(t *testing.T) {
+	mockService, httpListHandler := setUp()
+	mockService.
+		On("Delete", mock.Anything, "sprint", domain.ListDeleteOptions{}).
+		Return(domain.Conflict("list still has entries"))
+	mockService.
+		On("Delete", mock.Anything, "sprint", domain.ListDeleteOptions{Cascade: true}).
+		Return(nil)
+	mockService.
+		On("Delete", mock.Anything, "sprint", domain.ListDeleteOptions{ReassignTo: "backlog"}).
+		Return(nil)
+	mockService.
+		On("Delete", mock.Anything, "invalid", domain.ListDeleteOptions{}).
+		Return(errors.New("invalid"))
+	mockService.
+		On("Delete", mock.Anything, "missing", domain.ListDeleteOptions{}).
+		Return(domain.NotFound("list not found"))
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		status int
+	}{
+		{
+			name:   "should return Conflict when list has entries and no option is given",
+			id:     "sprint",
+			status: http.StatusConflict,
+		},
+		{
+			name:   "should return OK when cascading",
+			id:     "sprint",
+			query:  "?cascade=true",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return OK when reassigning",
+			id:     "sprint",
+			query:  "?reassign_to=backlog",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Bad Request when cascade is not a boolean",
+			id:     "sprint",
+			query:  "?cascade=maybe",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should return Not Found when the list does not exist",
+			id:     "missing",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "should return Internal Server Error when deletion fails unexpectedly",
+			id:     "invalid",
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/api/list/%s%s", test.id, test.query), nil)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/list/{id}", httpListHandler.Delete)
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
+
+func TestHTTPListHandler_Move(t *testing.T) {
+	mockService, httpListHandler := setUp()
+	mockService.
+		On("Move", mock.Anything, "a", "sprint").
+		Return(&domain.Entry{ID: "a", ListID: "sprint"}, nil)
+	mockService.
+		On("Move", mock.Anything, "a", "missing").
+		Return(&domain.Entry{}, domain.NotFound("list not found"))
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{
+			name:   "should return OK when entry is moved",
+			id:     "sprint",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Not Found when list does not exist",
+			id:     "missing",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", fmt.Sprintf("/api/list/%s/entries", test.id), strings.NewReader(`{"entry_id": "a"}`))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/list/{id}/entries", httpListHandler.Move)
+			router.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package userHandler
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/handlers/httpCommon"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+type tokenInputJSON struct {
+	Name string `json:"name"`
+}
+
+// tokenJSON is a newly created API token along with its secret, which is only ever sent once.
+type tokenJSON struct {
+	*domain.Token
+	Secret string `json:"secret"`
+}
+
+type HTTPUserHandler struct {
+	UserService ports.UserService
+}
+
+// NewHTTPUserHandler returns a pointer to the HTTP adapter for the ports.UserService interface.
+func NewHTTPUserHandler(userService ports.UserService) *HTTPUserHandler {
+	return &HTTPUserHandler{
+		UserService: userService,
+	}
+}
+
+// Register handles the creation of a new user through HTTP with given username and password
+// within body. It is the only endpoint reachable without authentication.
+func (h *HTTPUserHandler) Register(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	var details domain.UserInput
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	user, err := h.UserService.Register(details)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to register user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		panic(err)
+	}
+}
+
+// Me handles retrieval of the authenticated user.
+func (h *HTTPUserHandler) Me(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	user, err := h.UserService.Me(r.Context())
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to retrieve user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		panic(err)
+	}
+}
+
+// CreateToken handles the creation of an API token for the authenticated user with the name
+// given within body. The response carries the secret of the token, which cannot be retrieved
+// again.
+func (h *HTTPUserHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	var details tokenInputJSON
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	token, secret, err := h.UserService.CreateToken(r.Context(), details.Name)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to create token", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(tokenJSON{Token: token, Secret: secret}); err != nil {
+		panic(err)
+	}
+}
+
+// Tokens handles retrieval of every API token of the authenticated user.
+func (h *HTTPUserHandler) Tokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	tokens, err := h.UserService.Tokens(r.Context())
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to list tokens", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+		panic(err)
+	}
+}
+
+// RevokeToken deletes the API token of the authenticated user with the ID specified in the URL.
+func (h *HTTPUserHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.UserService.RevokeToken(r.Context(), id); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to revoke token", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
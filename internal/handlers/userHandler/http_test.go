@@ -0,0 +1,123 @@
+package userHandler
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	mocks "github.com/Nikym/go-todo/mocks/core/ports"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setUp() (*mocks.UserService, *HTTPUserHandler) {
+	mockService := &mocks.UserService{}
+	httpUserHandler := NewHTTPUserHandler(mockService)
+	return mockService, httpUserHandler
+}
+
+func TestHTTPUserHandler_Register(t *testing.T) {
+	mockService, httpUserHandler := setUp()
+	mockService.
+		On("Register", domain.UserInput{Username: "alice", Password: "correct horse"}).
+		Return(&domain.User{ID: "alice-id", Username: "alice", PasswordHash: []byte("secret hash")}, nil)
+	mockService.
+		On("Register", domain.UserInput{Username: "bob", Password: "correct horse"}).
+		Return(&domain.User{}, domain.Conflict("username is already taken"))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name:   "should return Created when user is registered",
+			body:   `{"username": "alice", "password": "correct horse"}`,
+			status: http.StatusCreated,
+		},
+		{
+			name:   "should return Conflict when username is taken",
+			body:   `{"username": "bob", "password": "correct horse"}`,
+			status: http.StatusConflict,
+		},
+		{
+			name:   "should return Bad Request when body is not json",
+			body:   `alice`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/users", strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+			httpUserHandler.Register(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+			assert.NotContains(t, rr.Body.String(), "secret hash")
+		})
+	}
+}
+
+func TestHTTPUserHandler_Authenticate(t *testing.T) {
+	alice := domain.Identity{UserID: "alice-id", Username: "alice"}
+	mockService, httpUserHandler := setUp()
+	mockService.On("Authenticate", "todo_valid").Return(alice, nil)
+	mockService.On("Authenticate", "todo_revoked").Return(domain.Identity{}, domain.Unauthenticated("invalid API token"))
+	mockService.On("Login", "alice", "correct horse").Return(alice, nil)
+	mockService.On("Login", "alice", "wrong horse").Return(domain.Identity{}, domain.Unauthenticated("invalid username or password"))
+
+	handler := httpUserHandler.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := domain.IdentityFrom(r.Context())
+		assert.True(t, ok)
+		assert.EqualValues(t, alice, identity)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		setUp  func(req *http.Request)
+		status int
+	}{
+		{
+			name:   "should pass on requests with a valid bearer token",
+			setUp:  func(req *http.Request) { req.Header.Set("Authorization", "Bearer todo_valid") },
+			status: http.StatusOK,
+		},
+		{
+			name:   "should pass on requests with a valid username and password",
+			setUp:  func(req *http.Request) { req.SetBasicAuth("alice", "correct horse") },
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Unauthorized when bearer token is revoked",
+			setUp:  func(req *http.Request) { req.Header.Set("Authorization", "Bearer todo_revoked") },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when password is wrong",
+			setUp:  func(req *http.Request) { req.SetBasicAuth("alice", "wrong horse") },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "should return Unauthorized when no credentials are given",
+			setUp:  func(req *http.Request) {},
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/entry", nil)
+			test.setUp(req)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+			if test.status == http.StatusUnauthorized {
+				assert.Len(t, rr.Header().Values("WWW-Authenticate"), 2)
+				assert.Contains(t, rr.Body.String(), `"code":"unauthenticated"`)
+			}
+		})
+	}
+}
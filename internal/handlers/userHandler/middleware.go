@@ -0,0 +1,44 @@
+package userHandler
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/handlers/httpCommon"
+	"net/http"
+	"strings"
+)
+
+// Authenticate is a middleware rejecting requests that are not made on behalf of a user. Clients
+// authenticate with an API token sent as a bearer token, or with their username and password
+// using basic authentication. The identity of the user is added to the request context.
+// Requests already authenticated by an earlier middleware are passed on untouched.
+func (h *HTTPUserHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := domain.IdentityFrom(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, err := h.identify(r)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.Header().Add("WWW-Authenticate", `Bearer realm="todo"`)
+			w.Header().Add("WWW-Authenticate", `Basic realm="todo", charset="UTF-8"`)
+			httpCommon.SendErrorResponse(w, "failed to authenticate request", err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.WithIdentity(r.Context(), identity)))
+	})
+}
+
+// identify returns the identity of the user whose credentials are carried by the request.
+func (h *HTTPUserHandler) identify(r *http.Request) (domain.Identity, error) {
+	header := r.Header.Get("Authorization")
+	if scheme, token, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return h.UserService.Authenticate(strings.TrimSpace(token))
+	}
+	if username, password, ok := r.BasicAuth(); ok {
+		return h.UserService.Login(username, password)
+	}
+	return domain.Identity{}, domain.Unauthenticated("authentication required")
+}
@@ -0,0 +1,133 @@
+package viewHandler
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/handlers/httpCommon"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+type HTTPViewHandler struct {
+	ViewService ports.ViewService
+}
+
+// NewHTTPViewHandler returns a pointer to the HTTP adapter for the ports.ViewService interface.
+func NewHTTPViewHandler(viewService ports.ViewService) *HTTPViewHandler {
+	return &HTTPViewHandler{
+		ViewService: viewService,
+	}
+}
+
+// Get handles retrieval of a view through HTTP with a specified name within the URL.
+func (h *HTTPViewHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	view, err := h.ViewService.Get(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to retrieve view with given name", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(view); err != nil {
+		panic(err)
+	}
+}
+
+// Create handles saving a new view through HTTP with given name and query within body.
+func (h *HTTPViewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	var details domain.ViewInput
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	view, err := h.ViewService.Create(r.Context(), details)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to create view", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(view); err != nil {
+		panic(err)
+	}
+}
+
+// Update handles renaming the view with the name specified in the URL and replacing its query
+// with the values given in the body. Omitting the name in the body keeps the name of the view.
+func (h *HTTPViewHandler) Update(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	var details domain.ViewInput
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to decode json body", httpCommon.BodyError(err))
+		return
+	}
+
+	view, err := h.ViewService.Update(r.Context(), mux.Vars(r)["name"], details)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to update view", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(view); err != nil {
+		panic(err)
+	}
+}
+
+// Delete removes a view with a given name through HTTP.
+func (h *HTTPViewHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	if err := h.ViewService.Delete(r.Context(), mux.Vars(r)["name"]); err != nil {
+		httpCommon.SendErrorResponse(w, "failed to delete view with given name", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// List handles retrieval of every view, ordered by name.
+func (h *HTTPViewHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	views, err := h.ViewService.List(r.Context())
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to list views", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(views); err != nil {
+		panic(err)
+	}
+}
+
+// Entries handles retrieval of a page of the entries matching the view with the name specified in
+// the URL, accepting the same query parameters as the entry listing to narrow and order them.
+func (h *HTTPViewHandler) Entries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	query, err := httpCommon.ParseListQuery(r)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to parse query parameters", err)
+		return
+	}
+
+	page, err := h.ViewService.Entries(r.Context(), mux.Vars(r)["name"], query)
+	if err != nil {
+		httpCommon.SendErrorResponse(w, "failed to list entries of view", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		panic(err)
+	}
+}
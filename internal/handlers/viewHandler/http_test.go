@@ -0,0 +1,124 @@
+package viewHandler
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	mocks "github.com/Nikym/go-todo/mocks/core/ports"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setUp() (*mocks.ViewService, *HTTPViewHandler) {
+	mockService := &mocks.ViewService{}
+	httpViewHandler := NewHTTPViewHandler(mockService)
+	return mockService, httpViewHandler
+}
+
+func TestHTTPViewHandler_Create(t *testing.T) {
+	mockService, httpViewHandler := setUp()
+	mockService.
+		On("Create", mock.Anything, domain.ViewInput{Name: "urgent", Query: "priority:high"}).
+		Return(&domain.View{ID: "id", Name: "urgent", Query: "priority:high"}, nil)
+	mockService.
+		On("Create", mock.Anything, domain.ViewInput{Name: "broken", Query: "due<soon"}).
+		Return(&domain.View{}, domain.NewValidationError("query", "due must be a date"))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name:   "should return OK when view is created",
+			body:   `{"name": "urgent", "query": "priority:high"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Bad Request when query is malformed",
+			body:   `{"name": "broken", "query": "due<soon"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should return Bad Request when body is not json",
+			body:   `name`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/views", strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+			httpViewHandler.Create(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
+
+func TestHTTPViewHandler_Update(t *testing.T) {
+	mockService, httpViewHandler := setUp()
+	mockService.
+		On("Update", mock.Anything, "urgent", domain.ViewInput{Query: "priority>=medium"}).
+		Return(&domain.View{ID: "id", Name: "urgent", Query: "priority>=medium"}, nil)
+
+	req := mux.SetURLVars(httptest.NewRequest("PATCH", "/api/views/urgent", strings.NewReader(`{"query": "priority>=medium"}`)), map[string]string{"name": "urgent"})
+	rr := httptest.NewRecorder()
+	httpViewHandler.Update(rr, req)
+
+	assert.EqualValues(t, http.StatusOK, rr.Code)
+	var view domain.View
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &view))
+	assert.EqualValues(t, "priority>=medium", view.Query)
+}
+
+func TestHTTPViewHandler_Entries(t *testing.T) {
+	mockService, httpViewHandler := setUp()
+	mockService.
+		On("Entries", mock.Anything, "urgent", domain.ListQuery{Limit: 10}).
+		Return(&domain.EntryPage{Entries: []*domain.Entry{{ID: "id", Title: "Fix outage"}}}, nil)
+	mockService.
+		On("Entries", mock.Anything, "missing", domain.ListQuery{}).
+		Return(&domain.EntryPage{}, domain.NotFound("view not found in repository"))
+
+	tests := []struct {
+		name   string
+		target string
+		view   string
+		status int
+	}{
+		{
+			name:   "should return the entries of the view",
+			target: "/api/views/urgent/entries?limit=10",
+			view:   "urgent",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should return Not Found when view is missing",
+			target: "/api/views/missing/entries",
+			view:   "missing",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "should return Bad Request when query parameters are malformed",
+			target: "/api/views/urgent/entries?limit=ten",
+			view:   "urgent",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := mux.SetURLVars(httptest.NewRequest("GET", test.target, nil), map[string]string{"name": test.view})
+			rr := httptest.NewRecorder()
+			httpViewHandler.Entries(rr, req)
+
+			assert.EqualValues(t, test.status, rr.Code)
+		})
+	}
+}
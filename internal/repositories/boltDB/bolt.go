@@ -0,0 +1,139 @@
+package boltDB
+
+import (
+	"fmt"
+	"go.etcd.io/bbolt"
+	"io"
+	"os"
+	"time"
+)
+
+// EntriesBucket is the bucket holding the JSON encoding of every entry, keyed by entry ID.
+var EntriesBucket = []byte("entries")
+
+// ListsBucket is the bucket holding the JSON encoding of every list, keyed by list ID.
+var ListsBucket = []byte("lists")
+
+// UsersBucket is the bucket holding the JSON encoding of every user, keyed by user ID.
+var UsersBucket = []byte("users")
+
+// UsernamesBucket is the bucket indexing users, mapping each lower case username to a user ID.
+var UsernamesBucket = []byte("usernames")
+
+// TokensBucket is the bucket holding the JSON encoding of every API token, keyed by the hash of
+// its secret.
+var TokensBucket = []byte("tokens")
+
+// GrantsBucket is the bucket holding the JSON encoding of every grant, keyed by resource type,
+// resource ID and user ID separated by slashes.
+var GrantsBucket = []byte("grants")
+
+// ViewsBucket is the bucket holding the JSON encoding of every view, keyed by view ID.
+var ViewsBucket = []byte("views")
+
+// TrashBucket is the bucket holding the JSON encoding of every entry in the trash, keyed by
+// entry ID.
+var TrashBucket = []byte("trash")
+
+// RevisionsBucket is the bucket holding the history of entries: a nested bucket per entry ID
+// holding the JSON encoding of each revision, keyed by its big-endian revision number.
+var RevisionsBucket = []byte("revisions")
+
+// buckets lists every bucket created when a database is opened.
+var buckets = [][]byte{EntriesBucket, ListsBucket, UsersBucket, UsernamesBucket, TokensBucket, GrantsBucket, ViewsBucket, TrashBucket, RevisionsBucket}
+
+// Open returns a handle to the bbolt database at the given path, creating the file and its
+// buckets if needed. It fails after the timeout if another process holds the database open.
+func Open(path string, timeout time.Duration) (*bbolt.DB, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating buckets: %w", err)
+	}
+
+	return db, nil
+}
+
+// OpenReadOnly returns a read-only handle to the existing bbolt database at the given path.
+// Several read-only handles may be open at once, but not alongside a writable one.
+func OpenReadOnly(path string, timeout time.Duration) (*bbolt.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	db, err := bbolt.Open(path, 0400, &bbolt.Options{ReadOnly: true, Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Backup writes a consistent copy of the whole database to w. Writers are not blocked while
+// the copy is taken.
+func Backup(db *bbolt.DB, w io.Writer) (int64, error) {
+	var written int64
+	err := db.View(func(tx *bbolt.Tx) error {
+		var err error
+		written, err = tx.WriteTo(w)
+		return err
+	})
+	return written, err
+}
+
+// BackupFile writes a consistent copy of the whole database to a new file at path.
+func BackupFile(db *bbolt.DB, path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := Backup(db, f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Compact rewrites the database at src into a new file at dst, leaving out the free pages that
+// bbolt never returns to the file system. The source database must not be open elsewhere.
+func Compact(src, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+
+	from, err := OpenReadOnly(src, time.Second)
+	if err != nil {
+		return err
+	}
+	defer from.Close()
+
+	to, err := bbolt.Open(dst, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", dst, err)
+	}
+
+	if err := bbolt.Compact(to, from, 64<<20); err != nil {
+		to.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	return to.Close()
+}
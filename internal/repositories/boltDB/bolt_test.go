@@ -0,0 +1,88 @@
+package boltDB
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setUp(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "todo.bolt")
+	db, err := Open(path, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(EntriesBucket)
+		for _, id := range []string{"a", "b", "c"} {
+			if err := bucket.Put([]byte(id), bytes.Repeat([]byte(id), 4096)); err != nil {
+				return err
+			}
+		}
+		return bucket.Delete([]byte("b"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func assertEntries(t *testing.T, path string, expected ...string) {
+	db, err := OpenReadOnly(path, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var actual []string
+	assert.NoError(t, db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(EntriesBucket).ForEach(func(k, _ []byte) error {
+			actual = append(actual, string(k))
+			return nil
+		})
+	}))
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestBackupFile(t *testing.T) {
+	src := setUp(t)
+	dst := filepath.Join(t.TempDir(), "backup.bolt")
+
+	db, err := Open(src, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	t.Run("should copy every entry to the destination", func(t *testing.T) {
+		assert.NoError(t, BackupFile(db, dst))
+		assertEntries(t, dst, "a", "c")
+	})
+
+	t.Run("should refuse to overwrite an existing file", func(t *testing.T) {
+		assert.Error(t, BackupFile(db, dst))
+	})
+}
+
+func TestCompact(t *testing.T) {
+	src := setUp(t)
+	dst := filepath.Join(t.TempDir(), "compacted.bolt")
+
+	t.Run("should copy every entry to the destination", func(t *testing.T) {
+		assert.NoError(t, Compact(src, dst))
+		assertEntries(t, dst, "a", "c")
+	})
+
+	t.Run("should refuse to overwrite an existing file", func(t *testing.T) {
+		assert.Error(t, Compact(src, dst))
+	})
+
+	t.Run("should return error when the source does not exist", func(t *testing.T) {
+		assert.Error(t, Compact(filepath.Join(t.TempDir(), "missing.bolt"), filepath.Join(t.TempDir(), "out.bolt")))
+	})
+}
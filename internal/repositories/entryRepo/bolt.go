@@ -0,0 +1,192 @@
+package entryRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"go.etcd.io/bbolt"
+)
+
+// boltKVS keeps the layout of memKVS, one JSON encoded entry per ID, in a bbolt bucket.
+type boltKVS struct {
+	db *bbolt.DB
+}
+
+// boltTx is the view of the repository within a single bbolt transaction.
+type boltTx struct {
+	tx *bbolt.Tx
+}
+
+// NewBolt returns a pointer to an entry repository stored in the given bbolt database, which
+// is expected to have been opened by boltDB.Open.
+func NewBolt(db *bbolt.DB) *boltKVS {
+	return &boltKVS{
+		db: db,
+	}
+}
+
+// Atomic runs fn against a view of the repository in which every write either succeeds
+// together with the others or, if fn returns an error, is discarded.
+func (r *boltKVS) Atomic(fn func(repo ports.EntryRepository) error) error {
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+	return domain.Categorise("transaction failed", err)
+}
+
+// Get retrieves an entry with a specified ID from the bbolt repository.
+func (r *boltKVS) Get(id string) (*domain.Entry, error) {
+	var entry *domain.Entry
+	err := r.db.View(func(tx *bbolt.Tx) (err error) {
+		entry, err = (&boltTx{tx: tx}).Get(id)
+		return err
+	})
+	if err != nil {
+		return &domain.Entry{}, domain.Categorise("reading entry failed", err)
+	}
+	return entry, nil
+}
+
+// Save stores a given domain.Entry object in the bbolt repository. Saving an entry whose ID is
+// already stored is a conflict.
+func (r *boltKVS) Save(entry *domain.Entry) error {
+	return r.Atomic(func(repo ports.EntryRepository) error {
+		return repo.Save(entry)
+	})
+}
+
+// Delete removes a domain.Entry object with a given ID from the bbolt repository.
+func (r *boltKVS) Delete(id string) error {
+	return r.Atomic(func(repo ports.EntryRepository) error {
+		return repo.Delete(id)
+	})
+}
+
+// Update sets the entry stored in the bbolt repository with given ID to the domain.Entry specified,
+// provided the stored entry is still at the version of the given one.
+func (r *boltKVS) Update(id string, entry *domain.Entry) error {
+	return r.Atomic(func(repo ports.EntryRepository) error {
+		return repo.Update(id, entry)
+	})
+}
+
+// List returns the page of entries stored in the bbolt repository that match the query.
+func (r *boltKVS) List(query domain.ListQuery) (*domain.EntryPage, error) {
+	var page *domain.EntryPage
+	err := r.db.View(func(tx *bbolt.Tx) (err error) {
+		page, err = (&boltTx{tx: tx}).List(query)
+		return err
+	})
+	if err != nil {
+		return &domain.EntryPage{}, domain.Categorise("listing entries failed", err)
+	}
+	return page, nil
+}
+
+// Tags counts the tags of the entries stored in the bbolt repository that match the query.
+func (r *boltKVS) Tags(query domain.ListQuery) ([]domain.TagCount, error) {
+	var tags []domain.TagCount
+	err := r.db.View(func(tx *bbolt.Tx) (err error) {
+		tags, err = (&boltTx{tx: tx}).Tags(query)
+		return err
+	})
+	if err != nil {
+		return nil, domain.Categorise("counting tags failed", err)
+	}
+	return tags, nil
+}
+
+func (t *boltTx) Get(id string) (*domain.Entry, error) {
+	val := t.tx.Bucket(boltDB.EntriesBucket).Get([]byte(id))
+	if val == nil {
+		return &domain.Entry{}, domain.NotFound("entry not found in repository")
+	}
+
+	entry := domain.Entry{}
+	if err := json.Unmarshal(val, &entry); err != nil {
+		return &domain.Entry{}, domain.Internal("decoding stored entry failed", err)
+	}
+	return &entry, nil
+}
+
+func (t *boltTx) Save(entry *domain.Entry) error {
+	if entry.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	bucket := t.tx.Bucket(boltDB.EntriesBucket)
+	if bucket.Get([]byte(entry.ID)) != nil {
+		return domain.Conflict("entry with given id already exists in repository")
+	}
+	return t.put(bucket, entry.ID, entry)
+}
+
+func (t *boltTx) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	return t.tx.Bucket(boltDB.EntriesBucket).Delete([]byte(id))
+}
+
+func (t *boltTx) Update(id string, entry *domain.Entry) error {
+	bucket := t.tx.Bucket(boltDB.EntriesBucket)
+	val := bucket.Get([]byte(id))
+	if val == nil {
+		return domain.NotFound("no entry with given id found in repository")
+	}
+	if err := checkVersion(val, entry.Version); err != nil {
+		return err
+	}
+
+	updated := *entry
+	updated.ID = id
+	updated.Version++
+	if err := t.put(bucket, id, &updated); err != nil {
+		return err
+	}
+	entry.Version = updated.Version
+	return nil
+}
+
+func (t *boltTx) List(query domain.ListQuery) (*domain.EntryPage, error) {
+	matched, err := t.match(query)
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+
+	return domain.Paginate(matched, query)
+}
+
+func (t *boltTx) Tags(query domain.ListQuery) ([]domain.TagCount, error) {
+	matched, err := t.match(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.CountTags(matched), nil
+}
+
+func (t *boltTx) match(query domain.ListQuery) ([]*domain.Entry, error) {
+	var matched []*domain.Entry
+	err := t.tx.Bucket(boltDB.EntriesBucket).ForEach(func(_, val []byte) error {
+		entry := domain.Entry{}
+		if err := json.Unmarshal(val, &entry); err != nil {
+			return domain.Internal("decoding stored entry failed", err)
+		}
+		if query.Matches(&entry) {
+			matched = append(matched, &entry)
+		}
+		return nil
+	})
+	return matched, err
+}
+
+func (t *boltTx) put(bucket *bbolt.Bucket, id string, entry *domain.Entry) error {
+	bytes, err := json.Marshal(*entry)
+	if err != nil {
+		return domain.Internal("encoding entry failed", err)
+	}
+	return bucket.Put([]byte(id), bytes)
+}
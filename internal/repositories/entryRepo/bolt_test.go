@@ -0,0 +1,22 @@
+package entryRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo/repotest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBolt(t *testing.T) {
+	repotest.RunAtomic(t, func(t *testing.T) ports.AtomicEntryRepository {
+		db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		return NewBolt(db)
+	})
+}
@@ -0,0 +1,27 @@
+package entryRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"strconv"
+)
+
+// checkVersion returns a precondition failure unless the stored entry encoded in val is at the
+// given version.
+func checkVersion(val []byte, version int64) error {
+	var stored struct {
+		Version int64 `json:"version"`
+	}
+	if err := json.Unmarshal(val, &stored); err != nil {
+		return domain.Internal("decoding stored entry failed", err)
+	}
+	return compareVersion(stored.Version, version)
+}
+
+// compareVersion returns a precondition failure unless the stored version equals the given one.
+func compareVersion(stored, version int64) error {
+	if stored != version {
+		return domain.PreconditionFailed("entry is at version " + strconv.FormatInt(stored, 10) + ", not " + strconv.FormatInt(version, 10))
+	}
+	return nil
+}
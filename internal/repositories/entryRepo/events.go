@@ -0,0 +1,562 @@
+package entryRepo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultSnapshotInterval is the number of events appended to an event log between two
+// snapshots, unless configured otherwise.
+const DefaultSnapshotInterval = 1000
+
+// eventLog is an event-sourced entry repository. Rather than the entries themselves, it stores
+// every change made to them as domain events (domain.EntryEvent), appended to a log file that is
+// never rewritten, and projects the current state of the entries from them into a memKVS serving
+// all reads.
+//
+// The events written by a single call or transaction are checked against the projection, then
+// appended as one record, framed by its length and checksum and synced to disk, and only then
+// applied to the projection, all at once so that reads never see part of them. A record torn by
+// a crash is detected and dropped when the log is opened again. Every snapshotInterval events, the
+// projection is written to a snapshot file next to the log, naming the offset of the log it
+// covers; opening the log only replays the records following that offset.
+type eventLog struct {
+	mu               sync.Mutex
+	path             string
+	file             *os.File
+	size             int64
+	projection       *memKVS
+	snapshotInterval int
+	sinceSnapshot    int
+}
+
+// eventTx is the view of the repository within a single transaction. Writes are staged, as the
+// entries they leave behind (nil for removed ones) and the events recording them, until the
+// transaction commits.
+type eventTx struct {
+	log    *eventLog
+	staged map[string]*domain.Entry
+	events []domain.EntryEvent
+}
+
+// snapshot is the projection of an event log up to Offset, as stored in its snapshot file.
+type snapshot struct {
+	Offset  int64           `json:"offset"`
+	Entries []*domain.Entry `json:"entries"`
+}
+
+// EventLogOption configures optional behaviour of an event log.
+type EventLogOption func(r *eventLog)
+
+// WithSnapshotInterval makes the event log take a snapshot every given number of events.
+// Intervals below 1 disable snapshots, making every start replay the whole log.
+func WithSnapshotInterval(events int) EventLogOption {
+	return func(r *eventLog) {
+		r.snapshotInterval = events
+	}
+}
+
+// OpenEventLog returns a pointer to an event-sourced entry repository keeping its events in the
+// file at path, created if missing, and its snapshots at path with a ".snapshot" suffix. The
+// entries are projected from the latest snapshot and the events appended since; a torn record
+// ending the log is cut off. The repository must be closed once it is no longer used.
+func OpenEventLog(path string, opts ...EventLogOption) (*eventLog, error) {
+	r := &eventLog{
+		path:             path,
+		projection:       NewMemKVS(),
+		snapshotInterval: DefaultSnapshotInterval,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening event log: %w", err)
+	}
+	// The log may just have been created, which only lasts once its directory is synced.
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("opening event log: %w", err)
+	}
+	r.file = file
+
+	if err := r.recover(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close closes the log file of the repository.
+func (r *eventLog) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+// Atomic runs fn against a view of the repository in which every write either succeeds
+// together with the others or, if fn returns an error, is discarded. Transactions run one at a
+// time, while reads outside of them go on.
+func (r *eventLog) Atomic(fn func(repo ports.EntryRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &eventTx{log: r, staged: map[string]*domain.Entry{}}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return r.commit(tx.events)
+}
+
+// Get retrieves an entry with a specified ID from the projection of the event log.
+func (r *eventLog) Get(id string) (*domain.Entry, error) {
+	return r.projection.Get(id)
+}
+
+// Save records the creation of a given domain.Entry object in the event log. Saving an entry
+// whose ID is already stored is a conflict.
+func (r *eventLog) Save(entry *domain.Entry) error {
+	return r.Atomic(func(repo ports.EntryRepository) error {
+		return repo.Save(entry)
+	})
+}
+
+// Delete records the removal of the domain.Entry object with a given ID in the event log.
+func (r *eventLog) Delete(id string) error {
+	return r.Atomic(func(repo ports.EntryRepository) error {
+		return repo.Delete(id)
+	})
+}
+
+// Update records the changes made by the domain.Entry specified to the entry with given ID in the
+// event log, provided the stored entry is still at the version of the given one.
+func (r *eventLog) Update(id string, entry *domain.Entry) error {
+	return r.Atomic(func(repo ports.EntryRepository) error {
+		return repo.Update(id, entry)
+	})
+}
+
+// List returns the page of entries projected from the event log that match the query.
+func (r *eventLog) List(query domain.ListQuery) (*domain.EntryPage, error) {
+	return r.projection.List(query)
+}
+
+// Tags counts the tags of the entries projected from the event log that match the query.
+func (r *eventLog) Tags(query domain.ListQuery) ([]domain.TagCount, error) {
+	return r.projection.Tags(query)
+}
+
+// Search returns the entries projected from the event log whose title or description contains
+// every word of text, most relevant first.
+func (r *eventLog) Search(text string) ([]domain.SearchHit, error) {
+	return r.projection.Search(text)
+}
+
+// Events returns every event recorded for the entry with the given ID, oldest first.
+func (r *eventLog) Events(id string) ([]domain.EntryEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := []domain.EntryEvent{}
+	if _, err := scanRecords(r.file, 0, r.size, func(events []domain.EntryEvent) error {
+		for _, event := range events {
+			if event.EntryID == id {
+				found = append(found, event)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, domain.Internal("reading event log failed", err)
+	}
+	return found, nil
+}
+
+// recover rebuilds the projection from the latest snapshot, when there is a usable one, and the
+// records following it, and cuts off a torn record ending the log.
+func (r *eventLog) recover() error {
+	info, err := r.file.Stat()
+	if err != nil {
+		return fmt.Errorf("reading event log: %w", err)
+	}
+	size := info.Size()
+
+	offset := r.loadSnapshot(size)
+	end, err := scanRecords(r.file, offset, size, func(events []domain.EntryEvent) error {
+		changed, err := r.project(events)
+		if err != nil {
+			return err
+		}
+		if err := r.projection.putAll(changed); err != nil {
+			return err
+		}
+		r.sinceSnapshot += len(events)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("replaying event log: %w", err)
+	}
+
+	if err := cutTornTail(r.file, end, size); err != nil {
+		return fmt.Errorf("recovering event log: %w", err)
+	}
+	r.size = end
+	return nil
+}
+
+// loadSnapshot projects the entries of the snapshot of the log, and returns the offset of the
+// log it covers. Missing and unusable snapshots, such as ones covering more than the size of the
+// log, are ignored, leaving the whole log to be replayed.
+func (r *eventLog) loadSnapshot(size int64) int64 {
+	data, err := os.ReadFile(r.snapshotPath())
+	if err != nil {
+		return 0
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil || snap.Offset > size {
+		return 0
+	}
+
+	projection := NewMemKVS()
+	for _, entry := range snap.Entries {
+		if err := projection.put(entry); err != nil {
+			return 0
+		}
+	}
+	r.projection = projection
+	return snap.Offset
+}
+
+// commit appends events to the log as a single record, synced to disk, and applies them to the
+// projection, taking a snapshot when enough events were appended since the last one. Events that
+// cannot be applied are not appended, as they would fail every replay of the log. It must be
+// called with the lock held.
+func (r *eventLog) commit(events []domain.EntryEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(events)
+	if err != nil {
+		return domain.Internal("encoding events failed", err)
+	}
+	changed, err := r.project(events)
+	if err != nil {
+		return err
+	}
+	written, err := writeFrame(r.file, r.size, payload, true)
+	if err != nil {
+		return domain.Internal("appending events to log failed", err)
+	}
+	r.size += written
+
+	if err := r.projection.putAll(changed); err != nil {
+		return err
+	}
+
+	r.sinceSnapshot += len(events)
+	if r.snapshotInterval > 0 && r.sinceSnapshot >= r.snapshotInterval {
+		// The log holds every event either way, so a failed snapshot is retried after the next
+		// commit.
+		if err := r.takeSnapshot(); err == nil {
+			r.sinceSnapshot = 0
+		}
+	}
+	return nil
+}
+
+// takeSnapshot writes the projection to the snapshot file, replacing the previous snapshot only
+// once the new one is complete. It must be called with the lock held.
+func (r *eventLog) takeSnapshot() error {
+	entries, err := r.projection.match(domain.ListQuery{})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot{Offset: r.size, Entries: entries})
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(r.snapshotPath(), data)
+}
+
+// snapshotPath returns the path of the snapshot file of the log.
+func (r *eventLog) snapshotPath() string {
+	return r.path + ".snapshot"
+}
+
+// project returns the entries that events leave behind, by ID, nil for removed ones, without
+// changing the projection. It fails when an event cannot be applied to the entry it records.
+func (r *eventLog) project(events []domain.EntryEvent) (map[string]*domain.Entry, error) {
+	changed := map[string]*domain.Entry{}
+	for _, event := range events {
+		if event.Type == domain.EntryDeleted {
+			changed[event.EntryID] = nil
+			continue
+		}
+
+		var entry *domain.Entry
+		switch event.Type {
+		case domain.EntryCreated, domain.EntryChanged:
+			if event.Entry == nil {
+				return nil, domain.Internal("applying event failed", fmt.Errorf("%s event of entry %s carries no entry", event.Type, event.EntryID))
+			}
+			entry = event.Entry
+		case domain.EntryRenamed, domain.EntryCompleted, domain.EntryReopened:
+			current, ok := changed[event.EntryID]
+			if !ok {
+				projected, err := r.projection.Get(event.EntryID)
+				if err != nil {
+					return nil, domain.Internal("applying event failed", fmt.Errorf("%s event of entry %s: %w", event.Type, event.EntryID, err))
+				}
+				current = projected
+			}
+			if current == nil {
+				return nil, domain.Internal("applying event failed", fmt.Errorf("%s event of removed entry %s", event.Type, event.EntryID))
+			}
+			entry = current
+			switch event.Type {
+			case domain.EntryRenamed:
+				entry.Title = event.Title
+			case domain.EntryCompleted:
+				entry.Done, entry.CompletedAt = true, event.CompletedAt
+			default:
+				entry.Done, entry.CompletedAt = false, nil
+			}
+		default:
+			return nil, domain.Internal("applying event failed", fmt.Errorf("unknown event type %q", event.Type))
+		}
+
+		entry.ID = event.EntryID
+		entry.Version = event.Version
+		entry.UpdatedAt = event.At
+		changed[event.EntryID] = entry
+	}
+	return changed, nil
+}
+
+// Get retrieves an entry with a specified ID, as left behind by the transaction.
+func (tx *eventTx) Get(id string) (*domain.Entry, error) {
+	if entry, ok := tx.staged[id]; ok {
+		if entry == nil {
+			return &domain.Entry{}, domain.NotFound("entry not found in repository")
+		}
+		return cloneEntry(entry)
+	}
+	return tx.log.projection.Get(id)
+}
+
+// Save records the creation of a given domain.Entry object within the transaction. Saving an
+// entry whose ID is already stored is a conflict.
+func (tx *eventTx) Save(entry *domain.Entry) error {
+	if entry.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+	if _, err := tx.Get(entry.ID); err == nil {
+		return domain.Conflict("entry with given id already exists in repository")
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	return tx.stage(entry.ID, nil, entry)
+}
+
+// Delete records the removal of the domain.Entry object with a given ID within the transaction.
+func (tx *eventTx) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+	current, err := tx.Get(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return tx.stage(id, current, nil)
+}
+
+// Update records the changes made by the domain.Entry specified to the entry with given ID within
+// the transaction, provided the stored entry is still at the version of the given one.
+func (tx *eventTx) Update(id string, entry *domain.Entry) error {
+	current, err := tx.Get(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.NotFound("no entry with given id found in repository")
+		}
+		return err
+	}
+	if err := compareVersion(current.Version, entry.Version); err != nil {
+		return err
+	}
+
+	updated := *entry
+	updated.ID = id
+	updated.Version++
+	if err := tx.stage(id, current, &updated); err != nil {
+		return err
+	}
+	entry.Version = updated.Version
+	return nil
+}
+
+// List returns the page of entries that match the query, as left behind by the transaction.
+func (tx *eventTx) List(query domain.ListQuery) (*domain.EntryPage, error) {
+	matched, err := tx.match(query)
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+
+	return domain.Paginate(matched, query)
+}
+
+// Tags counts the tags of the entries that match the query, as left behind by the transaction.
+func (tx *eventTx) Tags(query domain.ListQuery) ([]domain.TagCount, error) {
+	matched, err := tx.match(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.CountTags(matched), nil
+}
+
+// match returns every entry matching the query, taking the entries staged by the transaction
+// over their projected state.
+func (tx *eventTx) match(query domain.ListQuery) ([]*domain.Entry, error) {
+	projected, err := tx.log.projection.match(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*domain.Entry
+	for _, entry := range projected {
+		if _, ok := tx.staged[entry.ID]; !ok {
+			matched = append(matched, entry)
+		}
+	}
+	for _, entry := range tx.staged {
+		if entry != nil && query.Matches(entry) {
+			cloned, err := cloneEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+			matched = append(matched, cloned)
+		}
+	}
+	return matched, nil
+}
+
+// stage records the change of the entry with the given ID from before to after, either of which
+// is nil when the entry is created or removed.
+func (tx *eventTx) stage(id string, before, after *domain.Entry) error {
+	if after != nil {
+		cloned, err := cloneEntry(after)
+		if err != nil {
+			return err
+		}
+		after = cloned
+	}
+
+	events, err := entryEvents(id, before, after)
+	if err != nil {
+		return err
+	}
+	tx.staged[id] = after
+	tx.events = append(tx.events, events...)
+	return nil
+}
+
+// entryEvents returns the events recording the change of the entry with the given ID from before
+// to after. Renames, completions and reopenings are recorded as such, and any other change, or an
+// update changing nothing, as a change carrying the whole entry.
+func entryEvents(id string, before, after *domain.Entry) ([]domain.EntryEvent, error) {
+	switch {
+	case before == nil:
+		return []domain.EntryEvent{{Type: domain.EntryCreated, EntryID: id, Version: after.Version, At: after.UpdatedAt, Entry: after}}, nil
+	case after == nil:
+		return []domain.EntryEvent{{Type: domain.EntryDeleted, EntryID: id, Version: before.Version}}, nil
+	}
+
+	var events []domain.EntryEvent
+	if before.Title != after.Title {
+		events = append(events, domain.EntryEvent{Type: domain.EntryRenamed, Title: after.Title})
+	}
+	if after.Done != before.Done {
+		if after.Done {
+			events = append(events, domain.EntryEvent{Type: domain.EntryCompleted, CompletedAt: after.CompletedAt})
+		} else {
+			events = append(events, domain.EntryEvent{Type: domain.EntryReopened})
+		}
+	}
+
+	changed, err := otherFieldsChanged(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if changed || len(events) == 0 {
+		events = append(events, domain.EntryEvent{Type: domain.EntryChanged, Entry: after})
+	}
+
+	for i := range events {
+		events[i].EntryID = id
+		events[i].Version = after.Version
+		events[i].At = after.UpdatedAt
+	}
+	return events, nil
+}
+
+// otherFieldsChanged reports whether the entry changed from before to after in any field but
+// those recorded by dedicated events and those every change sets.
+func otherFieldsChanged(before, after *domain.Entry) (bool, error) {
+	encode := func(entry domain.Entry) ([]byte, error) {
+		entry.Title, entry.Version, entry.UpdatedAt = "", 0, after.UpdatedAt
+		if before.Done != after.Done {
+			entry.Done, entry.CompletedAt = false, nil
+		}
+		return json.Marshal(entry)
+	}
+
+	b, err := encode(*before)
+	if err != nil {
+		return false, domain.Internal("encoding entry failed", err)
+	}
+	a, err := encode(*after)
+	if err != nil {
+		return false, domain.Internal("encoding entry failed", err)
+	}
+	return !bytes.Equal(b, a), nil
+}
+
+// scanRecords reads the records of the log file between offset and size, as described by
+// scanFrames, passing the events of each to fn.
+func scanRecords(file *os.File, offset, size int64, fn func(events []domain.EntryEvent) error) (int64, error) {
+	return scanFrames(file, offset, size, func(payload []byte) error {
+		var events []domain.EntryEvent
+		if err := json.Unmarshal(payload, &events); err != nil {
+			return fmt.Errorf("decoding record: %w", err)
+		}
+		return fn(events)
+	})
+}
+
+// cloneEntry returns a copy of entry sharing no memory with it.
+func cloneEntry(entry *domain.Entry) (*domain.Entry, error) {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return nil, domain.Internal("encoding entry failed", err)
+	}
+	cloned := &domain.Entry{}
+	if err := json.Unmarshal(encoded, cloned); err != nil {
+		return nil, domain.Internal("decoding stored entry failed", err)
+	}
+	return cloned, nil
+}
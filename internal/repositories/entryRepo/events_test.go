@@ -0,0 +1,230 @@
+package entryRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestEventLog(t *testing.T) {
+	repotest.RunAtomic(t, func(t *testing.T) ports.AtomicEntryRepository {
+		repo, err := OpenEventLog(filepath.Join(t.TempDir(), "todo.events"), WithSnapshotInterval(10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
+
+func TestEventLog_Events(t *testing.T) {
+	repo, err := OpenEventLog(filepath.Join(t.TempDir(), "todo.events"))
+	require.NoError(t, err)
+	defer repo.Close()
+	done := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	entry := &domain.Entry{ID: "a", Title: "Buy milk"}
+	require.NoError(t, repo.Save(entry))
+	entry.Title = "Buy oat milk"
+	require.NoError(t, repo.Update("a", entry))
+	entry.Done, entry.CompletedAt = true, &done
+	entry.Tags = []string{"@shop"}
+	require.NoError(t, repo.Update("a", entry))
+	entry.Done, entry.CompletedAt = false, nil
+	require.NoError(t, repo.Update("a", entry))
+	require.NoError(t, repo.Update("a", entry))
+	require.NoError(t, repo.Delete("a"))
+	require.NoError(t, repo.Save(&domain.Entry{ID: "b", Title: "Buy bread"}))
+
+	events, err := repo.Events("a")
+	require.NoError(t, err)
+	types := make([]domain.EntryEventType, len(events))
+	versions := make([]int64, len(events))
+	for i, event := range events {
+		types[i], versions[i] = event.Type, event.Version
+	}
+	assert.EqualValues(t, []domain.EntryEventType{
+		domain.EntryCreated,
+		domain.EntryRenamed,
+		domain.EntryCompleted,
+		domain.EntryChanged,
+		domain.EntryReopened,
+		domain.EntryChanged,
+		domain.EntryDeleted,
+	}, types)
+	assert.EqualValues(t, []int64{0, 1, 2, 2, 3, 4, 4}, versions)
+	assert.EqualValues(t, "Buy oat milk", events[1].Title)
+	assert.EqualValues(t, &done, events[2].CompletedAt)
+}
+
+func TestEventLog_Atomic(t *testing.T) {
+	t.Run("should show the writes of a transaction to reads all at once", func(t *testing.T) {
+		repo, err := OpenEventLog(filepath.Join(t.TempDir(), "todo.events"), WithSnapshotInterval(0))
+		require.NoError(t, err)
+		defer repo.Close()
+
+		done := make(chan struct{})
+		torn := make(chan int, 1)
+		go func() {
+			defer close(torn)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				page, err := repo.List(domain.ListQuery{})
+				if err == nil && len(page.Entries)%50 != 0 {
+					torn <- len(page.Entries)
+					return
+				}
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			require.NoError(t, repo.Atomic(func(repo ports.EntryRepository) error {
+				for j := 0; j < 50; j++ {
+					if err := repo.Save(&domain.Entry{ID: strconv.Itoa(i) + "-" + strconv.Itoa(j), Title: "Buy milk"}); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+		}
+		close(done)
+
+		for count := range torn {
+			t.Fatalf("listed %d entries, part of a transaction", count)
+		}
+	})
+
+	t.Run("should append no record of events that cannot be applied", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.events")
+		repo, err := OpenEventLog(path)
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(&domain.Entry{ID: "a", Title: "Buy milk"}))
+		size := repo.size
+
+		repo.mu.Lock()
+		err = repo.commit([]domain.EntryEvent{
+			{Type: domain.EntryRenamed, EntryID: "a", Version: 1, Title: "Buy bread"},
+			{Type: domain.EntryRenamed, EntryID: "missing", Version: 1, Title: "Buy eggs"},
+		})
+		repo.mu.Unlock()
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		assert.EqualValues(t, size, repo.size)
+		entry, err := repo.Get("a")
+		require.NoError(t, err)
+		assert.EqualValues(t, "Buy milk", entry.Title)
+		require.NoError(t, repo.Close())
+
+		repo, err = OpenEventLog(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		_, err = repo.Get("a")
+		assert.NoError(t, err)
+	})
+}
+
+func TestEventLog_Recover(t *testing.T) {
+	// write fills a new log at path with entries, and closes it.
+	write := func(t *testing.T, path string, opts ...EventLogOption) {
+		repo, err := OpenEventLog(path, opts...)
+		require.NoError(t, err)
+		defer repo.Close()
+
+		for _, id := range []string{"a", "b", "c"} {
+			require.NoError(t, repo.Save(&domain.Entry{ID: id, Title: "Buy milk", Tags: []string{"@shop"}}))
+		}
+		entry, err := repo.Get("b")
+		require.NoError(t, err)
+		entry.Title = "Buy bread"
+		require.NoError(t, repo.Update("b", entry))
+		require.NoError(t, repo.Delete("c"))
+	}
+
+	// verify checks that the repository at path holds the entries written by write.
+	verify := func(t *testing.T, repo *eventLog) {
+		page, err := repo.List(domain.ListQuery{Tags: []string{"@shop"}})
+		require.NoError(t, err)
+		assert.Len(t, page.Entries, 2)
+		entry, err := repo.Get("b")
+		require.NoError(t, err)
+		assert.EqualValues(t, "Buy bread", entry.Title)
+		assert.EqualValues(t, 1, entry.Version)
+		hits, err := repo.Search("bread")
+		require.NoError(t, err)
+		assert.Len(t, hits, 1)
+	}
+
+	for _, interval := range []int{0, 1, 2, 4} {
+		t.Run("should project the entries from the log and snapshots every "+strconv.Itoa(interval)+" events", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "todo.events")
+			write(t, path, WithSnapshotInterval(interval))
+
+			repo, err := OpenEventLog(path)
+			require.NoError(t, err)
+			defer repo.Close()
+			verify(t, repo)
+		})
+	}
+
+	t.Run("should cut off a torn record ending the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.events")
+		write(t, path)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = file.Write([]byte{0, 0, 1, 0, 1, 2, 3, 4, '[', '{'})
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		repo, err := OpenEventLog(path)
+		require.NoError(t, err)
+		verify(t, repo)
+		require.NoError(t, repo.Save(&domain.Entry{ID: "d", Title: "Buy eggs"}))
+		require.NoError(t, repo.Close())
+
+		repo, err = OpenEventLog(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		_, err = repo.Get("d")
+		assert.NoError(t, err)
+		grown, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Greater(t, grown.Size(), info.Size())
+	})
+
+	t.Run("should fail on a corrupt record followed by others", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.events")
+		write(t, path)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[frameHeaderSize+2] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err = OpenEventLog(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("should replay the whole log when the snapshot is unusable", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.events")
+		write(t, path, WithSnapshotInterval(1))
+		require.NoError(t, os.WriteFile(path+".snapshot", []byte(`{"offset": 1000000, "entries": []}`), 0o600))
+
+		repo, err := OpenEventLog(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		verify(t, repo)
+	})
+}
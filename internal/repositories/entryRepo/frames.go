@@ -0,0 +1,143 @@
+package entryRepo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// frameHeaderSize is the size of the header framing every record of the logs kept by the
+	// repositories: the length of the record and its CRC-32 checksum, both big-endian.
+	frameHeaderSize = 8
+	// maxFrameSize is the largest record the logs hold. Longer lengths can only be read from a
+	// corrupt header.
+	maxFrameSize = 64 << 20
+)
+
+// writeFrame writes payload, framed by its header, to file at offset and syncs the file when
+// asked to, returning the number of bytes written. On failure, the file is cut back to offset, as
+// a partly written frame would be taken for a torn one and hide the frames written after it.
+func writeFrame(file *os.File, offset int64, payload []byte, sync bool) (int64, error) {
+	if len(payload) > maxFrameSize {
+		return 0, fmt.Errorf("record of %d bytes exceeds the limit of %d bytes", len(payload), maxFrameSize)
+	}
+
+	frame := make([]byte, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[frameHeaderSize:], payload)
+
+	if _, err := file.WriteAt(frame, offset); err != nil {
+		_ = file.Truncate(offset)
+		return 0, err
+	}
+	if sync {
+		if err := file.Sync(); err != nil {
+			_ = file.Truncate(offset)
+			return 0, err
+		}
+	}
+	return int64(len(frame)), nil
+}
+
+// scanFrames reads the frames of file between offset and size, passing the payload of each to fn,
+// and returns the offset following the last complete frame. A frame extending past size, or the
+// last frame failing its checksum, was torn while being written and ends the scan; any other
+// frame failing its checksum, or any frame longer than a record can be, is an error.
+func scanFrames(file *os.File, offset, size int64, fn func(payload []byte) error) (int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(file, offset, size-offset))
+	header := make([]byte, frameHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return offset, nil
+			}
+			return offset, err
+		}
+		length := int64(binary.BigEndian.Uint32(header[0:4]))
+		if length > maxFrameSize {
+			return offset, fmt.Errorf("record at offset %d is corrupt", offset)
+		}
+		end := offset + frameHeaderSize + length
+		if end > size {
+			return offset, nil
+		}
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return offset, err
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+			if end == size {
+				return offset, nil
+			}
+			return offset, fmt.Errorf("record at offset %d is corrupt", offset)
+		}
+
+		if err := fn(payload); err != nil {
+			return offset, err
+		}
+		offset = end
+	}
+}
+
+// cutTornTail cuts file back to end, the offset following its last complete frame, when a torn
+// frame follows it.
+func cutTornTail(file *os.File, end, size int64) error {
+	if end == size {
+		return nil
+	}
+	if err := file.Truncate(end); err != nil {
+		return fmt.Errorf("cutting off torn record: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("syncing log: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomically replaces the file at path by one holding data, only once it is complete and
+// synced to disk, so that a crash leaves either the old or the new file behind. The directory is
+// synced as well, so that the replacement is durable once it returns.
+func writeFileAtomically(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir syncs the directory at path to disk, making the files created in it or renamed into it
+// durable.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+	return dir.Close()
+}
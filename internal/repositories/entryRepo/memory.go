@@ -2,68 +2,300 @@ package entryRepo
 
 import (
 	"encoding/json"
-	"errors"
 	"github.com/Nikym/go-todo/internal/core/domain"
+	"sync"
 )
 
+// memKVS guards kvs with a read-write lock, so any number of readers can access the
+// repository at the same time while writers get exclusive access. Stored byte slices are
+// never modified once written, which lets entries be encoded and decoded outside the lock.
+//
+// Alongside the entries, memKVS maintains an index from every tag to the IDs of the entries
+// carrying it, so that listing entries by tag only decodes the entries that can match, and a
+// full-text index of their titles and descriptions, so that searches only decode the entries
+// found.
+//
+// Repositories opened by OpenMemKVS also record every write in a write-ahead log before making
+// it, from which they are recovered after a crash.
 type memKVS struct {
-	kvs map[string][]byte
+	mu        sync.RWMutex
+	kvs       map[string][]byte
+	tagIndex  map[string]map[string]struct{}
+	entryTags map[string][]string
+	textIndex *domain.TextIndex
+	wal       *writeAheadLog
 }
 
 // NewMemKVS returns a pointer to an in-memory entry repository.
 func NewMemKVS() *memKVS {
 	return &memKVS{
-		kvs: map[string][]byte{},
+		kvs:       map[string][]byte{},
+		tagIndex:  map[string]map[string]struct{}{},
+		entryTags: map[string][]string{},
+		textIndex: domain.NewTextIndex(),
 	}
 }
 
 // Get retrieves an entry with a specified ID from the in-memory KVS repository.
 func (r *memKVS) Get(id string) (*domain.Entry, error) {
-	if val, ok := r.kvs[id]; ok {
+	r.mu.RLock()
+	val, ok := r.kvs[id]
+	r.mu.RUnlock()
+
+	if ok {
 		entry := domain.Entry{}
 		if err := json.Unmarshal(val, &entry); err != nil {
-			return &domain.Entry{}, err
+			return &domain.Entry{}, domain.Internal("decoding stored entry failed", err)
 		}
 		return &entry, nil
 	}
 
-	return &domain.Entry{}, errors.New("entry not found in repository")
+	return &domain.Entry{}, domain.NotFound("entry not found in repository")
 }
 
-// Save stores a given domain.Entry object in the in-memory KVS repository.
+// Save stores a given domain.Entry object in the in-memory KVS repository. Saving an entry
+// whose ID is already stored is a conflict.
 func (r *memKVS) Save(entry *domain.Entry) error {
 	if entry.ID != "" {
 		bytes, err := json.Marshal(*entry)
 		if err != nil {
+			return domain.Internal("encoding entry failed", err)
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if _, ok := r.kvs[entry.ID]; ok {
+			return domain.Conflict("entry with given id already exists in repository")
+		}
+		if err := r.logWrite(entry.ID, bytes); err != nil {
 			return err
 		}
-		r.kvs[entry.ID] = bytes
+		r.store(entry.ID, bytes, entry)
+		r.compactIfDue()
 		return nil
 	}
 
-	return errors.New("id cannot be an empty string")
+	return domain.NewValidationError("id", "cannot be an empty string")
 }
 
 // Delete removes a domain.Entry object with a given ID from the in-memory KVS repository.
 func (r *memKVS) Delete(id string) error {
 	if id != "" {
-		delete(r.kvs, id)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if _, ok := r.kvs[id]; !ok {
+			return nil
+		}
+		if err := r.logWrite(id, nil); err != nil {
+			return err
+		}
+		r.remove(id)
+		r.compactIfDue()
 		return nil
 	}
-	return errors.New("id cannot be an empty string")
+	return domain.NewValidationError("id", "cannot be an empty string")
 }
 
-// Update sets the entry stored in KVS repository with given ID to the domain.Entry specified.
+// Update sets the entry stored in KVS repository with given ID to the domain.Entry specified,
+// provided the stored entry is still at the version of the given one.
 func (r *memKVS) Update(id string, entry *domain.Entry) error {
-	if _, ok := r.kvs[id]; ok {
+	updated := *entry
+	updated.ID = id
+	updated.Version++
+	bytes, err := json.Marshal(updated)
+	if err != nil {
+		return domain.Internal("encoding entry failed", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if val, ok := r.kvs[id]; ok {
+		if err := checkVersion(val, entry.Version); err != nil {
+			return err
+		}
+		if err := r.logWrite(id, bytes); err != nil {
+			return err
+		}
+		r.store(id, bytes, &updated)
+		entry.Version = updated.Version
+		r.compactIfDue()
+		return nil
+	}
+
+	return domain.NotFound("no entry with given id found in repository")
+}
+
+// List returns the page of entries stored in the in-memory KVS repository that match the query.
+func (r *memKVS) List(query domain.ListQuery) (*domain.EntryPage, error) {
+	matched, err := r.match(query)
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+
+	return domain.Paginate(matched, query)
+}
+
+// Tags counts the tags of the entries stored in the in-memory KVS repository that match the query.
+func (r *memKVS) Tags(query domain.ListQuery) ([]domain.TagCount, error) {
+	matched, err := r.match(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.CountTags(matched), nil
+}
+
+// Search returns the entries stored in the in-memory KVS repository whose title or description
+// contains every word of text, most relevant first.
+func (r *memKVS) Search(text string) ([]domain.SearchHit, error) {
+	r.mu.RLock()
+	matches := r.textIndex.Search(text)
+	values := make([][]byte, len(matches))
+	for i, match := range matches {
+		values[i] = r.kvs[match.ID]
+	}
+	r.mu.RUnlock()
+
+	hits := make([]domain.SearchHit, len(matches))
+	for i, val := range values {
+		entry := domain.Entry{}
+		if err := json.Unmarshal(val, &entry); err != nil {
+			return nil, domain.Internal("decoding stored entry failed", err)
+		}
+		hits[i] = domain.SearchHit{Entry: &entry, Score: matches[i].Score}
+	}
+
+	return hits, nil
+}
+
+// match decodes and returns every stored entry matching the query. When the query filters by
+// tag, only the entries found in the tag index are considered.
+func (r *memKVS) match(query domain.ListQuery) ([]*domain.Entry, error) {
+	r.mu.RLock()
+	values := r.candidates(query.Tags)
+	r.mu.RUnlock()
+
+	var matched []*domain.Entry
+	for _, val := range values {
+		entry := domain.Entry{}
+		if err := json.Unmarshal(val, &entry); err != nil {
+			return nil, domain.Internal("decoding stored entry failed", err)
+		}
+		if query.Matches(&entry) {
+			matched = append(matched, &entry)
+		}
+	}
+
+	return matched, nil
+}
+
+// candidates returns the stored values of every entry that may carry all the given tags,
+// walking the smallest of the indexed sets. It must be called with the lock held.
+func (r *memKVS) candidates(tags []string) [][]byte {
+	if len(tags) == 0 {
+		values := make([][]byte, 0, len(r.kvs))
+		for _, val := range r.kvs {
+			values = append(values, val)
+		}
+		return values
+	}
+
+	smallest := r.tagIndex[tags[0]]
+	for _, tag := range tags[1:] {
+		if ids := r.tagIndex[tag]; len(ids) < len(smallest) {
+			smallest = ids
+		}
+	}
+
+	values := make([][]byte, 0, len(smallest))
+	for id := range smallest {
+		values = append(values, r.kvs[id])
+	}
+	return values
+}
+
+// indexTags replaces the tags indexed for the entry with the given ID. It must be called with
+// the write lock held.
+func (r *memKVS) indexTags(id string, tags []string) {
+	for _, tag := range r.entryTags[id] {
+		delete(r.tagIndex[tag], id)
+		if len(r.tagIndex[tag]) == 0 {
+			delete(r.tagIndex, tag)
+		}
+	}
+
+	if len(tags) == 0 {
+		delete(r.entryTags, id)
+		return
+	}
+
+	r.entryTags[id] = append([]string(nil), tags...)
+	for _, tag := range tags {
+		if r.tagIndex[tag] == nil {
+			r.tagIndex[tag] = map[string]struct{}{}
+		}
+		r.tagIndex[tag][id] = struct{}{}
+	}
+}
+
+// put stores entry as it is, replacing any entry stored with its ID, without the checks made by
+// Save and Update.
+func (r *memKVS) put(entry *domain.Entry) error {
+	bytes, err := json.Marshal(*entry)
+	if err != nil {
+		return domain.Internal("encoding entry failed", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store(entry.ID, bytes, entry)
+	return nil
+}
+
+// putAll stores the given entries by ID and removes those given as nil, in a single write, so
+// that readers see either none of the changes or all of them.
+func (r *memKVS) putAll(entries map[string]*domain.Entry) error {
+	encoded := make(map[string][]byte, len(entries))
+	for id, entry := range entries {
+		if entry == nil {
+			continue
+		}
 		bytes, err := json.Marshal(*entry)
 		if err != nil {
-			return err
+			return domain.Internal("encoding entry failed", err)
 		}
+		encoded[id] = bytes
+	}
 
-		r.kvs[id] = bytes
-		return nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, entry := range entries {
+		if entry == nil {
+			r.remove(id)
+			continue
+		}
+		r.store(id, encoded[id], entry)
 	}
+	return nil
+}
+
+// store sets the value stored with the given ID to bytes, the encoding of entry, and indexes
+// entry. It must be called with the write lock held.
+func (r *memKVS) store(id string, bytes []byte, entry *domain.Entry) {
+	r.kvs[id] = bytes
+	r.indexTags(id, entry.Tags)
+	r.textIndex.Add(entry)
+}
 
-	return errors.New("no entry with given id found in repository")
+// remove removes the value stored with the given ID from the store and its indexes. It must be
+// called with the write lock held.
+func (r *memKVS) remove(id string) {
+	delete(r.kvs, id)
+	r.indexTags(id, nil)
+	r.textIndex.Remove(id)
 }
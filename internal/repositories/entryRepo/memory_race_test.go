@@ -0,0 +1,137 @@
+package entryRepo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+// The tests in this file are meant to be run with the race detector enabled:
+//
+//	go test -race ./internal/repositories/entryRepo
+//
+// Without it they still check that concurrent access leaves the repository consistent.
+
+const (
+	workers    = 16
+	iterations = 200
+)
+
+func TestMemKVS_ConcurrentDistinctKeys(t *testing.T) {
+	kvs := NewMemKVS()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				id := fmt.Sprintf("worker-%d-%d", w, i)
+				entry := &domain.Entry{ID: id, Title: "Title"}
+				if err := kvs.Save(entry); err != nil {
+					errs <- err
+					return
+				}
+				entry.Done = true
+				if err := kvs.Update(id, entry); err != nil {
+					errs <- err
+					return
+				}
+				stored, err := kvs.Get(id)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !stored.Done {
+					errs <- fmt.Errorf("entry %s lost its update", id)
+					return
+				}
+				if i%2 == 0 {
+					if err := kvs.Delete(id); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Len(t, kvs.kvs, workers*iterations/2)
+}
+
+func TestMemKVS_ConcurrentSharedKey(t *testing.T) {
+	kvs := NewMemKVS()
+	const id = "shared"
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4*workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(4)
+
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				err := kvs.Save(&domain.Entry{ID: id, Title: fmt.Sprintf("saved by %d", w)})
+				if err != nil && !errors.Is(err, domain.ErrConflict) {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				err := kvs.Update(id, &domain.Entry{ID: id, Title: fmt.Sprintf("updated by %d", w)})
+				if err != nil && !errors.Is(err, domain.ErrNotFound) && !errors.Is(err, domain.ErrPreconditionFailed) {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				entry, err := kvs.Get(id)
+				if err != nil && !errors.Is(err, domain.ErrNotFound) {
+					errs <- err
+					return
+				}
+				if err == nil && entry.ID != id {
+					errs <- fmt.Errorf("read a torn entry %+v", entry)
+					return
+				}
+				if _, err := kvs.List(domain.ListQuery{}); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if err := kvs.Delete(id); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
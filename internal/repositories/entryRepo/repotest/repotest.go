@@ -0,0 +1,736 @@
+// Package repotest provides a conformance suite verifying that an implementation of
+// ports.EntryRepository honours the contract every repository shares.
+//
+// Each backend calls Run from its own tests with a factory returning an empty repository:
+//
+//	func TestMemKVS(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) ports.EntryRepository { return NewMemKVS() })
+//	}
+package repotest
+
+import (
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Factory returns a new, empty repository. Resources it allocates should be released
+// through t.Cleanup.
+type Factory func(t *testing.T) ports.EntryRepository
+
+// AtomicFactory returns a new, empty repository supporting transactions.
+type AtomicFactory func(t *testing.T) ports.AtomicEntryRepository
+
+const storedID = "5b2c9d9f-7bb2-401d-b0e1-1e5d8ea955ca"
+
+func storedEntry() *domain.Entry {
+	return &domain.Entry{
+		ID:          storedID,
+		Title:       "Test Title",
+		Description: "Test Description",
+		Done:        false,
+	}
+}
+
+// setUp returns a repository from the factory holding a single entry, storedEntry.
+func setUp(t *testing.T, newRepo Factory) ports.EntryRepository {
+	repo := newRepo(t)
+	require.NoError(t, repo.Save(storedEntry()))
+	return repo
+}
+
+// Run verifies every behaviour of the ports.EntryRepository contract against repositories
+// created by newRepo.
+func Run(t *testing.T, newRepo Factory) {
+	t.Run("Get", func(t *testing.T) { testGet(t, newRepo) })
+	t.Run("Save", func(t *testing.T) { testSave(t, newRepo) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newRepo) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepo) })
+	t.Run("Versions", func(t *testing.T) { testVersions(t, newRepo) })
+	t.Run("Fields", func(t *testing.T) { testFields(t, newRepo) })
+	t.Run("Isolation", func(t *testing.T) { testIsolation(t, newRepo) })
+	t.Run("List", func(t *testing.T) { testList(t, newRepo) })
+	t.Run("Tags", func(t *testing.T) { testTags(t, newRepo) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, newRepo) })
+}
+
+// RunAtomic verifies the ports.AtomicEntryRepository contract, in addition to everything
+// checked by Run, against repositories created by newRepo.
+func RunAtomic(t *testing.T, newRepo AtomicFactory) {
+	Run(t, func(t *testing.T) ports.EntryRepository { return newRepo(t) })
+	t.Run("Atomic", func(t *testing.T) { testAtomic(t, newRepo) })
+}
+
+func testGet(t *testing.T, newRepo Factory) {
+	repo := setUp(t, newRepo)
+
+	tests := []struct {
+		name     string
+		key      string
+		expected *domain.Entry
+		err      error
+	}{
+		{
+			name:     "should return entry when id of stored entry given",
+			key:      storedID,
+			expected: storedEntry(),
+		},
+		{
+			name:     "should return not found error when id not found in repository",
+			key:      "invalid",
+			expected: &domain.Entry{},
+			err:      domain.ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := repo.Get(test.key)
+
+			assert.EqualValues(t, test.expected, actual)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func testSave(t *testing.T, newRepo Factory) {
+	repo := setUp(t, newRepo)
+
+	tests := []struct {
+		name     string
+		input    *domain.Entry
+		expected *domain.Entry
+		err      error
+	}{
+		{
+			name:     "should save an entry when a valid entry given",
+			input:    &domain.Entry{ID: "valid", Title: "Test Title 2", Description: "Test Description 2"},
+			expected: &domain.Entry{ID: "valid", Title: "Test Title 2", Description: "Test Description 2"},
+		},
+		{
+			name:     "should return conflict error and keep stored entry when id already stored",
+			input:    &domain.Entry{ID: storedID, Title: "Overwritten"},
+			expected: storedEntry(),
+			err:      domain.ErrConflict,
+		},
+		{
+			name:  "should return validation error when empty string given for id",
+			input: &domain.Entry{ID: "", Title: "Test Title 2"},
+			err:   domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := repo.Save(test.input)
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if test.expected != nil {
+				stored, err := repo.Get(test.expected.ID)
+				assert.NoError(t, err)
+				assert.EqualValues(t, test.expected, stored)
+			}
+		})
+	}
+}
+
+func testDelete(t *testing.T, newRepo Factory) {
+	repo := setUp(t, newRepo)
+
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{
+			name:  "should remove entry when id of a stored entry given",
+			input: storedID,
+		},
+		{
+			name:  "should return no error when id of not stored entry given",
+			input: "test",
+		},
+		{
+			name:  "should return validation error when empty string given for id",
+			input: "",
+			err:   domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := repo.Delete(test.input)
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			_, err = repo.Get(test.input)
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+		})
+	}
+}
+
+func testUpdate(t *testing.T, newRepo Factory) {
+	repo := setUp(t, newRepo)
+
+	tests := []struct {
+		name       string
+		inputId    string
+		inputEntry *domain.Entry
+		err        error
+	}{
+		{
+			name:    "should change the stored entry to new details when given entry id present in repository",
+			inputId: storedID,
+			inputEntry: &domain.Entry{
+				ID:          storedID,
+				Title:       "Updated Title",
+				Description: "Updated Description",
+				Done:        true,
+			},
+		},
+		{
+			name:       "should return not found error and not create entry when no entry present with given id",
+			inputId:    "invalid",
+			inputEntry: &domain.Entry{ID: "invalid", Title: "Updated Title"},
+			err:        domain.ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := repo.Update(test.inputId, test.inputEntry)
+
+			stored, getErr := repo.Get(test.inputId)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				assert.ErrorIs(t, getErr, domain.ErrNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, getErr)
+			assert.EqualValues(t, test.inputEntry, stored)
+		})
+	}
+
+	t.Run("should keep the id of the stored entry when the given entry carries another", func(t *testing.T) {
+		repo := setUp(t, newRepo)
+		entry := storedEntry()
+		entry.ID, entry.Title = "other", "Renamed"
+		require.NoError(t, repo.Update(storedID, entry))
+
+		stored, err := repo.Get(storedID)
+		require.NoError(t, err)
+		assert.EqualValues(t, storedID, stored.ID)
+		assert.EqualValues(t, "Renamed", stored.Title)
+		_, err = repo.Get("other")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		page, err := repo.List(domain.ListQuery{})
+		require.NoError(t, err)
+		assert.EqualValues(t, []string{storedID}, ids(page.Entries))
+		if searchable, ok := repo.(ports.SearchableEntryRepository); ok {
+			hits, err := searchable.Search("renamed")
+			require.NoError(t, err)
+			if assert.Len(t, hits, 1) {
+				assert.EqualValues(t, storedID, hits[0].Entry.ID)
+			}
+		}
+	})
+}
+
+func testVersions(t *testing.T, newRepo Factory) {
+	t.Run("should increment the version of the stored and the given entry", func(t *testing.T) {
+		repo := setUp(t, newRepo)
+
+		for expected := int64(1); expected <= 3; expected++ {
+			stored, err := repo.Get(storedID)
+			require.NoError(t, err)
+			stored.Title = fmt.Sprintf("Version %d", expected)
+			require.NoError(t, repo.Update(storedID, stored))
+			assert.EqualValues(t, expected, stored.Version)
+		}
+
+		stored, err := repo.Get(storedID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, stored.Version)
+		assert.EqualValues(t, "Version 3", stored.Title)
+	})
+
+	t.Run("should return precondition failed and keep the stored entry when version is stale", func(t *testing.T) {
+		repo := setUp(t, newRepo)
+		first, err := repo.Get(storedID)
+		require.NoError(t, err)
+		second, err := repo.Get(storedID)
+		require.NoError(t, err)
+
+		first.Title = "First"
+		require.NoError(t, repo.Update(storedID, first))
+		second.Title = "Second"
+		assert.ErrorIs(t, repo.Update(storedID, second), domain.ErrPreconditionFailed)
+		assert.EqualValues(t, 0, second.Version)
+
+		stored, err := repo.Get(storedID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "First", stored.Title)
+	})
+
+	t.Run("should let a single one of concurrent updates of a version succeed", func(t *testing.T) {
+		repo := setUp(t, newRepo)
+
+		const writers = 8
+		var wg sync.WaitGroup
+		results := make(chan error, writers)
+		for w := 0; w < writers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				entry := storedEntry()
+				entry.Title = fmt.Sprintf("Writer %d", w)
+				results <- repo.Update(storedID, entry)
+			}(w)
+		}
+		wg.Wait()
+		close(results)
+
+		succeeded := 0
+		for err := range results {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+		}
+		assert.EqualValues(t, 1, succeeded)
+	})
+}
+
+func testFields(t *testing.T, newRepo Factory) {
+	repo := newRepo(t)
+	due := time.Date(2021, 3, 4, 17, 30, 0, 0, time.UTC)
+	completed := time.Date(2021, 3, 2, 9, 15, 30, 123456789, time.UTC)
+	until := time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("should store and return every field of an entry", func(t *testing.T) {
+		entry := &domain.Entry{
+			ID:          "all-fields",
+			Title:       "Every field",
+			Description: "Has them all",
+			Done:        true,
+			OwnerID:     "alice",
+			ParentID:    "parent",
+			ListID:      "list",
+			Priority:    domain.PriorityHigh,
+			Tags:        []string{"#release-2.3", "@work"},
+			DueAt:       &due,
+			Recurrence: &domain.Recurrence{
+				Freq:     domain.FreqWeekly,
+				Interval: 2,
+				ByDay:    []domain.WeekdayNum{{Weekday: time.Monday}, {Weekday: time.Thursday}},
+				Until:    &until,
+				TZID:     "Europe/London",
+			},
+			Occurrence:  3,
+			CreatedAt:   time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC),
+			UpdatedAt:   completed,
+			CompletedAt: &completed,
+		}
+		require.NoError(t, repo.Save(entry))
+
+		stored, err := repo.Get(entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, entry, stored)
+	})
+
+	t.Run("should clear optional fields on update", func(t *testing.T) {
+		entry := &domain.Entry{ID: "all-fields", Title: "Every field", Priority: domain.PriorityLow}
+		require.NoError(t, repo.Update(entry.ID, entry))
+
+		stored, err := repo.Get(entry.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, entry, stored)
+	})
+}
+
+func testIsolation(t *testing.T, newRepo Factory) {
+	t.Run("should not be affected by changes to a returned entry", func(t *testing.T) {
+		repo := setUp(t, newRepo)
+
+		returned, err := repo.Get(storedID)
+		require.NoError(t, err)
+		returned.Title = "Changed"
+
+		stored, err := repo.Get(storedID)
+		require.NoError(t, err)
+		assert.EqualValues(t, storedEntry(), stored)
+	})
+
+	t.Run("should not be affected by changes to a listed entry", func(t *testing.T) {
+		repo := setUp(t, newRepo)
+
+		page, err := repo.List(domain.ListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Entries, 1)
+		page.Entries[0].Title = "Changed"
+
+		stored, err := repo.Get(storedID)
+		require.NoError(t, err)
+		assert.EqualValues(t, storedEntry(), stored)
+	})
+
+	t.Run("should not be affected by changes to a saved entry", func(t *testing.T) {
+		repo := newRepo(t)
+
+		saved := storedEntry()
+		require.NoError(t, repo.Save(saved))
+		saved.Title = "Changed"
+
+		stored, err := repo.Get(storedID)
+		require.NoError(t, err)
+		assert.EqualValues(t, storedEntry(), stored)
+	})
+
+	t.Run("should not be affected by changes to an updated entry", func(t *testing.T) {
+		repo := setUp(t, newRepo)
+
+		updated := storedEntry()
+		updated.Done = true
+		require.NoError(t, repo.Update(storedID, updated))
+		updated.Title = "Changed"
+
+		stored, err := repo.Get(storedID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Test Title", stored.Title)
+		assert.True(t, stored.Done)
+	})
+}
+
+func testList(t *testing.T, newRepo Factory) {
+	repo := newRepo(t)
+	day := func(d int) *time.Time {
+		t := time.Date(2021, 3, d, 12, 0, 0, 0, time.UTC)
+		return &t
+	}
+	for _, entry := range []*domain.Entry{
+		{ID: "a", Title: "Buy milk", Done: false, OwnerID: "alice", Priority: domain.PriorityHigh, Tags: []string{"@shop"}, DueAt: day(3), CreatedAt: *day(1)},
+		{ID: "b", Title: "Walk dog", Done: true, ListID: "home", Priority: domain.PriorityLow, Tags: []string{"@home"}, CreatedAt: *day(4)},
+		{ID: "c", Title: "buy bread", Done: false, OwnerID: "alice", ParentID: "a", Priority: domain.PriorityHigh, Tags: []string{"@shop", "bakery"}, DueAt: day(1), CreatedAt: *day(3)},
+		{ID: "d", Title: "Clean kitchen", Done: false, ListID: "home", Tags: []string{"@home"}, DueAt: day(10), CreatedAt: *day(2)},
+	} {
+		require.NoError(t, repo.Save(entry))
+	}
+
+	notDone := false
+	high := domain.PriorityHigh
+	topLevel, parent := "", "a"
+	unlisted, home := "", "home"
+
+	tests := []struct {
+		name     string
+		query    domain.ListQuery
+		expected []string
+		err      error
+	}{
+		{
+			name:     "should return all entries ordered by id when query is empty",
+			query:    domain.ListQuery{},
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "should filter entries by done status",
+			query:    domain.ListQuery{Done: &notDone},
+			expected: []string{"a", "c", "d"},
+		},
+		{
+			name:     "should filter entries by parent",
+			query:    domain.ListQuery{ParentID: &parent},
+			expected: []string{"c"},
+		},
+		{
+			name:     "should filter top-level entries",
+			query:    domain.ListQuery{ParentID: &topLevel},
+			expected: []string{"a", "b", "d"},
+		},
+		{
+			name:     "should filter entries by list",
+			query:    domain.ListQuery{ListID: &home},
+			expected: []string{"b", "d"},
+		},
+		{
+			name:     "should filter entries in no list",
+			query:    domain.ListQuery{ListID: &unlisted},
+			expected: []string{"a", "c"},
+		},
+		{
+			name:     "should filter entries by owner",
+			query:    domain.ListQuery{OwnerID: "alice"},
+			expected: []string{"a", "c"},
+		},
+		{
+			name:     "should filter entries by case-insensitive title substring",
+			query:    domain.ListQuery{TitleContains: "BUY"},
+			expected: []string{"a", "c"},
+		},
+		{
+			name:     "should sort entries by title in descending order",
+			query:    domain.ListQuery{SortBy: domain.SortByTitle, Order: domain.SortDescending},
+			expected: []string{"b", "d", "a", "c"},
+		},
+		{
+			name:     "should filter entries by tag",
+			query:    domain.ListQuery{Tags: []string{"@shop"}},
+			expected: []string{"a", "c"},
+		},
+		{
+			name:     "should filter entries carrying every given tag",
+			query:    domain.ListQuery{Tags: []string{"bakery", "@shop"}},
+			expected: []string{"c"},
+		},
+		{
+			name:     "should filter open entries by tag",
+			query:    domain.ListQuery{Done: &notDone, Tags: []string{"@home"}},
+			expected: []string{"d"},
+		},
+		{
+			name:     "should return no entries for an unknown tag",
+			query:    domain.ListQuery{Tags: []string{"@nowhere"}},
+			expected: nil,
+		},
+		{
+			name:     "should filter entries by priority",
+			query:    domain.ListQuery{Priority: &high},
+			expected: []string{"a", "c"},
+		},
+		{
+			name:     "should filter entries due within a time range",
+			query:    domain.ListQuery{DueAfter: day(1), DueBefore: day(10)},
+			expected: []string{"a"},
+		},
+		{
+			name:     "should sort entries by priority then id in descending order",
+			query:    domain.ListQuery{SortBy: domain.SortByPriority, Order: domain.SortDescending},
+			expected: []string{"c", "a", "b", "d"},
+		},
+		{
+			name:     "should sort entries by due date with undated entries last",
+			query:    domain.ListQuery{SortBy: domain.SortByDueAt},
+			expected: []string{"c", "a", "d", "b"},
+		},
+		{
+			name:     "should sort entries by creation time",
+			query:    domain.ListQuery{SortBy: domain.SortByCreatedAt},
+			expected: []string{"a", "d", "c", "b"},
+		},
+		{
+			name:     "should limit the number of entries returned",
+			query:    domain.ListQuery{Limit: 2},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:  "should return validation error when cursor is malformed",
+			query: domain.ListQuery{Cursor: "%%%"},
+			err:   domain.ErrValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := repo.List(test.query)
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.EqualValues(t, test.expected, ids(page.Entries))
+		})
+	}
+
+	t.Run("should page through every entry using the next cursor", func(t *testing.T) {
+		query := domain.ListQuery{SortBy: domain.SortByTitle, Limit: 3}
+		var listed []string
+		for {
+			page, err := repo.List(query)
+			require.NoError(t, err)
+			listed = append(listed, ids(page.Entries)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		assert.EqualValues(t, []string{"c", "a", "d", "b"}, listed)
+	})
+}
+
+func testTags(t *testing.T, newRepo Factory) {
+	repo := newRepo(t)
+	for _, entry := range []*domain.Entry{
+		{ID: "a", Title: "Buy milk", Tags: []string{"@shop"}},
+		{ID: "b", Title: "Walk dog", Done: true, ListID: "home", Tags: []string{"@home", "pets"}},
+		{ID: "c", Title: "buy bread", Tags: []string{"@shop", "bakery"}},
+		{ID: "d", Title: "Clean kitchen", Tags: []string{"@home"}},
+		{ID: "e", Title: "Untagged"},
+	} {
+		require.NoError(t, repo.Save(entry))
+	}
+
+	notDone := false
+
+	t.Run("should count the entries carrying each tag", func(t *testing.T) {
+		tags, err := repo.Tags(domain.ListQuery{})
+		require.NoError(t, err)
+		assert.EqualValues(t, []domain.TagCount{
+			{Tag: "@home", Count: 2},
+			{Tag: "@shop", Count: 2},
+			{Tag: "bakery", Count: 1},
+			{Tag: "pets", Count: 1},
+		}, tags)
+	})
+
+	t.Run("should only count the tags of entries matching the query", func(t *testing.T) {
+		tags, err := repo.Tags(domain.ListQuery{Done: &notDone})
+		require.NoError(t, err)
+		assert.EqualValues(t, []domain.TagCount{
+			{Tag: "@shop", Count: 2},
+			{Tag: "@home", Count: 1},
+			{Tag: "bakery", Count: 1},
+		}, tags)
+	})
+
+	t.Run("should reflect tags changed by updates and deletes", func(t *testing.T) {
+		require.NoError(t, repo.Update("a", &domain.Entry{ID: "a", Title: "Buy milk", Tags: []string{"dairy"}}))
+		require.NoError(t, repo.Delete("c"))
+
+		page, err := repo.List(domain.ListQuery{Tags: []string{"@shop"}})
+		require.NoError(t, err)
+		assert.Empty(t, page.Entries)
+
+		page, err = repo.List(domain.ListQuery{Tags: []string{"dairy"}})
+		require.NoError(t, err)
+		assert.EqualValues(t, []string{"a"}, ids(page.Entries))
+
+		tags, err := repo.Tags(domain.ListQuery{})
+		require.NoError(t, err)
+		assert.EqualValues(t, []domain.TagCount{
+			{Tag: "@home", Count: 2},
+			{Tag: "dairy", Count: 1},
+			{Tag: "pets", Count: 1},
+		}, tags)
+	})
+}
+
+func testConcurrency(t *testing.T, newRepo Factory) {
+	repo := newRepo(t)
+
+	const workers, iterations = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				id := fmt.Sprintf("worker-%d-%d", w, i)
+				entry := &domain.Entry{ID: id, Title: "Title"}
+				if err := repo.Save(entry); err != nil {
+					errs <- err
+					return
+				}
+				entry.Done = true
+				if err := repo.Update(id, entry); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := repo.Get(id); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := repo.List(domain.ListQuery{Limit: 5}); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	page, err := repo.List(domain.ListQuery{})
+	require.NoError(t, err)
+	assert.Len(t, page.Entries, workers*iterations)
+}
+
+func testAtomic(t *testing.T, newRepo AtomicFactory) {
+	repo := newRepo(t)
+
+	t.Run("should apply every write when the transaction succeeds", func(t *testing.T) {
+		err := repo.Atomic(func(tx ports.EntryRepository) error {
+			if err := tx.Save(&domain.Entry{ID: "first", Title: "First"}); err != nil {
+				return err
+			}
+			return tx.Save(&domain.Entry{ID: "second", Title: "Second"})
+		})
+		assert.NoError(t, err)
+
+		_, err = repo.Get("first")
+		assert.NoError(t, err)
+		_, err = repo.Get("second")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should discard every write when the transaction fails", func(t *testing.T) {
+		err := repo.Atomic(func(tx ports.EntryRepository) error {
+			if err := tx.Save(&domain.Entry{ID: "third", Title: "Third"}); err != nil {
+				return err
+			}
+			if err := tx.Delete("first"); err != nil {
+				return err
+			}
+			return tx.Save(&domain.Entry{ID: "second", Title: "Duplicate"})
+		})
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		_, err = repo.Get("third")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = repo.Get("first")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should see its own writes within the transaction", func(t *testing.T) {
+		err := repo.Atomic(func(tx ports.EntryRepository) error {
+			if err := tx.Save(&domain.Entry{ID: "fourth", Title: "Fourth"}); err != nil {
+				return err
+			}
+			_, err := tx.Get("fourth")
+			return err
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func ids(entries []*domain.Entry) []string {
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
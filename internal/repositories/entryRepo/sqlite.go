@@ -0,0 +1,528 @@
+package entryRepo
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"strings"
+	"time"
+)
+
+// sqliteRepo keeps entries in the entries table, their tags in entry_tags and the terms of their
+// titles and descriptions in entry_terms, which serves as their full-text index. A repository
+// with a transaction is the view of the repository within it.
+type sqliteRepo struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// querier runs statements either directly against the database or within a transaction.
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// NewSQLite returns a pointer to an entry repository backed by the given SQLite database,
+// whose schema is expected to have been migrated by sqliteDB.Open.
+func NewSQLite(db *sql.DB) *sqliteRepo {
+	return &sqliteRepo{
+		db: db,
+	}
+}
+
+// entryColumns lists the columns of the entries table written by Save and Update, in the order
+// of the values returned by entryValues.
+var entryColumns = []string{
+	"id", "title", "description", "done", "priority", "due_at", "created_at", "updated_at", "completed_at",
+	"parent_id", "recurrence", "occurrence", "list_id", "owner_id", "version",
+}
+
+// selectEntry selects the columns read by scanEntry: entryColumns followed by the tags of the
+// entry joined by the unit separator.
+var selectEntry = `SELECT ` + strings.Join(entryColumns, ", ") + `,
+	(SELECT group_concat(tag, char(31) ORDER BY tag) FROM entry_tags WHERE entry_id = entries.id)
+	FROM entries`
+
+const tagSeparator = "\x1f"
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row scanner) (*domain.Entry, error) {
+	entry := domain.Entry{}
+	var dueAt, completedAt, tags sql.NullString
+	var createdAt, updatedAt, recurrence string
+	if err := row.Scan(
+		&entry.ID, &entry.Title, &entry.Description, &entry.Done, &entry.Priority,
+		&dueAt, &createdAt, &updatedAt, &completedAt, &entry.ParentID, &recurrence, &entry.Occurrence,
+		&entry.ListID, &entry.OwnerID, &entry.Version, &tags,
+	); err != nil {
+		return &domain.Entry{}, err
+	}
+
+	var err error
+	if entry.DueAt, err = parseNullTime(dueAt); err != nil {
+		return &domain.Entry{}, err
+	}
+	if entry.CreatedAt, err = sqliteDB.ParseTime(createdAt); err != nil {
+		return &domain.Entry{}, err
+	}
+	if entry.UpdatedAt, err = sqliteDB.ParseTime(updatedAt); err != nil {
+		return &domain.Entry{}, err
+	}
+	if entry.CompletedAt, err = parseNullTime(completedAt); err != nil {
+		return &domain.Entry{}, err
+	}
+	if recurrence != "" {
+		if entry.Recurrence, err = domain.ParseRecurrence(recurrence); err != nil {
+			return &domain.Entry{}, err
+		}
+	}
+	if tags.Valid {
+		entry.Tags = strings.Split(tags.String, tagSeparator)
+	}
+	return &entry, nil
+}
+
+// entryValues returns the values of the entry in the order of entryColumns.
+func entryValues(entry *domain.Entry) []interface{} {
+	return []interface{}{
+		entry.ID, entry.Title, entry.Description, entry.Done, entry.Priority,
+		formatNullTime(entry.DueAt), sqliteDB.FormatTime(entry.CreatedAt), sqliteDB.FormatTime(entry.UpdatedAt),
+		formatNullTime(entry.CompletedAt), entry.ParentID, formatRecurrence(entry.Recurrence), entry.Occurrence,
+		entry.ListID, entry.OwnerID, entry.Version,
+	}
+}
+
+// Atomic runs fn against a view of the repository in which every write either succeeds
+// together with the others or, if fn returns an error, is discarded.
+func (r *sqliteRepo) Atomic(fn func(repo ports.EntryRepository) error) error {
+	return r.inTx("transaction failed", func(tx *sql.Tx) error {
+		return fn(&sqliteRepo{db: r.db, tx: tx})
+	})
+}
+
+// Get retrieves an entry with a specified ID from the SQLite repository.
+func (r *sqliteRepo) Get(id string) (*domain.Entry, error) {
+	entry, err := scanEntry(r.conn().QueryRow(selectEntry+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.Entry{}, domain.NotFound("entry not found in repository")
+	}
+	if err != nil {
+		return &domain.Entry{}, domain.Internal("reading entry failed", err)
+	}
+
+	return entry, nil
+}
+
+// Save stores a given domain.Entry object in the SQLite repository. Saving an entry whose ID is
+// already stored is a conflict.
+func (r *sqliteRepo) Save(entry *domain.Entry) error {
+	if entry.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	return r.inTx("inserting entry failed", func(tx *sql.Tx) error {
+		res, err := tx.Exec(
+			`INSERT INTO entries (`+strings.Join(entryColumns, ", ")+`) VALUES (`+placeholders(len(entryColumns))+`)
+			ON CONFLICT (id) DO NOTHING`,
+			entryValues(entry)...,
+		)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(res, domain.Conflict("entry with given id already exists in repository")); err != nil {
+			return err
+		}
+
+		if err := writeTags(tx, entry.ID, entry.Tags); err != nil {
+			return err
+		}
+		return writeTerms(tx, entry.ID, entry)
+	})
+}
+
+// Delete removes a domain.Entry object with a given ID from the SQLite repository.
+func (r *sqliteRepo) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	if _, err := r.conn().Exec(`DELETE FROM entries WHERE id = ?`, id); err != nil {
+		return domain.Internal("deleting entry failed", err)
+	}
+
+	return nil
+}
+
+// Update sets the entry stored in the SQLite repository with given ID to the domain.Entry specified,
+// provided the stored entry is still at the version of the given one.
+func (r *sqliteRepo) Update(id string, entry *domain.Entry) error {
+	updated := *entry
+	updated.Version++
+	if err := r.inTx("updating entry failed", func(tx *sql.Tx) error {
+		columns := entryColumns[1:]
+		assignments := make([]string, len(columns))
+		for i, column := range columns {
+			assignments[i] = column + ` = ?`
+		}
+
+		res, err := tx.Exec(
+			`UPDATE entries SET `+strings.Join(assignments, ", ")+` WHERE id = ? AND version = ?`,
+			append(entryValues(&updated)[1:], id, entry.Version)...,
+		)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(res, errNoRows); err != nil {
+			if errors.Is(err, errNoRows) {
+				return staleOrMissing(tx, id, entry.Version)
+			}
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM entry_tags WHERE entry_id = ?`, id); err != nil {
+			return err
+		}
+		if err := writeTags(tx, id, entry.Tags); err != nil {
+			return err
+		}
+		return writeTerms(tx, id, &updated)
+	}); err != nil {
+		return err
+	}
+
+	entry.Version = updated.Version
+	return nil
+}
+
+// errNoRows signals an update that matched no row.
+var errNoRows = errors.New("no rows affected")
+
+// staleOrMissing explains why an update of the entry with the given ID at the given version
+// matched no row: either the entry does not exist or it is at another version.
+func staleOrMissing(tx *sql.Tx, id string, version int64) error {
+	var stored int64
+	err := tx.QueryRow(`SELECT version FROM entries WHERE id = ?`, id).Scan(&stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.NotFound("no entry with given id found in repository")
+	}
+	if err != nil {
+		return err
+	}
+	return compareVersion(stored, version)
+}
+
+// List returns the page of entries stored in the SQLite repository that match the query. Indexed
+// filters are applied by the database; the remaining ones and the ordering are applied in memory so
+// that every repository pages through entries identically.
+func (r *sqliteRepo) List(query domain.ListQuery) (*domain.EntryPage, error) {
+	matched, err := r.match(query)
+	if err != nil {
+		return &domain.EntryPage{}, err
+	}
+
+	return domain.Paginate(matched, query)
+}
+
+// Tags counts the tags of the entries stored in the SQLite repository that match the query.
+func (r *sqliteRepo) Tags(query domain.ListQuery) ([]domain.TagCount, error) {
+	matched, err := r.match(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.CountTags(matched), nil
+}
+
+func (r *sqliteRepo) match(query domain.ListQuery) ([]*domain.Entry, error) {
+	var conditions []string
+	var args []interface{}
+	if query.OwnerID != "" {
+		conditions = append(conditions, `owner_id = ?`)
+		args = append(args, query.OwnerID)
+	}
+	if query.Done != nil {
+		conditions = append(conditions, `done = ?`)
+		args = append(args, *query.Done)
+	}
+	if query.ParentID != nil {
+		conditions = append(conditions, `parent_id = ?`)
+		args = append(args, *query.ParentID)
+	}
+	if query.ListID != nil {
+		conditions = append(conditions, `list_id = ?`)
+		args = append(args, *query.ListID)
+	}
+	for _, tag := range query.Tags {
+		conditions = append(conditions, `id IN (SELECT entry_id FROM entry_tags WHERE tag = ?)`)
+		args = append(args, tag)
+	}
+	if query.Priority != nil {
+		conditions = append(conditions, `priority = ?`)
+		args = append(args, *query.Priority)
+	}
+	if query.DueBefore != nil {
+		conditions = append(conditions, `due_at < ?`)
+		args = append(args, sqliteDB.FormatTime(*query.DueBefore))
+	}
+	if query.DueAfter != nil {
+		conditions = append(conditions, `due_at > ?`)
+		args = append(args, sqliteDB.FormatTime(*query.DueAfter))
+	}
+
+	stmt := selectEntry
+	if len(conditions) > 0 {
+		stmt += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+
+	rows, err := r.conn().Query(stmt, args...)
+	if err != nil {
+		return nil, domain.Internal("listing entries failed", err)
+	}
+	defer rows.Close()
+
+	var matched []*domain.Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, domain.Internal("reading entry failed", err)
+		}
+		if query.Matches(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.Internal("listing entries failed", err)
+	}
+
+	return matched, nil
+}
+
+// Search returns the entries stored in the SQLite repository whose title or description
+// contains every word of text, most relevant first. Only the postings of the terms the text may
+// match are read, through the index of entry_terms.
+func (r *sqliteRepo) Search(text string) ([]domain.SearchHit, error) {
+	prefixes := domain.TextPrefixes(text)
+	if len(prefixes) == 0 {
+		return []domain.SearchHit{}, nil
+	}
+
+	var hits []domain.SearchHit
+	err := r.inTx("searching entries failed", func(tx *sql.Tx) error {
+		if err := indexPending(tx); err != nil {
+			return err
+		}
+
+		corpus := &domain.TextCorpus{Lengths: map[string]int{}}
+		if err := tx.QueryRow(
+			`SELECT count(*), coalesce(sum(text_length), 0) FROM entries WHERE text_length > 0`,
+		).Scan(&corpus.Entries, &corpus.Length); err != nil {
+			return err
+		}
+
+		// Terms are made of letters and digits, which GLOB matches literally.
+		conditions := make([]string, len(prefixes))
+		args := make([]interface{}, len(prefixes))
+		for i, prefix := range prefixes {
+			conditions[i] = `t.term GLOB ?`
+			args[i] = prefix + "*"
+		}
+		rows, err := tx.Query(`SELECT t.entry_id, t.term, t.title, t.description, e.text_length
+			FROM entry_terms t JOIN entries e ON e.id = t.entry_id
+			WHERE `+strings.Join(conditions, ` OR `), args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var p domain.TextPosting
+			var length int
+			if err := rows.Scan(&p.ID, &p.Term, &p.Title, &p.Description, &length); err != nil {
+				return err
+			}
+			corpus.Postings = append(corpus.Postings, p)
+			corpus.Lengths[p.ID] = length
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		matches := corpus.Search(text)
+		entries, err := selectEntries(tx, matches)
+		if err != nil {
+			return err
+		}
+		hits = make([]domain.SearchHit, len(matches))
+		for i, match := range matches {
+			hits[i] = domain.SearchHit{Entry: entries[match.ID], Score: match.Score}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+// selectEntries returns the entries matched by a search by their ID.
+func selectEntries(tx *sql.Tx, matches []domain.TextMatch) (map[string]*domain.Entry, error) {
+	entries := map[string]*domain.Entry{}
+	if len(matches) == 0 {
+		return entries, nil
+	}
+
+	args := make([]interface{}, len(matches))
+	for i, match := range matches {
+		args[i] = match.ID
+	}
+	rows, err := tx.Query(selectEntry+` WHERE id IN (`+placeholders(len(args))+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries[entry.ID] = entry
+	}
+	return entries, rows.Err()
+}
+
+// conn returns the transaction the repository is a view of, or the database when there is none.
+func (r *sqliteRepo) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// inTx runs fn in a transaction, committing it when fn succeeds, or within the transaction the
+// repository is a view of. Errors that are not already categorised are reported as internal
+// failures described by message.
+func (r *sqliteRepo) inTx(message string, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return domain.Categorise(message, fn(r.tx))
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Internal(message, err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return domain.Categorise(message, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Internal(message, err)
+	}
+	return nil
+}
+
+func writeTags(tx *sql.Tx, id string, tags []string) error {
+	for _, tag := range tags {
+		if _, err := tx.Exec(
+			`INSERT INTO entry_tags (entry_id, tag) VALUES (?, ?) ON CONFLICT DO NOTHING`, id, tag,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTerms indexes the title and description of the entry with the given ID, replacing what
+// was indexed for it before.
+func writeTerms(tx *sql.Tx, id string, entry *domain.Entry) error {
+	if _, err := tx.Exec(`DELETE FROM entry_terms WHERE entry_id = ?`, id); err != nil {
+		return err
+	}
+
+	postings, length := domain.TextTerms(entry)
+	for _, p := range postings {
+		if _, err := tx.Exec(
+			`INSERT INTO entry_terms (entry_id, term, title, description) VALUES (?, ?, ?, ?)`,
+			id, p.Term, p.Title, p.Description,
+		); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`UPDATE entries SET text_length = ? WHERE id = ?`, length, id)
+	return err
+}
+
+// indexPending indexes the entries stored before the repository indexed their text.
+func indexPending(tx *sql.Tx) error {
+	rows, err := tx.Query(selectEntry + ` WHERE text_length < 0`)
+	if err != nil {
+		return err
+	}
+	var pending []*domain.Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, entry := range pending {
+		if err := writeTerms(tx, entry.ID, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: sqliteDB.FormatTime(*t), Valid: true}
+}
+
+func formatRecurrence(r *domain.Recurrence) string {
+	if r == nil {
+		return ""
+	}
+	return r.String()
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := sqliteDB.ParseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// expectAffected returns errNone when the statement did not change any row.
+func expectAffected(res sql.Result, errNone error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return domain.Internal("reading affected rows failed", err)
+	}
+	if affected == 0 {
+		return errNone
+	}
+	return nil
+}
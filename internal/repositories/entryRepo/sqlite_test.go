@@ -0,0 +1,108 @@
+package entryRepo
+
+import (
+	"database/sql"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo/repotest"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLite(t *testing.T) {
+	repotest.RunAtomic(t, func(t *testing.T) ports.AtomicEntryRepository {
+		return NewSQLite(openSQLite(t))
+	})
+}
+
+func TestSQLite_Search(t *testing.T) {
+	t.Run("should rank entries as the text index does", func(t *testing.T) {
+		repo, idx := NewSQLite(openSQLite(t)), domain.NewTextIndex()
+		for _, entry := range []*domain.Entry{
+			{ID: "1", Title: "Deploy the website", Description: "Run the release pipeline"},
+			{ID: "2", Title: "Write release notes", Description: "Summarise what was deployed this week"},
+			{ID: "3", Title: "Buy groceries", Description: "Milk, eggs and bread"},
+			{ID: "4", Title: "Plan the deployment", Description: "Decide when to deploy"},
+			{ID: "5", Title: "The end"},
+		} {
+			require.NoError(t, repo.Save(entry))
+			idx.Add(entry)
+		}
+
+		for _, text := range []string{"deploying", "release deployed", "groc", "MILK, Bread!", "the and", "holiday"} {
+			hits, err := repo.Search(text)
+			require.NoError(t, err)
+			matches := []domain.TextMatch{}
+			for _, hit := range hits {
+				matches = append(matches, domain.TextMatch{ID: hit.Entry.ID, Score: hit.Score})
+			}
+			assert.EqualValues(t, idx.Search(text), matches, text)
+		}
+	})
+
+	t.Run("should reflect updates and deletes", func(t *testing.T) {
+		repo := NewSQLite(openSQLite(t))
+		require.NoError(t, repo.Save(&domain.Entry{ID: "a", Title: "Buy milk", Description: "Semi-skimmed"}))
+		require.NoError(t, repo.Save(&domain.Entry{ID: "b", Title: "Buy bread"}))
+
+		hits, err := repo.Search("buying")
+		require.NoError(t, err)
+		assert.Len(t, hits, 2)
+
+		require.NoError(t, repo.Update("a", &domain.Entry{ID: "a", Title: "Fetch milk"}))
+		require.NoError(t, repo.Delete("b"))
+
+		hits, err = repo.Search("buy")
+		require.NoError(t, err)
+		assert.Empty(t, hits)
+
+		hits, err = repo.Search("milk")
+		require.NoError(t, err)
+		if assert.Len(t, hits, 1) {
+			assert.EqualValues(t, "Fetch milk", hits[0].Entry.Title)
+			assert.EqualValues(t, 1, hits[0].Entry.Version)
+		}
+	})
+
+	t.Run("should index the entries stored before their text was", func(t *testing.T) {
+		db := openSQLite(t)
+		repo := NewSQLite(db)
+		require.NoError(t, repo.Save(&domain.Entry{ID: "a", Title: "Buy milk"}))
+		_, err := db.Exec(`DELETE FROM entry_terms`)
+		require.NoError(t, err)
+		_, err = db.Exec(`UPDATE entries SET text_length = -1`)
+		require.NoError(t, err)
+
+		hits, err := repo.Search("milk")
+		require.NoError(t, err)
+		assert.Len(t, hits, 1)
+	})
+
+	t.Run("should look terms up through their index", func(t *testing.T) {
+		db := openSQLite(t)
+		rows, err := db.Query(`EXPLAIN QUERY PLAN SELECT entry_id FROM entry_terms WHERE term GLOB ? OR term GLOB ?`, "deploy*", "deploi*")
+		require.NoError(t, err)
+		defer rows.Close()
+		plan := ""
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			require.NoError(t, rows.Scan(&id, &parent, &unused, &detail))
+			plan += detail + "\n"
+		}
+		assert.Contains(t, plan, "entry_terms_term")
+		assert.NotContains(t, plan, "SCAN entry_terms\n")
+	})
+}
@@ -0,0 +1,346 @@
+package entryRepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SyncPolicy tells when the write-ahead log of a memKVS is synced to disk.
+type SyncPolicy string
+
+const (
+	// SyncAlways syncs the log before every write returns, so no acknowledged write is lost.
+	SyncAlways SyncPolicy = "always"
+	// SyncPeriodic syncs the log in the background every sync interval. A crash of the process
+	// loses no write, while a crash of the machine loses those of the last interval at most.
+	SyncPeriodic SyncPolicy = "periodic"
+	// SyncNever leaves syncing the log to the operating system.
+	SyncNever SyncPolicy = "never"
+)
+
+const (
+	// DefaultSyncInterval is how often the write-ahead log is synced under SyncPeriodic, unless
+	// configured otherwise.
+	DefaultSyncInterval = time.Second
+	// DefaultCompactionThreshold is the number of writes recorded in the write-ahead log after
+	// which it is compacted, unless configured otherwise.
+	DefaultCompactionThreshold = 10000
+	// DefaultCompactionInterval is how often the write-ahead log is compacted when it recorded
+	// writes since it was last compacted, unless configured otherwise. It bounds the age of the
+	// snapshot for stores written to too rarely to reach the compaction threshold.
+	DefaultCompactionInterval = time.Hour
+)
+
+// ParseSyncPolicy returns the sync policy with the given name.
+func ParseSyncPolicy(name string) (SyncPolicy, error) {
+	switch policy := SyncPolicy(name); policy {
+	case SyncAlways, SyncPeriodic, SyncNever:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown sync policy %q", name)
+}
+
+// writeAheadLog records the writes made to a memKVS, each as a record framed by its length and
+// checksum, before they are made. Compacting the log, once it records enough writes or once the
+// compaction interval passed, writes every stored entry to a snapshot file and empties the log; writes are recorded as the values they store, so replaying the log on top
+// of a snapshot taken after some of them yields the same entries.
+type writeAheadLog struct {
+	mu        sync.Mutex
+	path      string
+	file      *os.File
+	size      int64
+	records   int
+	policy    SyncPolicy
+	interval  time.Duration
+	threshold int
+	every     time.Duration
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// walRecord is a write recorded in the write-ahead log, storing the encoded Entry with ID, or
+// removing it when there is none.
+type walRecord struct {
+	ID    string          `json:"id"`
+	Entry json.RawMessage `json:"entry,omitempty"`
+}
+
+// WALOption configures optional behaviour of the write-ahead log of a memKVS.
+type WALOption func(w *writeAheadLog)
+
+// WithSyncPolicy sets when the write-ahead log is synced to disk. The default is SyncAlways.
+func WithSyncPolicy(policy SyncPolicy) WALOption {
+	return func(w *writeAheadLog) {
+		w.policy = policy
+	}
+}
+
+// WithSyncInterval sets how often the write-ahead log is synced under SyncPeriodic.
+func WithSyncInterval(interval time.Duration) WALOption {
+	return func(w *writeAheadLog) {
+		w.interval = interval
+	}
+}
+
+// WithCompactionThreshold makes the write-ahead log compact itself once it records the given
+// number of writes. Thresholds below 1 leave compacting the log to Compact.
+func WithCompactionThreshold(records int) WALOption {
+	return func(w *writeAheadLog) {
+		w.threshold = records
+	}
+}
+
+// WithCompactionInterval makes the write-ahead log compact itself every given interval when it
+// recorded writes since it was last compacted, however few. Intervals below or equal to 0 leave
+// compacting the log to the compaction threshold and Compact.
+func WithCompactionInterval(interval time.Duration) WALOption {
+	return func(w *writeAheadLog) {
+		w.every = interval
+	}
+}
+
+// OpenMemKVS returns a pointer to an in-memory entry repository recording its writes in a
+// write-ahead log in the file at path, created if missing, and compacting it into a snapshot at
+// path with a ".snapshot" suffix. The entries are recovered from the snapshot and the writes
+// recorded since; a torn record ending the log is cut off. The repository must be closed once it
+// is no longer used.
+func OpenMemKVS(path string, opts ...WALOption) (*memKVS, error) {
+	w := &writeAheadLog{
+		path:      path,
+		policy:    SyncAlways,
+		interval:  DefaultSyncInterval,
+		threshold: DefaultCompactionThreshold,
+		every:     DefaultCompactionInterval,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if _, err := ParseSyncPolicy(string(w.policy)); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening write-ahead log: %w", err)
+	}
+	// The log may just have been created, which only lasts once its directory is synced.
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("opening write-ahead log: %w", err)
+	}
+	w.file = file
+
+	r := NewMemKVS()
+	if err := r.recover(w); err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.wal = w
+
+	if w.policy == SyncPeriodic || w.every > 0 {
+		w.stop, w.stopped = make(chan struct{}), make(chan struct{})
+		go r.maintain()
+	}
+	return r, nil
+}
+
+// Close syncs and closes the write-ahead log of the repository, if it has one.
+func (r *memKVS) Close() error {
+	if r.wal == nil {
+		return nil
+	}
+	if r.wal.stop != nil {
+		close(r.wal.stop)
+		<-r.wal.stopped
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wal.mu.Lock()
+	defer r.wal.mu.Unlock()
+
+	if err := r.wal.file.Sync(); err != nil {
+		r.wal.file.Close()
+		return err
+	}
+	return r.wal.file.Close()
+}
+
+// Compact writes every stored entry to the snapshot of the write-ahead log and empties the log.
+// Repositories without a write-ahead log have nothing to compact.
+func (r *memKVS) Compact() error {
+	if r.wal == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.compact()
+}
+
+// recover restores the entries from the snapshot of w, if any, and the writes recorded in w, and
+// cuts off a torn record ending w.
+func (r *memKVS) recover(w *writeAheadLog) error {
+	if data, err := os.ReadFile(w.snapshotPath()); err == nil {
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("decoding write-ahead log snapshot: %w", err)
+		}
+		for id, val := range entries {
+			if err := r.restore(walRecord{ID: id, Entry: val}); err != nil {
+				return err
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("reading write-ahead log snapshot: %w", err)
+	}
+
+	info, err := w.file.Stat()
+	if err != nil {
+		return fmt.Errorf("reading write-ahead log: %w", err)
+	}
+	end, err := scanFrames(w.file, 0, info.Size(), func(payload []byte) error {
+		var record walRecord
+		if err := json.Unmarshal(payload, &record); err != nil {
+			return fmt.Errorf("decoding record: %w", err)
+		}
+		w.records++
+		return r.restore(record)
+	})
+	if err != nil {
+		return fmt.Errorf("replaying write-ahead log: %w", err)
+	}
+	if err := cutTornTail(w.file, end, info.Size()); err != nil {
+		return fmt.Errorf("recovering write-ahead log: %w", err)
+	}
+	w.size = end
+	return nil
+}
+
+// restore makes the write recorded by record, without recording it again.
+func (r *memKVS) restore(record walRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record.Entry == nil {
+		r.remove(record.ID)
+		return nil
+	}
+	entry := domain.Entry{}
+	if err := json.Unmarshal(record.Entry, &entry); err != nil {
+		return fmt.Errorf("decoding entry %s: %w", record.ID, err)
+	}
+	entry.ID = record.ID
+	r.store(record.ID, []byte(record.Entry), &entry)
+	return nil
+}
+
+// logWrite records in the write-ahead log, if any, that the entry with the given ID is about to
+// be set to the encoded entry val, or removed when val is nil. It must be called with the write
+// lock held.
+func (r *memKVS) logWrite(id string, val []byte) error {
+	if r.wal == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(walRecord{ID: id, Entry: val})
+	if err != nil {
+		return domain.Internal("encoding write-ahead log record failed", err)
+	}
+
+	w := r.wal
+	w.mu.Lock()
+	written, err := writeFrame(w.file, w.size, payload, w.policy == SyncAlways)
+	if err == nil {
+		w.size += written
+		w.records++
+	}
+	w.mu.Unlock()
+	if err != nil {
+		return domain.Internal("appending to write-ahead log failed", err)
+	}
+	return nil
+}
+
+// compactIfDue compacts the write-ahead log, if any, once it holds enough records. The writes are
+// recorded either way, so a failed compaction is retried after the next write. It must be called
+// with the write lock held, after making the write last recorded.
+func (r *memKVS) compactIfDue() {
+	if r.wal != nil && r.wal.threshold > 0 && r.wal.records >= r.wal.threshold {
+		_ = r.compact()
+	}
+}
+
+// compact writes every stored entry to the snapshot of the write-ahead log, replacing the previous
+// snapshot only once the new one is complete, and then empties the log. It must be called with
+// the write lock held.
+func (r *memKVS) compact() error {
+	w := r.wal
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	entries := make(map[string]json.RawMessage, len(r.kvs))
+	for id, val := range r.kvs {
+		entries[id] = val
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomically(w.snapshotPath(), data); err != nil {
+		return err
+	}
+
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	w.size, w.records = 0, 0
+	return w.file.Sync()
+}
+
+// maintain syncs the write-ahead log every sync interval under SyncPeriodic, and compacts it
+// every compaction interval when it recorded writes since it was last compacted, until the log is
+// closed. A failed compaction is retried at the next interval.
+func (r *memKVS) maintain() {
+	w := r.wal
+	defer close(w.stopped)
+
+	var syncs, compactions <-chan time.Time
+	if w.policy == SyncPeriodic {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		syncs = ticker.C
+	}
+	if w.every > 0 {
+		ticker := time.NewTicker(w.every)
+		defer ticker.Stop()
+		compactions = ticker.C
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-syncs:
+			w.mu.Lock()
+			_ = w.file.Sync()
+			w.mu.Unlock()
+		case <-compactions:
+			r.mu.Lock()
+			if w.records > 0 {
+				_ = r.compact()
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+// snapshotPath returns the path of the snapshot the log is compacted into.
+func (w *writeAheadLog) snapshotPath() string {
+	return w.path + ".snapshot"
+}
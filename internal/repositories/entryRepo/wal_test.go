@@ -0,0 +1,196 @@
+package entryRepo
+
+import (
+	"encoding/binary"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/entryRepo/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemKVS_WAL(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncAlways, SyncPeriodic, SyncNever} {
+		t.Run(string(policy), func(t *testing.T) {
+			repotest.Run(t, func(t *testing.T) ports.EntryRepository {
+				repo, err := OpenMemKVS(filepath.Join(t.TempDir(), "todo.wal"), WithSyncPolicy(policy), WithCompactionThreshold(50))
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { repo.Close() })
+
+				return repo
+			})
+		})
+	}
+}
+
+func TestMemKVS_Recover(t *testing.T) {
+	// write fills a new repository at path with entries, leaving it open as after a crash.
+	write := func(t *testing.T, path string, opts ...WALOption) {
+		repo, err := OpenMemKVS(path, opts...)
+		require.NoError(t, err)
+
+		for _, id := range []string{"a", "b", "c"} {
+			require.NoError(t, repo.Save(&domain.Entry{ID: id, Title: "Buy milk", Tags: []string{"@shop"}}))
+		}
+		entry, err := repo.Get("b")
+		require.NoError(t, err)
+		entry.Title = "Buy bread"
+		require.NoError(t, repo.Update("b", entry))
+		require.NoError(t, repo.Delete("c"))
+	}
+
+	// verify checks that repo holds the entries written by write.
+	verify := func(t *testing.T, repo *memKVS) {
+		page, err := repo.List(domain.ListQuery{Tags: []string{"@shop"}})
+		require.NoError(t, err)
+		assert.Len(t, page.Entries, 2)
+		entry, err := repo.Get("b")
+		require.NoError(t, err)
+		assert.EqualValues(t, "Buy bread", entry.Title)
+		assert.EqualValues(t, 1, entry.Version)
+		hits, err := repo.Search("bread")
+		require.NoError(t, err)
+		assert.Len(t, hits, 1)
+	}
+
+	t.Run("should recover the entries from the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.wal")
+		write(t, path)
+
+		repo, err := OpenMemKVS(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		verify(t, repo)
+	})
+
+	t.Run("should recover the entries from the snapshot and the log after compacting it", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.wal")
+		write(t, path, WithCompactionThreshold(4))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Size())
+		_, err = os.Stat(path + ".snapshot")
+		require.NoError(t, err)
+
+		repo, err := OpenMemKVS(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		verify(t, repo)
+	})
+
+	t.Run("should empty the log when compacting it", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.wal")
+		write(t, path, WithCompactionThreshold(0))
+
+		repo, err := OpenMemKVS(path)
+		require.NoError(t, err)
+		require.NoError(t, repo.Compact())
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Zero(t, info.Size())
+		require.NoError(t, repo.Close())
+
+		repo, err = OpenMemKVS(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		verify(t, repo)
+	})
+
+	t.Run("should compact the log every compaction interval however few writes it records", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.wal")
+		repo, err := OpenMemKVS(path, WithCompactionInterval(time.Millisecond))
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(&domain.Entry{ID: "a", Title: "Buy milk"}))
+
+		assert.Eventually(t, func() bool {
+			info, err := os.Stat(path)
+			return err == nil && info.Size() == 0
+		}, time.Second, time.Millisecond)
+		_, err = os.Stat(path + ".snapshot")
+		require.NoError(t, err)
+		require.NoError(t, repo.Close())
+
+		repo, err = OpenMemKVS(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		_, err = repo.Get("a")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should recover the writes synced periodically once closed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.wal")
+		repo, err := OpenMemKVS(path, WithSyncPolicy(SyncPeriodic), WithSyncInterval(time.Millisecond))
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(&domain.Entry{ID: "a", Title: "Buy milk"}))
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, repo.Close())
+
+		repo, err = OpenMemKVS(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		_, err = repo.Get("a")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should cut off a torn record ending the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.wal")
+		write(t, path)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = file.Write([]byte{0, 0, 0, 40, 1, 2, 3, 4, '{', '"'})
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		repo, err := OpenMemKVS(path)
+		require.NoError(t, err)
+		defer repo.Close()
+		verify(t, repo)
+		cut, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.EqualValues(t, info.Size(), cut.Size())
+	})
+
+	t.Run("should fail on a corrupt record followed by others", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.wal")
+		write(t, path)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[frameHeaderSize+2] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err = OpenMemKVS(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail on a record longer than any record can be, rather than cut it off", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.wal")
+		write(t, path)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		binary.BigEndian.PutUint32(data[0:4], maxFrameSize+1)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err = OpenMemKVS(path)
+		assert.Error(t, err)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.EqualValues(t, len(data), info.Size())
+	})
+
+	t.Run("should reject unknown sync policies", func(t *testing.T) {
+		_, err := OpenMemKVS(filepath.Join(t.TempDir(), "todo.wal"), WithSyncPolicy("sometimes"))
+		assert.Error(t, err)
+	})
+}
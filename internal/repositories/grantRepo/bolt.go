@@ -0,0 +1,130 @@
+package grantRepo
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"go.etcd.io/bbolt"
+)
+
+// boltKVS stores one JSON encoded grant per resource and user in a bbolt bucket, keyed so that
+// the grants on a resource are adjacent.
+type boltKVS struct {
+	db *bbolt.DB
+}
+
+// NewBolt returns a pointer to a grant repository stored in the given bbolt database, which is
+// expected to have been opened by boltDB.Open.
+func NewBolt(db *bbolt.DB) *boltKVS {
+	return &boltKVS{
+		db: db,
+	}
+}
+
+func resourcePrefix(kind domain.ResourceKind, resourceID string) []byte {
+	return []byte(string(kind) + "/" + resourceID + "/")
+}
+
+func key(kind domain.ResourceKind, resourceID, userID string) []byte {
+	return append(resourcePrefix(kind, resourceID), userID...)
+}
+
+// Get retrieves the grant of the user with userID on a resource from the bbolt repository.
+func (r *boltKVS) Get(kind domain.ResourceKind, resourceID, userID string) (*domain.Grant, error) {
+	var grant *domain.Grant
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		val := tx.Bucket(boltDB.GrantsBucket).Get(key(kind, resourceID, userID))
+		if val == nil {
+			return domain.NotFound("grant not found in repository")
+		}
+
+		var err error
+		grant, err = decode(val)
+		return err
+	})
+	if err != nil {
+		return &domain.Grant{}, domain.Categorise("reading grant failed", err)
+	}
+	return grant, nil
+}
+
+// Save stores a given domain.Grant object in the bbolt repository, replacing any grant of the
+// same user on the same resource.
+func (r *boltKVS) Save(grant *domain.Grant) error {
+	if err := validate(grant); err != nil {
+		return err
+	}
+
+	stored := *grant
+	stored.Username = ""
+	val, err := json.Marshal(stored)
+	if err != nil {
+		return domain.Internal("encoding grant failed", err)
+	}
+
+	err = r.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.GrantsBucket).Put(key(grant.Kind, grant.ResourceID, grant.UserID), val)
+	})
+	return domain.Categorise("saving grant failed", err)
+}
+
+// Delete removes the grant of the user with userID on a resource from the bbolt repository.
+func (r *boltKVS) Delete(kind domain.ResourceKind, resourceID, userID string) error {
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.GrantsBucket).Delete(key(kind, resourceID, userID))
+	})
+	return domain.Categorise("deleting grant failed", err)
+}
+
+// ForResource returns every grant on a resource, ordered by user ID.
+func (r *boltKVS) ForResource(kind domain.ResourceKind, resourceID string) ([]*domain.Grant, error) {
+	prefix := resourcePrefix(kind, resourceID)
+	grants := []*domain.Grant{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(boltDB.GrantsBucket).Cursor()
+		for k, val := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, val = c.Next() {
+			grant, err := decode(val)
+			if err != nil {
+				return err
+			}
+			grants = append(grants, grant)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, domain.Categorise("listing grants failed", err)
+	}
+	sortGrants(grants)
+	return grants, nil
+}
+
+// ForUser returns every grant of the user with userID, ordered by resource.
+func (r *boltKVS) ForUser(userID string) ([]*domain.Grant, error) {
+	grants := []*domain.Grant{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.GrantsBucket).ForEach(func(_, val []byte) error {
+			grant, err := decode(val)
+			if err != nil {
+				return err
+			}
+			if grant.UserID == userID {
+				grants = append(grants, grant)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, domain.Categorise("listing grants failed", err)
+	}
+	sortGrants(grants)
+	return grants, nil
+}
+
+func decode(val []byte) (*domain.Grant, error) {
+	grant := domain.Grant{}
+	if err := json.Unmarshal(val, &grant); err != nil {
+		return nil, domain.Internal("decoding stored grant failed", err)
+	}
+	return &grant, nil
+}
@@ -0,0 +1,93 @@
+package grantRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemKVS(t *testing.T) {
+	testRepository(t, NewMemKVS())
+}
+
+func TestSQLite(t *testing.T) {
+	db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewSQLite(db))
+}
+
+func TestBolt(t *testing.T) {
+	db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewBolt(db))
+}
+
+func testRepository(t *testing.T, repo ports.GrantRepository) {
+	created := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	bob := &domain.Grant{Kind: domain.ResourceList, ResourceID: "sprint", UserID: "bob", Role: domain.RoleViewer, CreatedAt: created}
+
+	t.Run("should store and return every field of a grant", func(t *testing.T) {
+		require.NoError(t, repo.Save(bob))
+
+		stored, err := repo.Get(domain.ResourceList, "sprint", "bob")
+		require.NoError(t, err)
+		assert.EqualValues(t, bob, stored)
+	})
+
+	t.Run("should replace the grant of a user on the same resource", func(t *testing.T) {
+		editor := *bob
+		editor.Role = domain.RoleEditor
+		require.NoError(t, repo.Save(&editor))
+
+		stored, err := repo.Get(domain.ResourceList, "sprint", "bob")
+		require.NoError(t, err)
+		assert.EqualValues(t, domain.RoleEditor, stored.Role)
+	})
+
+	t.Run("should return validation error when role is unknown", func(t *testing.T) {
+		invalid := *bob
+		invalid.Role = "admin"
+		assert.ErrorIs(t, repo.Save(&invalid), domain.ErrValidation)
+	})
+
+	t.Run("should return not found when getting a missing grant", func(t *testing.T) {
+		_, err := repo.Get(domain.ResourceEntry, "sprint", "bob")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should list the grants on a resource and of a user", func(t *testing.T) {
+		require.NoError(t, repo.Save(&domain.Grant{Kind: domain.ResourceList, ResourceID: "sprint", UserID: "carol", Role: domain.RoleOwner}))
+		require.NoError(t, repo.Save(&domain.Grant{Kind: domain.ResourceEntry, ResourceID: "task", UserID: "bob", Role: domain.RoleViewer}))
+		require.NoError(t, repo.Save(&domain.Grant{Kind: domain.ResourceList, ResourceID: "sprint-2", UserID: "dave", Role: domain.RoleViewer}))
+
+		grants, err := repo.ForResource(domain.ResourceList, "sprint")
+		require.NoError(t, err)
+		require.Len(t, grants, 2)
+		assert.EqualValues(t, "bob", grants[0].UserID)
+		assert.EqualValues(t, "carol", grants[1].UserID)
+
+		grants, err = repo.ForUser("bob")
+		require.NoError(t, err)
+		require.Len(t, grants, 2)
+		assert.EqualValues(t, domain.ResourceEntry, grants[0].Kind)
+		assert.EqualValues(t, domain.ResourceList, grants[1].Kind)
+	})
+
+	t.Run("should delete a stored grant", func(t *testing.T) {
+		require.NoError(t, repo.Delete(domain.ResourceList, "sprint", "bob"))
+		require.NoError(t, repo.Delete(domain.ResourceList, "sprint", "bob"))
+
+		_, err := repo.Get(domain.ResourceList, "sprint", "bob")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
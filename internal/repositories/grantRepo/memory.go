@@ -0,0 +1,122 @@
+package grantRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"sort"
+	"sync"
+)
+
+// grantKey identifies the grant of a user on a resource.
+type grantKey struct {
+	kind       domain.ResourceKind
+	resourceID string
+	userID     string
+}
+
+// memKVS stores grants by resource and user, guarded by a read-write lock.
+type memKVS struct {
+	mu     sync.RWMutex
+	grants map[grantKey]domain.Grant
+}
+
+// NewMemKVS returns a pointer to an in-memory grant repository.
+func NewMemKVS() *memKVS {
+	return &memKVS{
+		grants: map[grantKey]domain.Grant{},
+	}
+}
+
+// Get retrieves the grant of the user with userID on a resource from the in-memory KVS repository.
+func (r *memKVS) Get(kind domain.ResourceKind, resourceID, userID string) (*domain.Grant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	grant, ok := r.grants[grantKey{kind, resourceID, userID}]
+	if !ok {
+		return &domain.Grant{}, domain.NotFound("grant not found in repository")
+	}
+	return &grant, nil
+}
+
+// Save stores a given domain.Grant object in the in-memory KVS repository, replacing any grant
+// of the same user on the same resource.
+func (r *memKVS) Save(grant *domain.Grant) error {
+	if err := validate(grant); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *grant
+	stored.Username = ""
+	r.grants[grantKey{grant.Kind, grant.ResourceID, grant.UserID}] = stored
+	return nil
+}
+
+// Delete removes the grant of the user with userID on a resource from the in-memory KVS repository.
+func (r *memKVS) Delete(kind domain.ResourceKind, resourceID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.grants, grantKey{kind, resourceID, userID})
+	return nil
+}
+
+// ForResource returns every grant on a resource, ordered by user ID.
+func (r *memKVS) ForResource(kind domain.ResourceKind, resourceID string) ([]*domain.Grant, error) {
+	return r.filter(func(grant domain.Grant) bool {
+		return grant.Kind == kind && grant.ResourceID == resourceID
+	}), nil
+}
+
+// ForUser returns every grant of the user with userID, ordered by resource.
+func (r *memKVS) ForUser(userID string) ([]*domain.Grant, error) {
+	return r.filter(func(grant domain.Grant) bool {
+		return grant.UserID == userID
+	}), nil
+}
+
+func (r *memKVS) filter(keep func(grant domain.Grant) bool) []*domain.Grant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	grants := []*domain.Grant{}
+	for _, grant := range r.grants {
+		if keep(grant) {
+			grant := grant
+			grants = append(grants, &grant)
+		}
+	}
+	sortGrants(grants)
+	return grants
+}
+
+// validate checks that a grant names a resource, a user and a role.
+func validate(grant *domain.Grant) error {
+	switch {
+	case grant.Kind != domain.ResourceList && grant.Kind != domain.ResourceEntry:
+		return domain.NewValidationError("resource_type", "must be either list or entry")
+	case grant.ResourceID == "":
+		return domain.NewValidationError("resource_id", "cannot be an empty string")
+	case grant.UserID == "":
+		return domain.NewValidationError("user_id", "cannot be an empty string")
+	case !grant.Role.Valid():
+		return domain.NewValidationError("role", "must be one of viewer, editor or owner")
+	}
+	return nil
+}
+
+// sortGrants orders grants by resource and then by user.
+func sortGrants(grants []*domain.Grant) {
+	sort.Slice(grants, func(i, j int) bool {
+		a, b := grants[i], grants[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.ResourceID != b.ResourceID {
+			return a.ResourceID < b.ResourceID
+		}
+		return a.UserID < b.UserID
+	})
+}
@@ -0,0 +1,112 @@
+package grantRepo
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+)
+
+type sqliteRepo struct {
+	db *sql.DB
+}
+
+// NewSQLite returns a pointer to a grant repository backed by the given SQLite database, whose
+// schema is expected to have been migrated by sqliteDB.Open.
+func NewSQLite(db *sql.DB) *sqliteRepo {
+	return &sqliteRepo{
+		db: db,
+	}
+}
+
+const selectGrant = `SELECT resource_type, resource_id, user_id, role, created_at FROM grants`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanGrant(row scanner) (*domain.Grant, error) {
+	grant := domain.Grant{}
+	var createdAt string
+	if err := row.Scan(&grant.Kind, &grant.ResourceID, &grant.UserID, &grant.Role, &createdAt); err != nil {
+		return &domain.Grant{}, err
+	}
+
+	var err error
+	if grant.CreatedAt, err = sqliteDB.ParseTime(createdAt); err != nil {
+		return &domain.Grant{}, err
+	}
+	return &grant, nil
+}
+
+// Get retrieves the grant of the user with userID on a resource from the SQLite repository.
+func (r *sqliteRepo) Get(kind domain.ResourceKind, resourceID, userID string) (*domain.Grant, error) {
+	grant, err := scanGrant(r.db.QueryRow(
+		selectGrant+` WHERE resource_type = ? AND resource_id = ? AND user_id = ?`, kind, resourceID, userID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.Grant{}, domain.NotFound("grant not found in repository")
+	}
+	if err != nil {
+		return &domain.Grant{}, domain.Internal("reading grant failed", err)
+	}
+	return grant, nil
+}
+
+// Save stores a given domain.Grant object in the SQLite repository, replacing any grant of the
+// same user on the same resource.
+func (r *sqliteRepo) Save(grant *domain.Grant) error {
+	if err := validate(grant); err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(
+		`INSERT INTO grants (resource_type, resource_id, user_id, role, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (resource_type, resource_id, user_id) DO UPDATE SET role = excluded.role, created_at = excluded.created_at`,
+		grant.Kind, grant.ResourceID, grant.UserID, grant.Role, sqliteDB.FormatTime(grant.CreatedAt),
+	); err != nil {
+		return domain.Internal("saving grant failed", err)
+	}
+	return nil
+}
+
+// Delete removes the grant of the user with userID on a resource from the SQLite repository.
+func (r *sqliteRepo) Delete(kind domain.ResourceKind, resourceID, userID string) error {
+	if _, err := r.db.Exec(
+		`DELETE FROM grants WHERE resource_type = ? AND resource_id = ? AND user_id = ?`, kind, resourceID, userID,
+	); err != nil {
+		return domain.Internal("deleting grant failed", err)
+	}
+	return nil
+}
+
+// ForResource returns every grant on a resource, ordered by user ID.
+func (r *sqliteRepo) ForResource(kind domain.ResourceKind, resourceID string) ([]*domain.Grant, error) {
+	return r.query(selectGrant+` WHERE resource_type = ? AND resource_id = ? ORDER BY user_id`, kind, resourceID)
+}
+
+// ForUser returns every grant of the user with userID, ordered by resource.
+func (r *sqliteRepo) ForUser(userID string) ([]*domain.Grant, error) {
+	return r.query(selectGrant+` WHERE user_id = ? ORDER BY resource_type, resource_id`, userID)
+}
+
+func (r *sqliteRepo) query(stmt string, args ...interface{}) ([]*domain.Grant, error) {
+	rows, err := r.db.Query(stmt, args...)
+	if err != nil {
+		return nil, domain.Internal("listing grants failed", err)
+	}
+	defer rows.Close()
+
+	grants := []*domain.Grant{}
+	for rows.Next() {
+		grant, err := scanGrant(rows)
+		if err != nil {
+			return nil, domain.Internal("reading grant failed", err)
+		}
+		grants = append(grants, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.Internal("listing grants failed", err)
+	}
+	return grants, nil
+}
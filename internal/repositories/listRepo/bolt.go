@@ -0,0 +1,108 @@
+package listRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"go.etcd.io/bbolt"
+)
+
+// boltKVS keeps the layout of memKVS, one JSON encoded list per ID, in a bbolt bucket.
+type boltKVS struct {
+	db *bbolt.DB
+}
+
+// NewBolt returns a pointer to a list repository stored in the given bbolt database, which is
+// expected to have been opened by boltDB.Open.
+func NewBolt(db *bbolt.DB) *boltKVS {
+	return &boltKVS{
+		db: db,
+	}
+}
+
+// Get retrieves a list with a specified ID from the bbolt repository.
+func (r *boltKVS) Get(id string) (*domain.List, error) {
+	var list *domain.List
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		val := tx.Bucket(boltDB.ListsBucket).Get([]byte(id))
+		if val == nil {
+			return domain.NotFound("list not found in repository")
+		}
+
+		var err error
+		list, err = decode(val)
+		return err
+	})
+	if err != nil {
+		return &domain.List{}, domain.Categorise("reading list failed", err)
+	}
+	return list, nil
+}
+
+// Save stores a given domain.List object in the bbolt repository. Saving a list whose ID is
+// already stored is a conflict.
+func (r *boltKVS) Save(list *domain.List) error {
+	if list.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltDB.ListsBucket)
+		if bucket.Get([]byte(list.ID)) != nil {
+			return domain.Conflict("list with given id already exists in repository")
+		}
+		return put(bucket, list.ID, list)
+	})
+	return domain.Categorise("saving list failed", err)
+}
+
+// Delete removes a domain.List object with a given ID from the bbolt repository.
+func (r *boltKVS) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.ListsBucket).Delete([]byte(id))
+	})
+	return domain.Categorise("deleting list failed", err)
+}
+
+// Update sets the list stored in the bbolt repository with given ID to the domain.List specified.
+func (r *boltKVS) Update(id string, list *domain.List) error {
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltDB.ListsBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return domain.NotFound("no list with given id found in repository")
+		}
+		return put(bucket, id, list)
+	})
+	return domain.Categorise("updating list failed", err)
+}
+
+// List returns every list stored in the bbolt repository, ordered by ID.
+func (r *boltKVS) List() ([]*domain.List, error) {
+	lists := []*domain.List{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.ListsBucket).ForEach(func(_, val []byte) error {
+			list, err := decode(val)
+			if err != nil {
+				return err
+			}
+			lists = append(lists, list)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, domain.Categorise("listing lists failed", err)
+	}
+	return lists, nil
+}
+
+func put(bucket *bbolt.Bucket, id string, list *domain.List) error {
+	bytes, err := json.Marshal(*list)
+	if err != nil {
+		return domain.Internal("encoding list failed", err)
+	}
+	return bucket.Put([]byte(id), bytes)
+}
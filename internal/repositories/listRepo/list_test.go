@@ -0,0 +1,95 @@
+package listRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMemKVS(t *testing.T) {
+	testRepository(t, NewMemKVS())
+}
+
+func TestSQLite(t *testing.T) {
+	db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewSQLite(db))
+}
+
+func TestBolt(t *testing.T) {
+	db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewBolt(db))
+}
+
+func testRepository(t *testing.T, repo ports.ListRepository) {
+	created := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	sprint := &domain.List{ID: "sprint", OwnerID: "alice", Name: "Sprint", Description: "Current sprint", CreatedAt: created, UpdatedAt: created}
+
+	t.Run("should store and return every field of a list", func(t *testing.T) {
+		require.NoError(t, repo.Save(sprint))
+
+		stored, err := repo.Get(sprint.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, sprint, stored)
+	})
+
+	t.Run("should return conflict when saving a list whose id is taken", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(&domain.List{ID: "sprint", Name: "Other"}), domain.ErrConflict)
+	})
+
+	t.Run("should return validation error when saving a list without id", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(&domain.List{Name: "Nameless"}), domain.ErrValidation)
+	})
+
+	t.Run("should return not found when getting a missing list", func(t *testing.T) {
+		_, err := repo.Get("missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should update a stored list", func(t *testing.T) {
+		renamed := *sprint
+		renamed.Name = "Sprint 12"
+		require.NoError(t, repo.Update(sprint.ID, &renamed))
+
+		stored, err := repo.Get(sprint.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "Sprint 12", stored.Name)
+	})
+
+	t.Run("should return not found when updating a missing list", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Update("missing", &domain.List{ID: "missing", Name: "Missing"}), domain.ErrNotFound)
+	})
+
+	t.Run("should list every stored list", func(t *testing.T) {
+		require.NoError(t, repo.Save(&domain.List{ID: "groceries", Name: "Groceries"}))
+
+		lists, err := repo.List()
+		require.NoError(t, err)
+		ids := make([]string, len(lists))
+		for i, list := range lists {
+			ids[i] = list.ID
+		}
+		sort.Strings(ids)
+		assert.EqualValues(t, []string{"groceries", "sprint"}, ids)
+	})
+
+	t.Run("should delete a stored list", func(t *testing.T) {
+		require.NoError(t, repo.Delete("groceries"))
+		require.NoError(t, repo.Delete("groceries"))
+
+		_, err := repo.Get("groceries")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
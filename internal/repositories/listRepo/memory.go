@@ -0,0 +1,112 @@
+package listRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"sync"
+)
+
+// memKVS stores one JSON encoded list per ID, guarded by a read-write lock.
+type memKVS struct {
+	mu  sync.RWMutex
+	kvs map[string][]byte
+}
+
+// NewMemKVS returns a pointer to an in-memory list repository.
+func NewMemKVS() *memKVS {
+	return &memKVS{
+		kvs: map[string][]byte{},
+	}
+}
+
+// Get retrieves a list with a specified ID from the in-memory KVS repository.
+func (r *memKVS) Get(id string) (*domain.List, error) {
+	r.mu.RLock()
+	val, ok := r.kvs[id]
+	r.mu.RUnlock()
+
+	if !ok {
+		return &domain.List{}, domain.NotFound("list not found in repository")
+	}
+	return decode(val)
+}
+
+// Save stores a given domain.List object in the in-memory KVS repository. Saving a list whose
+// ID is already stored is a conflict.
+func (r *memKVS) Save(list *domain.List) error {
+	if list.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	bytes, err := json.Marshal(*list)
+	if err != nil {
+		return domain.Internal("encoding list failed", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.kvs[list.ID]; ok {
+		return domain.Conflict("list with given id already exists in repository")
+	}
+	r.kvs[list.ID] = bytes
+	return nil
+}
+
+// Delete removes a domain.List object with a given ID from the in-memory KVS repository.
+func (r *memKVS) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.kvs, id)
+	return nil
+}
+
+// Update sets the list stored in the KVS repository with given ID to the domain.List specified.
+func (r *memKVS) Update(id string, list *domain.List) error {
+	bytes, err := json.Marshal(*list)
+	if err != nil {
+		return domain.Internal("encoding list failed", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.kvs[id]; !ok {
+		return domain.NotFound("no list with given id found in repository")
+	}
+	r.kvs[id] = bytes
+	return nil
+}
+
+// List returns every list stored in the in-memory KVS repository, in no particular order.
+func (r *memKVS) List() ([]*domain.List, error) {
+	r.mu.RLock()
+	values := make([][]byte, 0, len(r.kvs))
+	for _, val := range r.kvs {
+		values = append(values, val)
+	}
+	r.mu.RUnlock()
+
+	lists := make([]*domain.List, 0, len(values))
+	for _, val := range values {
+		list, err := decode(val)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+func decode(val []byte) (*domain.List, error) {
+	list := domain.List{}
+	if err := json.Unmarshal(val, &list); err != nil {
+		return &domain.List{}, domain.Internal("decoding stored list failed", err)
+	}
+	return &list, nil
+}
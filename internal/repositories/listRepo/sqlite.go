@@ -0,0 +1,131 @@
+package listRepo
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+)
+
+type sqliteRepo struct {
+	db *sql.DB
+}
+
+// NewSQLite returns a pointer to a list repository backed by the given SQLite database, whose
+// schema is expected to have been migrated by sqliteDB.Open.
+func NewSQLite(db *sql.DB) *sqliteRepo {
+	return &sqliteRepo{
+		db: db,
+	}
+}
+
+const selectList = `SELECT id, owner_id, name, description, created_at, updated_at FROM lists`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanList(row scanner) (*domain.List, error) {
+	list := domain.List{}
+	var createdAt, updatedAt string
+	if err := row.Scan(&list.ID, &list.OwnerID, &list.Name, &list.Description, &createdAt, &updatedAt); err != nil {
+		return &domain.List{}, err
+	}
+
+	var err error
+	if list.CreatedAt, err = sqliteDB.ParseTime(createdAt); err != nil {
+		return &domain.List{}, err
+	}
+	if list.UpdatedAt, err = sqliteDB.ParseTime(updatedAt); err != nil {
+		return &domain.List{}, err
+	}
+	return &list, nil
+}
+
+// Get retrieves a list with a specified ID from the SQLite repository.
+func (r *sqliteRepo) Get(id string) (*domain.List, error) {
+	list, err := scanList(r.db.QueryRow(selectList+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.List{}, domain.NotFound("list not found in repository")
+	}
+	if err != nil {
+		return &domain.List{}, domain.Internal("reading list failed", err)
+	}
+	return list, nil
+}
+
+// Save stores a given domain.List object in the SQLite repository. Saving a list whose ID is
+// already stored is a conflict.
+func (r *sqliteRepo) Save(list *domain.List) error {
+	if list.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	res, err := r.db.Exec(
+		`INSERT INTO lists (id, owner_id, name, description, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		list.ID, list.OwnerID, list.Name, list.Description, sqliteDB.FormatTime(list.CreatedAt), sqliteDB.FormatTime(list.UpdatedAt),
+	)
+	if err != nil {
+		return domain.Internal("inserting list failed", err)
+	}
+	return expectAffected(res, domain.Conflict("list with given id already exists in repository"))
+}
+
+// Delete removes a domain.List object with a given ID from the SQLite repository.
+func (r *sqliteRepo) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	if _, err := r.db.Exec(`DELETE FROM lists WHERE id = ?`, id); err != nil {
+		return domain.Internal("deleting list failed", err)
+	}
+	return nil
+}
+
+// Update sets the list stored in the SQLite repository with given ID to the domain.List specified.
+func (r *sqliteRepo) Update(id string, list *domain.List) error {
+	res, err := r.db.Exec(
+		`UPDATE lists SET owner_id = ?, name = ?, description = ?, created_at = ?, updated_at = ? WHERE id = ?`,
+		list.OwnerID, list.Name, list.Description, sqliteDB.FormatTime(list.CreatedAt), sqliteDB.FormatTime(list.UpdatedAt), id,
+	)
+	if err != nil {
+		return domain.Internal("updating list failed", err)
+	}
+	return expectAffected(res, domain.NotFound("no list with given id found in repository"))
+}
+
+// List returns every list stored in the SQLite repository, ordered by ID.
+func (r *sqliteRepo) List() ([]*domain.List, error) {
+	rows, err := r.db.Query(selectList + ` ORDER BY id`)
+	if err != nil {
+		return nil, domain.Internal("listing lists failed", err)
+	}
+	defer rows.Close()
+
+	lists := []*domain.List{}
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, domain.Internal("reading list failed", err)
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.Internal("listing lists failed", err)
+	}
+	return lists, nil
+}
+
+// expectAffected returns errNone when the statement did not change any row.
+func expectAffected(res sql.Result, errNone error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return domain.Internal("reading affected rows failed", err)
+	}
+	if affected == 0 {
+		return errNone
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package revisionRepo
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"go.etcd.io/bbolt"
+)
+
+// boltKVS stores the JSON encoded revisions of each entry in a nested bucket of a bbolt bucket,
+// numbered by the sequence of the nested bucket.
+type boltKVS struct {
+	db *bbolt.DB
+}
+
+// NewBolt returns a pointer to a revision repository stored in the given bbolt database, which
+// is expected to have been opened by boltDB.Open.
+func NewBolt(db *bbolt.DB) *boltKVS {
+	return &boltKVS{
+		db: db,
+	}
+}
+
+// Append stores a given domain.Revision object as the next revision of its entry in the bbolt
+// repository.
+func (r *boltKVS) Append(revision *domain.Revision) error {
+	if revision.EntryID == "" {
+		return domain.NewValidationError("entry_id", "cannot be an empty string")
+	}
+
+	var number int
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.Bucket(boltDB.RevisionsBucket).CreateBucketIfNotExists([]byte(revision.EntryID))
+		if err != nil {
+			return err
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		stored := *revision
+		stored.Number = int(seq)
+		bytes, err := json.Marshal(stored)
+		if err != nil {
+			return domain.Internal("encoding revision failed", err)
+		}
+		number = stored.Number
+		return bucket.Put(key(number), bytes)
+	})
+	if err != nil {
+		return domain.Categorise("appending revision failed", err)
+	}
+
+	revision.Number = number
+	return nil
+}
+
+// Get retrieves the revision with the given number of an entry from the bbolt repository.
+func (r *boltKVS) Get(entryID string, number int) (*domain.Revision, error) {
+	var revision *domain.Revision
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		var val []byte
+		if bucket := tx.Bucket(boltDB.RevisionsBucket).Bucket([]byte(entryID)); bucket != nil && number > 0 {
+			val = bucket.Get(key(number))
+		}
+		if val == nil {
+			return domain.NotFound("revision not found in repository")
+		}
+
+		var err error
+		revision, err = decode(val)
+		return err
+	})
+	if err != nil {
+		return &domain.Revision{}, domain.Categorise("reading revision failed", err)
+	}
+	return revision, nil
+}
+
+// List returns every revision of an entry stored in the bbolt repository, ordered by number.
+func (r *boltKVS) List(entryID string) ([]*domain.Revision, error) {
+	revisions := []*domain.Revision{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltDB.RevisionsBucket).Bucket([]byte(entryID))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, val []byte) error {
+			revision, err := decode(val)
+			if err != nil {
+				return err
+			}
+			revisions = append(revisions, revision)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, domain.Categorise("listing revisions failed", err)
+	}
+	return revisions, nil
+}
+
+// key returns the key of the revision with the given number, which sorts in numeric order.
+func key(number int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(number))
+	return key
+}
@@ -0,0 +1,83 @@
+package revisionRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"sync"
+)
+
+// memKVS stores the JSON encoded revisions of each entry ID in order, guarded by a read-write
+// lock.
+type memKVS struct {
+	mu  sync.RWMutex
+	kvs map[string][][]byte
+}
+
+// NewMemKVS returns a pointer to an in-memory revision repository.
+func NewMemKVS() *memKVS {
+	return &memKVS{
+		kvs: map[string][][]byte{},
+	}
+}
+
+// Append stores a given domain.Revision object as the next revision of its entry in the
+// in-memory KVS repository.
+func (r *memKVS) Append(revision *domain.Revision) error {
+	if revision.EntryID == "" {
+		return domain.NewValidationError("entry_id", "cannot be an empty string")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	number := len(r.kvs[revision.EntryID]) + 1
+	stored := *revision
+	stored.Number = number
+	bytes, err := json.Marshal(stored)
+	if err != nil {
+		return domain.Internal("encoding revision failed", err)
+	}
+
+	r.kvs[revision.EntryID] = append(r.kvs[revision.EntryID], bytes)
+	revision.Number = number
+	return nil
+}
+
+// Get retrieves the revision with the given number of an entry from the in-memory KVS
+// repository.
+func (r *memKVS) Get(entryID string, number int) (*domain.Revision, error) {
+	r.mu.RLock()
+	revisions := r.kvs[entryID]
+	r.mu.RUnlock()
+
+	if number < 1 || number > len(revisions) {
+		return &domain.Revision{}, domain.NotFound("revision not found in repository")
+	}
+	return decode(revisions[number-1])
+}
+
+// List returns every revision of an entry stored in the in-memory KVS repository, ordered by
+// number.
+func (r *memKVS) List(entryID string) ([]*domain.Revision, error) {
+	r.mu.RLock()
+	values := r.kvs[entryID]
+	r.mu.RUnlock()
+
+	revisions := make([]*domain.Revision, 0, len(values))
+	for _, val := range values {
+		revision, err := decode(val)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+func decode(val []byte) (*domain.Revision, error) {
+	revision := domain.Revision{}
+	if err := json.Unmarshal(val, &revision); err != nil {
+		return &domain.Revision{}, domain.Internal("decoding stored revision failed", err)
+	}
+	return &revision, nil
+}
@@ -0,0 +1,92 @@
+package revisionRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemKVS(t *testing.T) {
+	testRepository(t, NewMemKVS())
+}
+
+func TestSQLite(t *testing.T) {
+	db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewSQLite(db))
+}
+
+func TestBolt(t *testing.T) {
+	db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewBolt(db))
+}
+
+func testRepository(t *testing.T, repo ports.RevisionRepository) {
+	at := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	created := &domain.Revision{
+		EntryID: "release",
+		Action:  domain.RevisionCreated,
+		ActorID: "alice",
+		At:      at,
+		Changes: []domain.FieldChange{{Field: "title", To: json.RawMessage(`"Release"`)}},
+		Entry:   &domain.Entry{ID: "release", Version: 1, OwnerID: "alice", Title: "Release", CreatedAt: at, UpdatedAt: at},
+	}
+
+	t.Run("should number and store every field of a revision", func(t *testing.T) {
+		require.NoError(t, repo.Append(created))
+		assert.EqualValues(t, 1, created.Number)
+
+		stored, err := repo.Get("release", 1)
+		require.NoError(t, err)
+		assert.EqualValues(t, created, stored)
+	})
+
+	t.Run("should number the revisions of each entry on their own", func(t *testing.T) {
+		updated := &domain.Revision{EntryID: "release", Action: domain.RevisionUpdated, ActorID: "bob", At: at.Add(time.Hour), Changes: []domain.FieldChange{}}
+		require.NoError(t, repo.Append(updated))
+		assert.EqualValues(t, 2, updated.Number)
+
+		other := &domain.Revision{EntryID: "notes", Action: domain.RevisionCreated, ActorID: "alice", At: at, Changes: []domain.FieldChange{}}
+		require.NoError(t, repo.Append(other))
+		assert.EqualValues(t, 1, other.Number)
+	})
+
+	t.Run("should list the revisions of an entry in order", func(t *testing.T) {
+		revisions, err := repo.List("release")
+		require.NoError(t, err)
+		if assert.Len(t, revisions, 2) {
+			assert.EqualValues(t, domain.RevisionCreated, revisions[0].Action)
+			assert.EqualValues(t, domain.RevisionUpdated, revisions[1].Action)
+			assert.EqualValues(t, 2, revisions[1].Number)
+		}
+
+		revisions, err = repo.List("missing")
+		require.NoError(t, err)
+		assert.Empty(t, revisions)
+	})
+
+	t.Run("should return not found when getting a missing revision", func(t *testing.T) {
+		for _, number := range []int{0, 3} {
+			_, err := repo.Get("release", number)
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+		}
+		_, err := repo.Get("missing", 1)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should return validation error when appending a revision without entry", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Append(&domain.Revision{Action: domain.RevisionCreated}), domain.ErrValidation)
+	})
+}
@@ -0,0 +1,96 @@
+package revisionRepo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+)
+
+type sqliteRepo struct {
+	db *sql.DB
+}
+
+// NewSQLite returns a pointer to a revision repository backed by the given SQLite database, whose
+// schema is expected to have been migrated by sqliteDB.Open. Revisions are stored as JSON, next
+// to the columns describing them.
+func NewSQLite(db *sql.DB) *sqliteRepo {
+	return &sqliteRepo{
+		db: db,
+	}
+}
+
+// Append stores a given domain.Revision object as the next revision of its entry in the SQLite
+// repository.
+func (r *sqliteRepo) Append(revision *domain.Revision) error {
+	if revision.EntryID == "" {
+		return domain.NewValidationError("entry_id", "cannot be an empty string")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Internal("beginning transaction failed", err)
+	}
+	defer tx.Rollback()
+
+	stored := *revision
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(number), 0) + 1 FROM revisions WHERE entry_id = ?`, revision.EntryID).Scan(&stored.Number); err != nil {
+		return domain.Internal("numbering revision failed", err)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return domain.Internal("encoding revision failed", err)
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO revisions (entry_id, number, action, actor_id, at, data) VALUES (?, ?, ?, ?, ?, ?)`,
+		stored.EntryID, stored.Number, string(stored.Action), stored.ActorID, sqliteDB.FormatTime(stored.At), string(data),
+	); err != nil {
+		return domain.Internal("inserting revision failed", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Internal("committing revision failed", err)
+	}
+
+	revision.Number = stored.Number
+	return nil
+}
+
+// Get retrieves the revision with the given number of an entry from the SQLite repository.
+func (r *sqliteRepo) Get(entryID string, number int) (*domain.Revision, error) {
+	var data []byte
+	err := r.db.QueryRow(`SELECT data FROM revisions WHERE entry_id = ? AND number = ?`, entryID, number).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.Revision{}, domain.NotFound("revision not found in repository")
+	}
+	if err != nil {
+		return &domain.Revision{}, domain.Internal("reading revision failed", err)
+	}
+	return decode(data)
+}
+
+// List returns every revision of an entry stored in the SQLite repository, ordered by number.
+func (r *sqliteRepo) List(entryID string) ([]*domain.Revision, error) {
+	rows, err := r.db.Query(`SELECT data FROM revisions WHERE entry_id = ? ORDER BY number`, entryID)
+	if err != nil {
+		return nil, domain.Internal("listing revisions failed", err)
+	}
+	defer rows.Close()
+
+	revisions := []*domain.Revision{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, domain.Internal("reading revision failed", err)
+		}
+		revision, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.Internal("listing revisions failed", err)
+	}
+	return revisions, nil
+}
@@ -0,0 +1,47 @@
+package sqliteDB
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.db")
+
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+	latest := migrations[len(migrations)-1].version
+
+	t.Run("should migrate a new database to the latest version", func(t *testing.T) {
+		db, err := Open(path)
+		assert.NoError(t, err)
+		defer db.Close()
+
+		version, err := Version(db)
+		assert.NoError(t, err)
+		assert.EqualValues(t, latest, version)
+	})
+
+	t.Run("should leave an up to date database untouched when reopened", func(t *testing.T) {
+		db, err := Open(path)
+		assert.NoError(t, err)
+		defer db.Close()
+
+		var applied int
+		assert.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied))
+		assert.EqualValues(t, len(migrations), applied)
+	})
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+
+	for i, m := range migrations {
+		if i > 0 {
+			assert.Greater(t, m.version, migrations[i-1].version)
+		}
+		assert.NotEmpty(t, m.sql)
+	}
+}
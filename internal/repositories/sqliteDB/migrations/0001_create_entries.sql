@@ -0,0 +1,8 @@
+CREATE TABLE entries (
+    id          TEXT PRIMARY KEY,
+    title       TEXT    NOT NULL,
+    description TEXT    NOT NULL DEFAULT '',
+    done        INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX entries_done ON entries (done);
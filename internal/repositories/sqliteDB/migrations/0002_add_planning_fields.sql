@@ -0,0 +1,10 @@
+-- Timestamps are stored as text in a fixed-width UTC layout (domain.SortableTimeLayout),
+-- so comparing them as strings compares them chronologically.
+ALTER TABLE entries ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE entries ADD COLUMN due_at TEXT;
+ALTER TABLE entries ADD COLUMN created_at TEXT NOT NULL DEFAULT '';
+ALTER TABLE entries ADD COLUMN updated_at TEXT NOT NULL DEFAULT '';
+ALTER TABLE entries ADD COLUMN completed_at TEXT;
+
+CREATE INDEX entries_priority ON entries (priority);
+CREATE INDEX entries_due_at ON entries (due_at);
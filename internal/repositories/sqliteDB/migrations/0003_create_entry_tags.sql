@@ -0,0 +1,7 @@
+CREATE TABLE entry_tags (
+    entry_id TEXT NOT NULL REFERENCES entries (id) ON DELETE CASCADE,
+    tag      TEXT NOT NULL,
+    PRIMARY KEY (entry_id, tag)
+);
+
+CREATE INDEX entry_tags_tag ON entry_tags (tag);
@@ -0,0 +1,3 @@
+ALTER TABLE entries ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX entries_parent_id ON entries (parent_id);
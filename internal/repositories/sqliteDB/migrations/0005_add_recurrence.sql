@@ -0,0 +1,3 @@
+-- Recurrences are stored as RRULE text, empty for entries that do not repeat.
+ALTER TABLE entries ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+ALTER TABLE entries ADD COLUMN occurrence INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+CREATE TABLE lists (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL DEFAULT '',
+    updated_at  TEXT NOT NULL DEFAULT ''
+);
+
+ALTER TABLE entries ADD COLUMN list_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX entries_list_id ON entries (list_id);
@@ -0,0 +1,22 @@
+CREATE TABLE users (
+    id            TEXT PRIMARY KEY,
+    username      TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    password_hash BLOB NOT NULL,
+    created_at    TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE api_tokens (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT NOT NULL DEFAULT '',
+    hash       TEXT NOT NULL UNIQUE,
+    created_at TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX api_tokens_user_id ON api_tokens (user_id);
+
+ALTER TABLE entries ADD COLUMN owner_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX entries_owner_id ON entries (owner_id);
+
+ALTER TABLE lists ADD COLUMN owner_id TEXT NOT NULL DEFAULT '';
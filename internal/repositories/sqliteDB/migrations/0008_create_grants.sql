@@ -0,0 +1,10 @@
+CREATE TABLE grants (
+    resource_type TEXT NOT NULL,
+    resource_id   TEXT NOT NULL,
+    user_id       TEXT NOT NULL,
+    role          TEXT NOT NULL,
+    created_at    TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (resource_type, resource_id, user_id)
+);
+
+CREATE INDEX grants_user_id ON grants (user_id);
@@ -0,0 +1 @@
+ALTER TABLE entries ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- Trashed entries are stored as the JSON encoding of the entry along with its subtasks.
+CREATE TABLE trash (
+    id         TEXT PRIMARY KEY,
+    owner_id   TEXT NOT NULL,
+    deleted_by TEXT NOT NULL,
+    deleted_at TEXT NOT NULL,
+    data       TEXT NOT NULL
+);
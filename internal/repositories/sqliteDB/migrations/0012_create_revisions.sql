@@ -0,0 +1,10 @@
+-- The changes and the entry recorded by a revision are stored as JSON.
+CREATE TABLE revisions (
+    entry_id TEXT    NOT NULL,
+    number   INTEGER NOT NULL,
+    action   TEXT    NOT NULL,
+    actor_id TEXT    NOT NULL,
+    at       TEXT    NOT NULL,
+    data     TEXT    NOT NULL,
+    PRIMARY KEY (entry_id, number)
+);
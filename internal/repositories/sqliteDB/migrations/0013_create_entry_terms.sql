@@ -0,0 +1,15 @@
+-- The terms of the titles and descriptions of entries (domain.TextTerms) form their full-text
+-- index. Entries stored before have a text length of -1 until they are indexed.
+CREATE TABLE entry_terms (
+    entry_id    TEXT    NOT NULL REFERENCES entries (id) ON DELETE CASCADE,
+    term        TEXT    NOT NULL,
+    title       INTEGER NOT NULL,
+    description INTEGER NOT NULL,
+    PRIMARY KEY (entry_id, term)
+);
+
+CREATE INDEX entry_terms_term ON entry_terms (term);
+
+ALTER TABLE entries ADD COLUMN text_length INTEGER NOT NULL DEFAULT -1;
+
+CREATE INDEX entries_text_length ON entries (text_length);
@@ -0,0 +1,38 @@
+package sqliteDB
+
+import (
+	"database/sql"
+	"fmt"
+	_ "modernc.org/sqlite"
+)
+
+// Open returns a handle to the SQLite database at the given path, creating the file if needed
+// and migrating its schema to the latest version. Use ":memory:" for a throwaway database.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer at a time; funnelling every query through one connection
+	// avoids "database is locked" errors and keeps in-memory databases from being per-connection.
+	db.SetMaxOpenConns(1)
+
+	for _, pragma := range []string{
+		"PRAGMA foreign_keys = ON",
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA busy_timeout = 5000",
+	} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("setting %q: %w", pragma, err)
+		}
+	}
+
+	if err := Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,24 @@
+package sqliteDB
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"time"
+)
+
+// FormatTime formats a time as stored in TEXT columns, in UTC using domain.SortableTimeLayout so
+// that comparing stored times as strings compares them chronologically. The zero time is stored
+// as an empty string.
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(domain.SortableTimeLayout)
+}
+
+// ParseTime parses a time formatted by FormatTime.
+func ParseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(domain.SortableTimeLayout, s)
+}
@@ -0,0 +1,88 @@
+package trashRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"go.etcd.io/bbolt"
+)
+
+// boltKVS keeps the layout of memKVS, one JSON encoded trashed entry per ID, in a bbolt bucket.
+type boltKVS struct {
+	db *bbolt.DB
+}
+
+// NewBolt returns a pointer to a trash repository stored in the given bbolt database, which is
+// expected to have been opened by boltDB.Open.
+func NewBolt(db *bbolt.DB) *boltKVS {
+	return &boltKVS{
+		db: db,
+	}
+}
+
+// Get retrieves the trashed entry with a specified ID from the bbolt repository.
+func (r *boltKVS) Get(id string) (*domain.TrashedEntry, error) {
+	var entry *domain.TrashedEntry
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		val := tx.Bucket(boltDB.TrashBucket).Get([]byte(id))
+		if val == nil {
+			return domain.NotFound("entry not found in trash")
+		}
+
+		var err error
+		entry, err = decode(val)
+		return err
+	})
+	if err != nil {
+		return &domain.TrashedEntry{}, domain.Categorise("reading trashed entry failed", err)
+	}
+	return entry, nil
+}
+
+// Save stores a given domain.TrashedEntry object in the bbolt repository. Saving an entry whose
+// ID is already stored is a conflict.
+func (r *boltKVS) Save(entry *domain.TrashedEntry) error {
+	bytes, err := encode(entry)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltDB.TrashBucket)
+		if bucket.Get([]byte(entry.ID)) != nil {
+			return domain.Conflict("entry with given id already exists in trash")
+		}
+		return bucket.Put([]byte(entry.ID), bytes)
+	})
+	return domain.Categorise("saving trashed entry failed", err)
+}
+
+// Delete removes the trashed entry with a given ID from the bbolt repository.
+func (r *boltKVS) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.TrashBucket).Delete([]byte(id))
+	})
+	return domain.Categorise("deleting trashed entry failed", err)
+}
+
+// List returns every trashed entry stored in the bbolt repository, ordered by ID.
+func (r *boltKVS) List() ([]*domain.TrashedEntry, error) {
+	entries := []*domain.TrashedEntry{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.TrashBucket).ForEach(func(_, val []byte) error {
+			entry, err := decode(val)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, domain.Categorise("listing trashed entries failed", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,105 @@
+package trashRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"sync"
+)
+
+// memKVS stores one JSON encoded trashed entry per ID, guarded by a read-write lock.
+type memKVS struct {
+	mu  sync.RWMutex
+	kvs map[string][]byte
+}
+
+// NewMemKVS returns a pointer to an in-memory trash repository.
+func NewMemKVS() *memKVS {
+	return &memKVS{
+		kvs: map[string][]byte{},
+	}
+}
+
+// Get retrieves the trashed entry with a specified ID from the in-memory KVS repository.
+func (r *memKVS) Get(id string) (*domain.TrashedEntry, error) {
+	r.mu.RLock()
+	val, ok := r.kvs[id]
+	r.mu.RUnlock()
+
+	if !ok {
+		return &domain.TrashedEntry{}, domain.NotFound("entry not found in trash")
+	}
+	return decode(val)
+}
+
+// Save stores a given domain.TrashedEntry object in the in-memory KVS repository. Saving an
+// entry whose ID is already stored is a conflict.
+func (r *memKVS) Save(entry *domain.TrashedEntry) error {
+	bytes, err := encode(entry)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.kvs[entry.ID]; ok {
+		return domain.Conflict("entry with given id already exists in trash")
+	}
+	r.kvs[entry.ID] = bytes
+	return nil
+}
+
+// Delete removes the trashed entry with a given ID from the in-memory KVS repository.
+func (r *memKVS) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.kvs, id)
+	return nil
+}
+
+// List returns every trashed entry stored in the in-memory KVS repository, in no particular
+// order.
+func (r *memKVS) List() ([]*domain.TrashedEntry, error) {
+	r.mu.RLock()
+	values := make([][]byte, 0, len(r.kvs))
+	for _, val := range r.kvs {
+		values = append(values, val)
+	}
+	r.mu.RUnlock()
+
+	entries := make([]*domain.TrashedEntry, 0, len(values))
+	for _, val := range values {
+		entry, err := decode(val)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// encode returns the JSON encoding of a trashed entry, which must have an ID.
+func encode(entry *domain.TrashedEntry) ([]byte, error) {
+	if entry.Entry == nil || entry.ID == "" {
+		return nil, domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	bytes, err := json.Marshal(*entry)
+	if err != nil {
+		return nil, domain.Internal("encoding trashed entry failed", err)
+	}
+	return bytes, nil
+}
+
+func decode(val []byte) (*domain.TrashedEntry, error) {
+	entry := domain.TrashedEntry{}
+	if err := json.Unmarshal(val, &entry); err != nil {
+		return &domain.TrashedEntry{}, domain.Internal("decoding trashed entry failed", err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,99 @@
+package trashRepo
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+)
+
+type sqliteRepo struct {
+	db *sql.DB
+}
+
+// NewSQLite returns a pointer to a trash repository backed by the given SQLite database, whose
+// schema is expected to have been migrated by sqliteDB.Open. The trashed entries are stored as
+// JSON, next to the columns they are looked up by.
+func NewSQLite(db *sql.DB) *sqliteRepo {
+	return &sqliteRepo{
+		db: db,
+	}
+}
+
+// Get retrieves the trashed entry with a specified ID from the SQLite repository.
+func (r *sqliteRepo) Get(id string) (*domain.TrashedEntry, error) {
+	var data []byte
+	err := r.db.QueryRow(`SELECT data FROM trash WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.TrashedEntry{}, domain.NotFound("entry not found in trash")
+	}
+	if err != nil {
+		return &domain.TrashedEntry{}, domain.Internal("reading trashed entry failed", err)
+	}
+	return decode(data)
+}
+
+// Save stores a given domain.TrashedEntry object in the SQLite repository. Saving an entry whose
+// ID is already stored is a conflict.
+func (r *sqliteRepo) Save(entry *domain.TrashedEntry) error {
+	data, err := encode(entry)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.Exec(
+		`INSERT INTO trash (id, owner_id, deleted_by, deleted_at, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		entry.ID, entry.OwnerID, entry.DeletedBy, sqliteDB.FormatTime(entry.DeletedAt), string(data),
+	)
+	if err != nil {
+		return domain.Internal("inserting trashed entry failed", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return domain.Internal("reading affected rows failed", err)
+	}
+	if affected == 0 {
+		return domain.Conflict("entry with given id already exists in trash")
+	}
+	return nil
+}
+
+// Delete removes the trashed entry with a given ID from the SQLite repository.
+func (r *sqliteRepo) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	if _, err := r.db.Exec(`DELETE FROM trash WHERE id = ?`, id); err != nil {
+		return domain.Internal("deleting trashed entry failed", err)
+	}
+	return nil
+}
+
+// List returns every trashed entry stored in the SQLite repository, ordered by ID.
+func (r *sqliteRepo) List() ([]*domain.TrashedEntry, error) {
+	rows, err := r.db.Query(`SELECT data FROM trash ORDER BY id`)
+	if err != nil {
+		return nil, domain.Internal("listing trashed entries failed", err)
+	}
+	defer rows.Close()
+
+	entries := []*domain.TrashedEntry{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, domain.Internal("reading trashed entry failed", err)
+		}
+		entry, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.Internal("listing trashed entries failed", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,91 @@
+package trashRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMemKVS(t *testing.T) {
+	testRepository(t, NewMemKVS())
+}
+
+func TestSQLite(t *testing.T) {
+	db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewSQLite(db))
+}
+
+func TestBolt(t *testing.T) {
+	db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewBolt(db))
+}
+
+func testRepository(t *testing.T, repo ports.TrashRepository) {
+	created := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	deleted := created.Add(24 * time.Hour)
+	release := &domain.TrashedEntry{
+		Entry: &domain.Entry{ID: "release", Version: 2, OwnerID: "alice", Title: "Release", ListID: "sprint", Tags: []string{"#work"}, CreatedAt: created, UpdatedAt: created},
+		Subtasks: []*domain.Entry{
+			{ID: "notes", Version: 1, OwnerID: "bob", Title: "Write notes", ParentID: "release", ListID: "sprint", CreatedAt: created, UpdatedAt: created},
+		},
+		DeletedAt: deleted,
+		DeletedBy: "bob",
+	}
+
+	t.Run("should store and return an entry along with its subtasks", func(t *testing.T) {
+		require.NoError(t, repo.Save(release))
+
+		stored, err := repo.Get(release.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, release, stored)
+	})
+
+	t.Run("should return conflict when saving an entry whose id is taken", func(t *testing.T) {
+		err := repo.Save(&domain.TrashedEntry{Entry: &domain.Entry{ID: "release"}, DeletedAt: deleted})
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("should return validation error when saving an entry without id", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(&domain.TrashedEntry{Entry: &domain.Entry{Title: "Nameless"}}), domain.ErrValidation)
+		assert.ErrorIs(t, repo.Save(&domain.TrashedEntry{}), domain.ErrValidation)
+	})
+
+	t.Run("should return not found when getting a missing entry", func(t *testing.T) {
+		_, err := repo.Get("missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should list every entry", func(t *testing.T) {
+		require.NoError(t, repo.Save(&domain.TrashedEntry{Entry: &domain.Entry{ID: "milk", OwnerID: "carol", Title: "Buy milk"}, DeletedAt: deleted, DeletedBy: "carol"}))
+
+		entries, err := repo.List()
+		require.NoError(t, err)
+		ids := make([]string, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+		}
+		sort.Strings(ids)
+		assert.EqualValues(t, []string{"milk", "release"}, ids)
+	})
+
+	t.Run("should delete a stored entry", func(t *testing.T) {
+		require.NoError(t, repo.Delete("milk"))
+		require.NoError(t, repo.Delete("milk"))
+
+		_, err := repo.Get("milk")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
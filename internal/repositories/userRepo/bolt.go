@@ -0,0 +1,227 @@
+package userRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"go.etcd.io/bbolt"
+	"sort"
+	"strings"
+)
+
+// boltKVS stores one JSON encoded user per ID and one JSON encoded token per hash in bbolt
+// buckets, indexing users by their lower case username.
+type boltKVS struct {
+	db *bbolt.DB
+}
+
+// NewBolt returns a pointer to a user repository stored in the given bbolt database, which is
+// expected to have been opened by boltDB.Open.
+func NewBolt(db *bbolt.DB) *boltKVS {
+	return &boltKVS{
+		db: db,
+	}
+}
+
+// storedUser is the stored encoding of a user, which unlike the API encoding includes the
+// password hash.
+type storedUser struct {
+	domain.User
+	PasswordHash []byte `json:"password_hash"`
+}
+
+// Get retrieves a user with a specified ID from the bbolt repository.
+func (r *boltKVS) Get(id string) (*domain.User, error) {
+	var user *domain.User
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		user, err = getUser(tx, id)
+		return err
+	})
+	if err != nil {
+		return &domain.User{}, domain.Categorise("reading user failed", err)
+	}
+	return user, nil
+}
+
+// GetByUsername retrieves the user with the given username, ignoring case, from the bbolt
+// repository.
+func (r *boltKVS) GetByUsername(username string) (*domain.User, error) {
+	var user *domain.User
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		id := tx.Bucket(boltDB.UsernamesBucket).Get([]byte(strings.ToLower(username)))
+		if id == nil {
+			return domain.NotFound("user not found in repository")
+		}
+
+		var err error
+		user, err = getUser(tx, string(id))
+		return err
+	})
+	if err != nil {
+		return &domain.User{}, domain.Categorise("reading user failed", err)
+	}
+	return user, nil
+}
+
+// Save stores a given domain.User object in the bbolt repository. Saving a user whose ID or
+// username is already stored is a conflict.
+func (r *boltKVS) Save(user *domain.User) error {
+	if user.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		users, usernames := tx.Bucket(boltDB.UsersBucket), tx.Bucket(boltDB.UsernamesBucket)
+		username := []byte(strings.ToLower(user.Username))
+		if users.Get([]byte(user.ID)) != nil {
+			return domain.Conflict("user with given id already exists in repository")
+		}
+		if usernames.Get(username) != nil {
+			return domain.Conflict("user with given username already exists in repository")
+		}
+
+		bytes, err := json.Marshal(storedUser{User: *user, PasswordHash: user.PasswordHash})
+		if err != nil {
+			return domain.Internal("encoding user failed", err)
+		}
+		if err := users.Put([]byte(user.ID), bytes); err != nil {
+			return err
+		}
+		return usernames.Put(username, []byte(user.ID))
+	})
+	return domain.Categorise("saving user failed", err)
+}
+
+// SaveToken stores a given domain.Token object in the bbolt repository. Saving a token whose ID
+// or hash is already stored is a conflict, as is saving one for a missing user.
+func (r *boltKVS) SaveToken(token *domain.Token) error {
+	if token.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		if tx.Bucket(boltDB.UsersBucket).Get([]byte(token.UserID)) == nil {
+			return domain.Conflict("token belongs to a user missing from repository")
+		}
+
+		bucket := tx.Bucket(boltDB.TokensBucket)
+		if bucket.Get([]byte(token.Hash)) != nil {
+			return domain.Conflict("token with given hash already exists in repository")
+		}
+		if err := bucket.ForEach(func(_, val []byte) error {
+			other, err := decodeToken(nil, val)
+			if err != nil {
+				return err
+			}
+			if other.ID == token.ID {
+				return domain.Conflict("token with given id already exists in repository")
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		bytes, err := json.Marshal(*token)
+		if err != nil {
+			return domain.Internal("encoding token failed", err)
+		}
+		return bucket.Put([]byte(token.Hash), bytes)
+	})
+	return domain.Categorise("saving token failed", err)
+}
+
+// GetToken retrieves the token with the given hash from the bbolt repository.
+func (r *boltKVS) GetToken(hash string) (*domain.Token, error) {
+	var token *domain.Token
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		val := tx.Bucket(boltDB.TokensBucket).Get([]byte(hash))
+		if val == nil {
+			return domain.NotFound("token not found in repository")
+		}
+
+		var err error
+		token, err = decodeToken([]byte(hash), val)
+		return err
+	})
+	if err != nil {
+		return &domain.Token{}, domain.Categorise("reading token failed", err)
+	}
+	return token, nil
+}
+
+// DeleteToken removes the token with the given ID from the bbolt repository.
+func (r *boltKVS) DeleteToken(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltDB.TokensBucket)
+		var hash []byte
+		if err := bucket.ForEach(func(key, val []byte) error {
+			token, err := decodeToken(key, val)
+			if err != nil {
+				return err
+			}
+			if token.ID == id {
+				hash = append([]byte(nil), key...)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if hash == nil {
+			return nil
+		}
+		return bucket.Delete(hash)
+	})
+	return domain.Categorise("deleting token failed", err)
+}
+
+// Tokens returns every token of the user with the given ID, ordered by ID.
+func (r *boltKVS) Tokens(userID string) ([]*domain.Token, error) {
+	tokens := []*domain.Token{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.TokensBucket).ForEach(func(key, val []byte) error {
+			token, err := decodeToken(key, val)
+			if err != nil {
+				return err
+			}
+			if token.UserID == userID {
+				tokens = append(tokens, token)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, domain.Categorise("listing tokens failed", err)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, nil
+}
+
+func getUser(tx *bbolt.Tx, id string) (*domain.User, error) {
+	val := tx.Bucket(boltDB.UsersBucket).Get([]byte(id))
+	if val == nil {
+		return nil, domain.NotFound("user not found in repository")
+	}
+
+	stored := storedUser{}
+	if err := json.Unmarshal(val, &stored); err != nil {
+		return nil, domain.Internal("decoding stored user failed", err)
+	}
+	stored.User.PasswordHash = stored.PasswordHash
+	return &stored.User, nil
+}
+
+// decodeToken decodes a stored token, whose hash is the key it is stored under.
+func decodeToken(hash, val []byte) (*domain.Token, error) {
+	token := domain.Token{}
+	if err := json.Unmarshal(val, &token); err != nil {
+		return nil, domain.Internal("decoding stored token failed", err)
+	}
+	token.Hash = string(hash)
+	return &token, nil
+}
@@ -0,0 +1,147 @@
+package userRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memKVS stores users by ID and API tokens by hash, guarded by a read-write lock.
+type memKVS struct {
+	mu        sync.RWMutex
+	users     map[string]domain.User
+	usernames map[string]string
+	tokens    map[string]domain.Token
+}
+
+// NewMemKVS returns a pointer to an in-memory user repository.
+func NewMemKVS() *memKVS {
+	return &memKVS{
+		users:     map[string]domain.User{},
+		usernames: map[string]string{},
+		tokens:    map[string]domain.Token{},
+	}
+}
+
+// Get retrieves a user with a specified ID from the in-memory KVS repository.
+func (r *memKVS) Get(id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return &domain.User{}, domain.NotFound("user not found in repository")
+	}
+	return copyUser(user), nil
+}
+
+// GetByUsername retrieves the user with the given username, ignoring case, from the in-memory
+// KVS repository.
+func (r *memKVS) GetByUsername(username string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.usernames[strings.ToLower(username)]
+	if !ok {
+		return &domain.User{}, domain.NotFound("user not found in repository")
+	}
+	return copyUser(r.users[id]), nil
+}
+
+// Save stores a given domain.User object in the in-memory KVS repository. Saving a user whose
+// ID or username is already stored is a conflict.
+func (r *memKVS) Save(user *domain.User) error {
+	if user.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	username := strings.ToLower(user.Username)
+	if _, ok := r.users[user.ID]; ok {
+		return domain.Conflict("user with given id already exists in repository")
+	}
+	if _, ok := r.usernames[username]; ok {
+		return domain.Conflict("user with given username already exists in repository")
+	}
+	r.users[user.ID] = *copyUser(*user)
+	r.usernames[username] = user.ID
+	return nil
+}
+
+// SaveToken stores a given domain.Token object in the in-memory KVS repository. Saving a token
+// whose ID or hash is already stored is a conflict, as is saving one for a missing user.
+func (r *memKVS) SaveToken(token *domain.Token) error {
+	if token.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[token.UserID]; !ok {
+		return domain.Conflict("token belongs to a user missing from repository")
+	}
+	if _, ok := r.tokens[token.Hash]; ok {
+		return domain.Conflict("token with given hash already exists in repository")
+	}
+	for _, other := range r.tokens {
+		if other.ID == token.ID {
+			return domain.Conflict("token with given id already exists in repository")
+		}
+	}
+	r.tokens[token.Hash] = *token
+	return nil
+}
+
+// GetToken retrieves the token with the given hash from the in-memory KVS repository.
+func (r *memKVS) GetToken(hash string) (*domain.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.tokens[hash]
+	if !ok {
+		return &domain.Token{}, domain.NotFound("token not found in repository")
+	}
+	return &token, nil
+}
+
+// DeleteToken removes the token with the given ID from the in-memory KVS repository.
+func (r *memKVS) DeleteToken(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.ID == id {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+// Tokens returns every token of the user with the given ID, ordered by ID.
+func (r *memKVS) Tokens(userID string) ([]*domain.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := []*domain.Token{}
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			token := token
+			tokens = append(tokens, &token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, nil
+}
+
+func copyUser(user domain.User) *domain.User {
+	user.PasswordHash = append([]byte(nil), user.PasswordHash...)
+	return &user
+}
@@ -0,0 +1,180 @@
+package userRepo
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+)
+
+type sqliteRepo struct {
+	db *sql.DB
+}
+
+// NewSQLite returns a pointer to a user repository backed by the given SQLite database, whose
+// schema is expected to have been migrated by sqliteDB.Open.
+func NewSQLite(db *sql.DB) *sqliteRepo {
+	return &sqliteRepo{
+		db: db,
+	}
+}
+
+const (
+	selectUser  = `SELECT id, username, password_hash, created_at FROM users`
+	selectToken = `SELECT id, user_id, name, hash, created_at FROM api_tokens`
+)
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row scanner) (*domain.User, error) {
+	user := domain.User{}
+	var createdAt string
+	if err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &createdAt); err != nil {
+		return &domain.User{}, err
+	}
+
+	var err error
+	if user.CreatedAt, err = sqliteDB.ParseTime(createdAt); err != nil {
+		return &domain.User{}, err
+	}
+	return &user, nil
+}
+
+func scanToken(row scanner) (*domain.Token, error) {
+	token := domain.Token{}
+	var createdAt string
+	if err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Hash, &createdAt); err != nil {
+		return &domain.Token{}, err
+	}
+
+	var err error
+	if token.CreatedAt, err = sqliteDB.ParseTime(createdAt); err != nil {
+		return &domain.Token{}, err
+	}
+	return &token, nil
+}
+
+// Get retrieves a user with a specified ID from the SQLite repository.
+func (r *sqliteRepo) Get(id string) (*domain.User, error) {
+	return r.getUser(selectUser+` WHERE id = ?`, id)
+}
+
+// GetByUsername retrieves the user with the given username, ignoring case, from the SQLite
+// repository.
+func (r *sqliteRepo) GetByUsername(username string) (*domain.User, error) {
+	return r.getUser(selectUser+` WHERE username = ?`, username)
+}
+
+func (r *sqliteRepo) getUser(query string, arg string) (*domain.User, error) {
+	user, err := scanUser(r.db.QueryRow(query, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.User{}, domain.NotFound("user not found in repository")
+	}
+	if err != nil {
+		return &domain.User{}, domain.Internal("reading user failed", err)
+	}
+	return user, nil
+}
+
+// Save stores a given domain.User object in the SQLite repository. Saving a user whose ID or
+// username is already stored is a conflict.
+func (r *sqliteRepo) Save(user *domain.User) error {
+	if user.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	res, err := r.db.Exec(
+		`INSERT INTO users (id, username, password_hash, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		user.ID, user.Username, user.PasswordHash, sqliteDB.FormatTime(user.CreatedAt),
+	)
+	if err != nil {
+		return domain.Internal("inserting user failed", err)
+	}
+	return expectAffected(res, domain.Conflict("user with given id or username already exists in repository"))
+}
+
+// SaveToken stores a given domain.Token object in the SQLite repository. Saving a token whose
+// ID or hash is already stored is a conflict, as is saving one for a missing user.
+func (r *sqliteRepo) SaveToken(token *domain.Token) error {
+	if token.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	if _, err := r.Get(token.UserID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Conflict("token belongs to a user missing from repository")
+		}
+		return err
+	}
+
+	res, err := r.db.Exec(
+		`INSERT INTO api_tokens (id, user_id, name, hash, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		token.ID, token.UserID, token.Name, token.Hash, sqliteDB.FormatTime(token.CreatedAt),
+	)
+	if err != nil {
+		return domain.Internal("inserting token failed", err)
+	}
+	return expectAffected(res, domain.Conflict("token with given id or hash already exists in repository"))
+}
+
+// GetToken retrieves the token with the given hash from the SQLite repository.
+func (r *sqliteRepo) GetToken(hash string) (*domain.Token, error) {
+	token, err := scanToken(r.db.QueryRow(selectToken+` WHERE hash = ?`, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.Token{}, domain.NotFound("token not found in repository")
+	}
+	if err != nil {
+		return &domain.Token{}, domain.Internal("reading token failed", err)
+	}
+	return token, nil
+}
+
+// DeleteToken removes the token with the given ID from the SQLite repository.
+func (r *sqliteRepo) DeleteToken(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	if _, err := r.db.Exec(`DELETE FROM api_tokens WHERE id = ?`, id); err != nil {
+		return domain.Internal("deleting token failed", err)
+	}
+	return nil
+}
+
+// Tokens returns every token of the user with the given ID, ordered by ID.
+func (r *sqliteRepo) Tokens(userID string) ([]*domain.Token, error) {
+	rows, err := r.db.Query(selectToken+` WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, domain.Internal("listing tokens failed", err)
+	}
+	defer rows.Close()
+
+	tokens := []*domain.Token{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, domain.Internal("reading token failed", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.Internal("listing tokens failed", err)
+	}
+	return tokens, nil
+}
+
+// expectAffected returns errNone when the statement did not change any row.
+func expectAffected(res sql.Result, errNone error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return domain.Internal("reading affected rows failed", err)
+	}
+	if affected == 0 {
+		return errNone
+	}
+	return nil
+}
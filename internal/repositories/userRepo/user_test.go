@@ -0,0 +1,106 @@
+package userRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemKVS(t *testing.T) {
+	testRepository(t, NewMemKVS())
+}
+
+func TestSQLite(t *testing.T) {
+	db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewSQLite(db))
+}
+
+func TestBolt(t *testing.T) {
+	db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewBolt(db))
+}
+
+func testRepository(t *testing.T, repo ports.UserRepository) {
+	created := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	alice := &domain.User{ID: "alice-id", Username: "alice", PasswordHash: []byte("hash"), CreatedAt: created}
+	token := &domain.Token{ID: "token-b", UserID: alice.ID, Name: "laptop", Hash: "abc", CreatedAt: created}
+
+	t.Run("should store and return every field of a user", func(t *testing.T) {
+		require.NoError(t, repo.Save(alice))
+
+		stored, err := repo.Get(alice.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, alice, stored)
+	})
+
+	t.Run("should find a user by username ignoring case", func(t *testing.T) {
+		stored, err := repo.GetByUsername("ALICE")
+		require.NoError(t, err)
+		assert.EqualValues(t, alice, stored)
+	})
+
+	t.Run("should return conflict when saving a user whose username is taken", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(&domain.User{ID: "other", Username: "Alice", PasswordHash: []byte("x")}), domain.ErrConflict)
+	})
+
+	t.Run("should return conflict when saving a user whose id is taken", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(&domain.User{ID: alice.ID, Username: "bob", PasswordHash: []byte("x")}), domain.ErrConflict)
+	})
+
+	t.Run("should return not found when getting a missing user", func(t *testing.T) {
+		_, err := repo.Get("missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = repo.GetByUsername("missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should store a token and find it by hash", func(t *testing.T) {
+		require.NoError(t, repo.SaveToken(token))
+
+		stored, err := repo.GetToken("abc")
+		require.NoError(t, err)
+		assert.EqualValues(t, token, stored)
+	})
+
+	t.Run("should return conflict when saving a token whose hash is taken", func(t *testing.T) {
+		assert.ErrorIs(t, repo.SaveToken(&domain.Token{ID: "token-c", UserID: alice.ID, Hash: "abc"}), domain.ErrConflict)
+	})
+
+	t.Run("should return conflict when saving a token of a missing user", func(t *testing.T) {
+		assert.ErrorIs(t, repo.SaveToken(&domain.Token{ID: "token-d", UserID: "missing", Hash: "def"}), domain.ErrConflict)
+	})
+
+	t.Run("should list the tokens of a user ordered by id", func(t *testing.T) {
+		require.NoError(t, repo.SaveToken(&domain.Token{ID: "token-a", UserID: alice.ID, Name: "ci", Hash: "xyz", CreatedAt: created}))
+
+		tokens, err := repo.Tokens(alice.ID)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.EqualValues(t, "token-a", tokens[0].ID)
+		assert.EqualValues(t, "token-b", tokens[1].ID)
+
+		tokens, err = repo.Tokens("missing")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("should delete a stored token", func(t *testing.T) {
+		require.NoError(t, repo.DeleteToken("token-a"))
+		require.NoError(t, repo.DeleteToken("token-a"))
+
+		_, err := repo.GetToken("xyz")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
@@ -0,0 +1,110 @@
+package viewRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"go.etcd.io/bbolt"
+)
+
+// boltKVS keeps the layout of memKVS, one JSON encoded view per ID, in a bbolt bucket.
+type boltKVS struct {
+	db *bbolt.DB
+}
+
+// NewBolt returns a pointer to a view repository stored in the given bbolt database, which is
+// expected to have been opened by boltDB.Open.
+func NewBolt(db *bbolt.DB) *boltKVS {
+	return &boltKVS{
+		db: db,
+	}
+}
+
+// Get retrieves a view with a specified ID from the bbolt repository.
+func (r *boltKVS) Get(id string) (*domain.View, error) {
+	var view *domain.View
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		val := tx.Bucket(boltDB.ViewsBucket).Get([]byte(id))
+		if val == nil {
+			return domain.NotFound("view not found in repository")
+		}
+
+		var err error
+		view, err = decode(val)
+		return err
+	})
+	if err != nil {
+		return &domain.View{}, domain.Categorise("reading view failed", err)
+	}
+	return view, nil
+}
+
+// Save stores a given domain.View object in the bbolt repository. Saving a view whose ID is
+// already stored is a conflict.
+func (r *boltKVS) Save(view *domain.View) error {
+	if view.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltDB.ViewsBucket)
+		if bucket.Get([]byte(view.ID)) != nil {
+			return domain.Conflict("view with given id already exists in repository")
+		}
+		return put(bucket, view.ID, view)
+	})
+	return domain.Categorise("saving view failed", err)
+}
+
+// Delete removes a domain.View object with a given ID from the bbolt repository.
+func (r *boltKVS) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.ViewsBucket).Delete([]byte(id))
+	})
+	return domain.Categorise("deleting view failed", err)
+}
+
+// Update sets the view stored in the bbolt repository with given ID to the domain.View specified.
+func (r *boltKVS) Update(id string, view *domain.View) error {
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltDB.ViewsBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return domain.NotFound("no view with given id found in repository")
+		}
+		return put(bucket, id, view)
+	})
+	return domain.Categorise("updating view failed", err)
+}
+
+// List returns every view of the user with ownerID stored in the bbolt repository, ordered by ID.
+func (r *boltKVS) List(ownerID string) ([]*domain.View, error) {
+	views := []*domain.View{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltDB.ViewsBucket).ForEach(func(_, val []byte) error {
+			view, err := decode(val)
+			if err != nil {
+				return err
+			}
+			if view.OwnerID == ownerID {
+				views = append(views, view)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, domain.Categorise("listing views failed", err)
+	}
+	return views, nil
+}
+
+func put(bucket *bbolt.Bucket, id string, view *domain.View) error {
+	bytes, err := json.Marshal(*view)
+	if err != nil {
+		return domain.Internal("encoding view failed", err)
+	}
+	return bucket.Put([]byte(id), bytes)
+}
@@ -0,0 +1,115 @@
+package viewRepo
+
+import (
+	"encoding/json"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"sync"
+)
+
+// memKVS stores one JSON encoded view per ID, guarded by a read-write lock.
+type memKVS struct {
+	mu  sync.RWMutex
+	kvs map[string][]byte
+}
+
+// NewMemKVS returns a pointer to an in-memory view repository.
+func NewMemKVS() *memKVS {
+	return &memKVS{
+		kvs: map[string][]byte{},
+	}
+}
+
+// Get retrieves a view with a specified ID from the in-memory KVS repository.
+func (r *memKVS) Get(id string) (*domain.View, error) {
+	r.mu.RLock()
+	val, ok := r.kvs[id]
+	r.mu.RUnlock()
+
+	if !ok {
+		return &domain.View{}, domain.NotFound("view not found in repository")
+	}
+	return decode(val)
+}
+
+// Save stores a given domain.View object in the in-memory KVS repository. Saving a view whose
+// ID is already stored is a conflict.
+func (r *memKVS) Save(view *domain.View) error {
+	if view.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	bytes, err := json.Marshal(*view)
+	if err != nil {
+		return domain.Internal("encoding view failed", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.kvs[view.ID]; ok {
+		return domain.Conflict("view with given id already exists in repository")
+	}
+	r.kvs[view.ID] = bytes
+	return nil
+}
+
+// Delete removes a domain.View object with a given ID from the in-memory KVS repository.
+func (r *memKVS) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.kvs, id)
+	return nil
+}
+
+// Update sets the view stored in the KVS repository with given ID to the domain.View specified.
+func (r *memKVS) Update(id string, view *domain.View) error {
+	bytes, err := json.Marshal(*view)
+	if err != nil {
+		return domain.Internal("encoding view failed", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.kvs[id]; !ok {
+		return domain.NotFound("no view with given id found in repository")
+	}
+	r.kvs[id] = bytes
+	return nil
+}
+
+// List returns every view of the user with ownerID stored in the in-memory KVS repository, in
+// no particular order.
+func (r *memKVS) List(ownerID string) ([]*domain.View, error) {
+	r.mu.RLock()
+	values := make([][]byte, 0, len(r.kvs))
+	for _, val := range r.kvs {
+		values = append(values, val)
+	}
+	r.mu.RUnlock()
+
+	views := []*domain.View{}
+	for _, val := range values {
+		view, err := decode(val)
+		if err != nil {
+			return nil, err
+		}
+		if view.OwnerID == ownerID {
+			views = append(views, view)
+		}
+	}
+	return views, nil
+}
+
+func decode(val []byte) (*domain.View, error) {
+	view := domain.View{}
+	if err := json.Unmarshal(val, &view); err != nil {
+		return &domain.View{}, domain.Internal("decoding stored view failed", err)
+	}
+	return &view, nil
+}
@@ -0,0 +1,131 @@
+package viewRepo
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+)
+
+type sqliteRepo struct {
+	db *sql.DB
+}
+
+// NewSQLite returns a pointer to a view repository backed by the given SQLite database, whose
+// schema is expected to have been migrated by sqliteDB.Open.
+func NewSQLite(db *sql.DB) *sqliteRepo {
+	return &sqliteRepo{
+		db: db,
+	}
+}
+
+const selectView = `SELECT id, owner_id, name, query, created_at, updated_at FROM views`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanView(row scanner) (*domain.View, error) {
+	view := domain.View{}
+	var createdAt, updatedAt string
+	if err := row.Scan(&view.ID, &view.OwnerID, &view.Name, &view.Query, &createdAt, &updatedAt); err != nil {
+		return &domain.View{}, err
+	}
+
+	var err error
+	if view.CreatedAt, err = sqliteDB.ParseTime(createdAt); err != nil {
+		return &domain.View{}, err
+	}
+	if view.UpdatedAt, err = sqliteDB.ParseTime(updatedAt); err != nil {
+		return &domain.View{}, err
+	}
+	return &view, nil
+}
+
+// Get retrieves a view with a specified ID from the SQLite repository.
+func (r *sqliteRepo) Get(id string) (*domain.View, error) {
+	view, err := scanView(r.db.QueryRow(selectView+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.View{}, domain.NotFound("view not found in repository")
+	}
+	if err != nil {
+		return &domain.View{}, domain.Internal("reading view failed", err)
+	}
+	return view, nil
+}
+
+// Save stores a given domain.View object in the SQLite repository. Saving a view whose ID is
+// already stored is a conflict.
+func (r *sqliteRepo) Save(view *domain.View) error {
+	if view.ID == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	res, err := r.db.Exec(
+		`INSERT INTO views (id, owner_id, name, query, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		view.ID, view.OwnerID, view.Name, view.Query, sqliteDB.FormatTime(view.CreatedAt), sqliteDB.FormatTime(view.UpdatedAt),
+	)
+	if err != nil {
+		return domain.Internal("inserting view failed", err)
+	}
+	return expectAffected(res, domain.Conflict("view with given id already exists in repository"))
+}
+
+// Delete removes a domain.View object with a given ID from the SQLite repository.
+func (r *sqliteRepo) Delete(id string) error {
+	if id == "" {
+		return domain.NewValidationError("id", "cannot be an empty string")
+	}
+
+	if _, err := r.db.Exec(`DELETE FROM views WHERE id = ?`, id); err != nil {
+		return domain.Internal("deleting view failed", err)
+	}
+	return nil
+}
+
+// Update sets the view stored in the SQLite repository with given ID to the domain.View specified.
+func (r *sqliteRepo) Update(id string, view *domain.View) error {
+	res, err := r.db.Exec(
+		`UPDATE views SET owner_id = ?, name = ?, query = ?, created_at = ?, updated_at = ? WHERE id = ?`,
+		view.OwnerID, view.Name, view.Query, sqliteDB.FormatTime(view.CreatedAt), sqliteDB.FormatTime(view.UpdatedAt), id,
+	)
+	if err != nil {
+		return domain.Internal("updating view failed", err)
+	}
+	return expectAffected(res, domain.NotFound("no view with given id found in repository"))
+}
+
+// List returns every view of the user with ownerID stored in the SQLite repository, ordered by ID.
+func (r *sqliteRepo) List(ownerID string) ([]*domain.View, error) {
+	rows, err := r.db.Query(selectView+` WHERE owner_id = ? ORDER BY id`, ownerID)
+	if err != nil {
+		return nil, domain.Internal("listing views failed", err)
+	}
+	defer rows.Close()
+
+	views := []*domain.View{}
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, domain.Internal("reading view failed", err)
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.Internal("listing views failed", err)
+	}
+	return views, nil
+}
+
+// expectAffected returns errNone when the statement did not change any row.
+func expectAffected(res sql.Result, errNone error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return domain.Internal("reading affected rows failed", err)
+	}
+	if affected == 0 {
+		return errNone
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package viewRepo
+
+import (
+	"github.com/Nikym/go-todo/internal/core/domain"
+	"github.com/Nikym/go-todo/internal/core/ports"
+	"github.com/Nikym/go-todo/internal/repositories/boltDB"
+	"github.com/Nikym/go-todo/internal/repositories/sqliteDB"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMemKVS(t *testing.T) {
+	testRepository(t, NewMemKVS())
+}
+
+func TestSQLite(t *testing.T) {
+	db, err := sqliteDB.Open(filepath.Join(t.TempDir(), "todo.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewSQLite(db))
+}
+
+func TestBolt(t *testing.T) {
+	db, err := boltDB.Open(filepath.Join(t.TempDir(), "todo.bolt"), time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testRepository(t, NewBolt(db))
+}
+
+func testRepository(t *testing.T, repo ports.ViewRepository) {
+	created := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	urgent := &domain.View{ID: "urgent", OwnerID: "alice", Name: "urgent", Query: "priority:high", CreatedAt: created, UpdatedAt: created}
+
+	t.Run("should store and return every field of a view", func(t *testing.T) {
+		require.NoError(t, repo.Save(urgent))
+
+		stored, err := repo.Get(urgent.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, urgent, stored)
+	})
+
+	t.Run("should return conflict when saving a view whose id is taken", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(&domain.View{ID: "urgent", Name: "other"}), domain.ErrConflict)
+	})
+
+	t.Run("should return validation error when saving a view without id", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Save(&domain.View{Name: "nameless"}), domain.ErrValidation)
+	})
+
+	t.Run("should return not found when getting a missing view", func(t *testing.T) {
+		_, err := repo.Get("missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should update a stored view", func(t *testing.T) {
+		changed := *urgent
+		changed.Query = "priority>=medium"
+		require.NoError(t, repo.Update(urgent.ID, &changed))
+
+		stored, err := repo.Get(urgent.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, "priority>=medium", stored.Query)
+	})
+
+	t.Run("should return not found when updating a missing view", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Update("missing", &domain.View{ID: "missing", Name: "missing"}), domain.ErrNotFound)
+	})
+
+	t.Run("should list the views of a user only", func(t *testing.T) {
+		require.NoError(t, repo.Save(&domain.View{ID: "week", OwnerID: "alice", Name: "week", Query: "due<+1w"}))
+		require.NoError(t, repo.Save(&domain.View{ID: "home", OwnerID: "bob", Name: "home", Query: "tag:home"}))
+
+		views, err := repo.List("alice")
+		require.NoError(t, err)
+		ids := make([]string, len(views))
+		for i, view := range views {
+			ids[i] = view.ID
+		}
+		sort.Strings(ids)
+		assert.EqualValues(t, []string{"urgent", "week"}, ids)
+	})
+
+	t.Run("should delete a stored view", func(t *testing.T) {
+		require.NoError(t, repo.Delete("week"))
+		require.NoError(t, repo.Delete("week"))
+
+		_, err := repo.Get("week")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
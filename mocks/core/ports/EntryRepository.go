@@ -49,6 +49,29 @@ func (_m *EntryRepository) Get(id string) (*domain.Entry, error) {
 	return r0, r1
 }
 
+// List provides a mock function with given fields: query
+func (_m *EntryRepository) List(query domain.ListQuery) (*domain.EntryPage, error) {
+	ret := _m.Called(query)
+
+	var r0 *domain.EntryPage
+	if rf, ok := ret.Get(0).(func(domain.ListQuery) *domain.EntryPage); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.EntryPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(domain.ListQuery) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: entry
 func (_m *EntryRepository) Save(entry *domain.Entry) error {
 	ret := _m.Called(entry)
@@ -63,6 +86,29 @@ func (_m *EntryRepository) Save(entry *domain.Entry) error {
 	return r0
 }
 
+// Tags provides a mock function with given fields: query
+func (_m *EntryRepository) Tags(query domain.ListQuery) ([]domain.TagCount, error) {
+	ret := _m.Called(query)
+
+	var r0 []domain.TagCount
+	if rf, ok := ret.Get(0).(func(domain.ListQuery) []domain.TagCount); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TagCount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(domain.ListQuery) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: id, entry
 func (_m *EntryRepository) Update(id string, entry *domain.Entry) error {
 	ret := _m.Called(id, entry)
@@ -3,8 +3,12 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/Nikym/go-todo/internal/core/domain"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // EntryService is an autogenerated mock type for the EntryService type
@@ -12,13 +16,59 @@ type EntryService struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: title, description
-func (_m *EntryService) Create(title string, description string) (*domain.Entry, error) {
-	ret := _m.Called(title, description)
+// AddTags provides a mock function with given fields: ctx, id, tags
+func (_m *EntryService) AddTags(ctx context.Context, id string, tags []string) (*domain.Entry, error) {
+	ret := _m.Called(ctx, id, tags)
+
+	var r0 *domain.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *domain.Entry); ok {
+		r0 = rf(ctx, id, tags)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, id, tags)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Children provides a mock function with given fields: ctx, id
+func (_m *EntryService) Children(ctx context.Context, id string) ([]*domain.Entry, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []*domain.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.Entry); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, input
+func (_m *EntryService) Create(ctx context.Context, input domain.EntryInput) (*domain.Entry, error) {
+	ret := _m.Called(ctx, input)
 
 	var r0 *domain.Entry
-	if rf, ok := ret.Get(0).(func(string, string) *domain.Entry); ok {
-		r0 = rf(title, description)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.EntryInput) *domain.Entry); ok {
+		r0 = rf(ctx, input)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Entry)
@@ -26,8 +76,8 @@ func (_m *EntryService) Create(title string, description string) (*domain.Entry,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(title, description)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.EntryInput) error); ok {
+		r1 = rf(ctx, input)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,13 +85,27 @@ func (_m *EntryService) Create(title string, description string) (*domain.Entry,
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *EntryService) Delete(id string) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, id, opts
+func (_m *EntryService) Delete(ctx context.Context, id string, opts domain.DeleteOptions) error {
+	ret := _m.Called(ctx, id, opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.DeleteOptions) error); ok {
+		r0 = rf(ctx, id, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EmptyList provides a mock function with given fields: ctx, listID, opts
+func (_m *EntryService) EmptyList(ctx context.Context, listID string, opts domain.ListDeleteOptions) error {
+	ret := _m.Called(ctx, listID, opts)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ListDeleteOptions) error); ok {
+		r0 = rf(ctx, listID, opts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -49,13 +113,27 @@ func (_m *EntryService) Delete(id string) error {
 	return r0
 }
 
-// Get provides a mock function with given fields: id
-func (_m *EntryService) Get(id string) (*domain.Entry, error) {
-	ret := _m.Called(id)
+// EmptyTrash provides a mock function with given fields: ctx
+func (_m *EntryService) EmptyTrash(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: ctx, id
+func (_m *EntryService) Get(ctx context.Context, id string) (*domain.Entry, error) {
+	ret := _m.Called(ctx, id)
 
 	var r0 *domain.Entry
-	if rf, ok := ret.Get(0).(func(string) *domain.Entry); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Entry); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Entry)
@@ -63,8 +141,319 @@ func (_m *EntryService) Get(id string) (*domain.Entry, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// History provides a mock function with given fields: ctx, id
+func (_m *EntryService) History(ctx context.Context, id string) ([]*domain.Revision, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []*domain.Revision
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.Revision); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Revision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, query
+func (_m *EntryService) List(ctx context.Context, query domain.ListQuery) (*domain.EntryPage, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 *domain.EntryPage
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ListQuery) *domain.EntryPage); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.EntryPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ListQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Parse provides a mock function with given fields: ctx, input
+func (_m *EntryService) Parse(ctx context.Context, input domain.EntryInput) (*domain.EntryInput, error) {
+	ret := _m.Called(ctx, input)
+
+	var r0 *domain.EntryInput
+	if rf, ok := ret.Get(0).(func(context.Context, domain.EntryInput) *domain.EntryInput); ok {
+		r0 = rf(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.EntryInput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.EntryInput) error); ok {
+		r1 = rf(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, id
+func (_m *EntryService) Purge(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PurgeTrash provides a mock function with given fields: ctx, olderThan
+func (_m *EntryService) PurgeTrash(ctx context.Context, olderThan time.Duration) (int, error) {
+	ret := _m.Called(ctx, olderThan)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) int); ok {
+		r0 = rf(ctx, olderThan)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, olderThan)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Redo provides a mock function with given fields: ctx
+func (_m *EntryService) Redo(ctx context.Context) (*domain.Operation, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *domain.Operation
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.Operation); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveTags provides a mock function with given fields: ctx, id, tags
+func (_m *EntryService) RemoveTags(ctx context.Context, id string, tags []string) (*domain.Entry, error) {
+	ret := _m.Called(ctx, id, tags)
+
+	var r0 *domain.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *domain.Entry); ok {
+		r0 = rf(ctx, id, tags)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, id, tags)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *EntryService) Restore(ctx context.Context, id string) (*domain.Entry, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *domain.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Entry); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revert provides a mock function with given fields: ctx, id, number, version
+func (_m *EntryService) Revert(ctx context.Context, id string, number int, version int64) (*domain.Entry, error) {
+	ret := _m.Called(ctx, id, number, version)
+
+	var r0 *domain.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int64) *domain.Entry); ok {
+		r0 = rf(ctx, id, number, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int64) error); ok {
+		r1 = rf(ctx, id, number, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, query
+func (_m *EntryService) Search(ctx context.Context, query domain.SearchQuery) ([]*domain.SearchResult, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []*domain.SearchResult
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SearchQuery) []*domain.SearchResult); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.SearchResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SearchQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Tags provides a mock function with given fields: ctx, query
+func (_m *EntryService) Tags(ctx context.Context, query domain.ListQuery) ([]domain.TagCount, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []domain.TagCount
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ListQuery) []domain.TagCount); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TagCount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ListQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Trash provides a mock function with given fields: ctx
+func (_m *EntryService) Trash(ctx context.Context) ([]*domain.TrashedEntry, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*domain.TrashedEntry
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.TrashedEntry); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TrashedEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Tree provides a mock function with given fields: ctx, id
+func (_m *EntryService) Tree(ctx context.Context, id string) (*domain.EntryNode, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *domain.EntryNode
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.EntryNode); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.EntryNode)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Undo provides a mock function with given fields: ctx
+func (_m *EntryService) Undo(ctx context.Context) (*domain.Operation, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *domain.Operation
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.Operation); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,13 +461,13 @@ func (_m *EntryService) Get(id string) (*domain.Entry, error) {
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: id, entry
-func (_m *EntryService) Update(id string, entry *domain.Entry) error {
-	ret := _m.Called(id, entry)
+// Update provides a mock function with given fields: ctx, id, entry
+func (_m *EntryService) Update(ctx context.Context, id string, entry *domain.Entry) error {
+	ret := _m.Called(ctx, id, entry)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, *domain.Entry) error); ok {
-		r0 = rf(id, entry)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Entry) error); ok {
+		r0 = rf(ctx, id, entry)
 	} else {
 		r0 = ret.Error(0)
 	}